@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+// audit records a change made through the API in the audit log. tx should
+// be the Transaction the change was made in, so if it can't be recorded the
+// change isn't made either.
+func audit(tx util.RecordStore, req *http.Request, id *util.Identity, action string, before, after proto.Message) error {
+	entry, err := util.NewAuditEntry(id, auth.ClientIP(req), action, before, after)
+	if err != nil {
+		return err
+	}
+	if err := tx.AppendAudit(entry); err != nil {
+		return fmt.Errorf("error writing audit log entry for %s by %s: %w", action, id.User, err)
+	}
+	return nil
+}
+
+// auditLog replies with entries from the audit log. It takes the fields:
+//
+//	user         who made the changes, which is always the caller for non-admins
+//	zone, name   what was changed
+//	since, until the time range, in RFC 3339
+//	limit        the most entries to return, defaulting to 100
+//	offset       how many entries to skip, for paging
+func auditLog(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	query := util.AuditQuery{
+		User:  req.FormValue("user"),
+		Zone:  req.FormValue("zone"),
+		Name:  req.FormValue("name"),
+		Limit: 100,
+	}
+	if !id.Admin {
+		query.User = id.User
+	}
+
+	for field, val := range map[string]*int64{
+		"since": &query.Since,
+		"until": &query.Until,
+	} {
+		if req.FormValue(field) == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, req.FormValue(field))
+		if err != nil {
+			writeError(resp, http.StatusBadRequest, fieldError(field, "must be an RFC 3339 time"))
+			return
+		}
+		*val = t.Unix()
+	}
+	for field, val := range map[string]*int{
+		"limit":  &query.Limit,
+		"offset": &query.Offset,
+	} {
+		if req.FormValue(field) == "" {
+			continue
+		}
+		i, err := strconv.Atoi(req.FormValue(field))
+		if err != nil || i < 0 {
+			writeError(resp, http.StatusBadRequest, fieldError(field, "must be a whole number"))
+			return
+		}
+		*val = i
+	}
+
+	entries, err := store.AuditLog(query)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	writeProto(resp, http.StatusOK, &pb.AuditEntryList{Entries: entries})
+}
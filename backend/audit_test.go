@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+func TestAuditLogEndpoint(t *testing.T) {
+	testAPI(t)
+	for _, user := range []string{"alice", "bob"} {
+		resp := serve(apiRequest(user, http.MethodPost, "/v1/addDNSRecord",
+			"zone=valid.zone.&type=1&value=192.0.2.1&ttl=60&name="+user))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("adding as %s: status = %d: %s", user, resp.Code, resp.Body)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		user   string
+		query  string
+		actors []string
+	}{
+		// users only see what they did themselves
+		{"alice", "alice", "", []string{"alice"}},
+		{"alice asking about bob", "alice", "?user=bob", []string{"alice"}},
+		{"admin", "admin", "", []string{"alice", "bob"}},
+		{"admin asking about bob", "admin", "?user=bob", []string{"bob"}},
+		{"paging", "admin", "?limit=1&offset=1", []string{"bob"}},
+		{"by name", "admin", "?zone=valid.zone.&name=alice", []string{"alice"}},
+	} {
+		resp := serve(apiRequest(tc.user, http.MethodGet, "/v1/auditLog"+tc.query, ""))
+		if resp.Code != http.StatusOK {
+			t.Errorf("%s: status = %d: %s", tc.name, resp.Code, resp.Body)
+			continue
+		}
+		list := &pb.AuditEntryList{}
+		readReply(t, resp, list)
+		var actors []string
+		for _, entry := range list.GetEntries() {
+			actors = append(actors, entry.GetActor())
+			if entry.GetAction() != "create_record" || entry.GetSourceIp() != "192.0.2.1" {
+				t.Errorf("%s: entry = %v", tc.name, entry)
+			}
+		}
+		if strings.Join(actors, ",") != strings.Join(tc.actors, ",") {
+			t.Errorf("%s: actors = %v, want %v", tc.name, actors, tc.actors)
+		}
+	}
+
+	if resp := serve(apiRequest("admin", http.MethodGet, "/v1/auditLog?since=yesterday", "")); resp.Code != http.StatusBadRequest {
+		t.Errorf("invalid since: status = %d, want 400", resp.Code)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+// maxBatchSize is the most a RecordBatch may take up as JSON, and
+// maxBatchOperations the most operations it may have, so that one request
+// can't take all of our memory or hold a huge transaction open.
+const (
+	maxBatchSize       = 1 << 20
+	maxBatchOperations = 1000
+)
+
+// batchDNSRecords makes the changes in a RecordBatch, sent as JSON in the
+// body, all at once, and replies with them as a RecordChangeList. Each
+// operation follows the same rules as addDNSRecord, updateDNSRecord or
+// deleteDNSRecord, and if any of them can't be made none of them are.
+// Operations with a revision fail with 412, like If-Match, if the record
+// isn't at it.
+func batchDNSRecords(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	body, err := io.ReadAll(http.MaxBytesReader(resp, req.Body, maxBatchSize))
+	if err != nil {
+		writeError(resp, bodyErrorStatus(err), err)
+		return
+	}
+	batch := &pb.RecordBatch{}
+	if err := protojson.Unmarshal(body, batch); err != nil {
+		writeError(resp, http.StatusBadRequest, fmt.Errorf("invalid JSON: %w", err))
+		return
+	}
+	// check everything is well formed before looking anything up
+	if err := validateBatch(batch); err != nil {
+		writeError(resp, http.StatusBadRequest, err)
+		return
+	}
+	changes, err := makeChanges(req, id, batch.GetOperations())
+	if err != nil {
+		writeChangeError(resp, conditional(batch.GetOperations()), err)
+		return
+	}
+	writeProto(resp, http.StatusOK, &pb.RecordChangeList{Changes: changes})
+}
+
+// makeChanges makes the changes ops make, which have already been validated,
+// all at once as id, and audits them. The records they change are read in
+// the same transaction, so nothing else can change them in between.
+func makeChanges(req *http.Request, id *util.Identity, ops []*pb.RecordOperation) ([]*pb.RecordChange, error) {
+	var changes []*pb.RecordChange
+	err := store.Transaction(func(tx util.RecordStore) error {
+		changes = nil
+		for _, op := range ops {
+			change, err := operationChange(tx, id, op)
+			if err != nil {
+				return err
+			}
+			changes = append(changes, change)
+		}
+		return applyChangesIn(tx, req, id, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	recordChanges.Changed()
+	return changes, nil
+}
+
+// applyChanges makes changes all at once as id, like applyChangesIn, and
+// wakes up watchers.
+func applyChanges(req *http.Request, id *util.Identity, changes []*pb.RecordChange) error {
+	err := store.Transaction(func(tx util.RecordStore) error {
+		return applyChangesIn(tx, req, id, changes)
+	})
+	if err != nil {
+		return err
+	}
+	recordChanges.Changed()
+	return nil
+}
+
+// applyChangesIn makes changes through tx as id, as long as they keep the
+// owners of the records within their quotas and can be audited.
+func applyChangesIn(tx util.RecordStore, req *http.Request, id *util.Identity, changes []*pb.RecordChange) error {
+	if err := tx.Apply(changes); err != nil {
+		return err
+	}
+	if err := quotas.CheckChanges(tx, id, changes); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		var err error
+		switch {
+		case change.GetBefore() == nil:
+			err = audit(tx, req, id, util.AuditCreateRecord, nil, change.GetAfter())
+		case change.GetAfter() == nil:
+			err = audit(tx, req, id, util.AuditDeleteRecord, change.GetBefore(), nil)
+		default:
+			err = audit(tx, req, id, util.AuditUpdateRecord, change.GetBefore(), change.GetAfter())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// conditional reports whether any of ops only applies to a record at a
+// revision.
+func conditional(ops []*pb.RecordOperation) bool {
+	for _, op := range ops {
+		if op.GetRevision() != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// validateBatch returns a util.FieldErrors listing what is wrong with every
+// operation in batch, if anything is. A record can only be changed by one
+// operation in a batch.
+func validateBatch(batch *pb.RecordBatch) error {
+	switch {
+	case len(batch.GetOperations()) == 0:
+		return fieldError("operations", "missing")
+	case len(batch.GetOperations()) > maxBatchOperations:
+		return fieldError("operations", fmt.Sprintf("at most %d allowed", maxBatchOperations))
+	}
+	var errs util.FieldErrors
+	changedBy := map[uint64]int{}
+	for i, op := range batch.GetOperations() {
+		prefix := fmt.Sprintf("operations[%d].", i)
+		errs = append(errs, prefixFields(prefix, validateOperation(op))...)
+		if op.GetOp() == "add" || op.GetId() == 0 {
+			continue
+		}
+		if j, ok := changedBy[op.GetId()]; ok {
+			errs = append(errs, &pb.FieldError{Field: prefix + "id",
+				Message: fmt.Sprintf("already changed by operations[%d]", j)})
+			continue
+		}
+		changedBy[op.GetId()] = i
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// prefixFields puts prefix in front of the fields of the util.FieldErrors in
+// err, for the fields of nested messages.
+func prefixFields(prefix string, err error) util.FieldErrors {
+	var errs, prefixed util.FieldErrors
+	errors.As(err, &errs)
+	for _, fieldErr := range errs {
+		prefixed = append(prefixed, &pb.FieldError{
+			Field:   prefix + fieldErr.GetField(),
+			Message: fieldErr.GetMessage(),
+		})
+	}
+	return prefixed
+}
+
+// validateOperation returns what is wrong with the fields of op, for its
+// kind of operation.
+func validateOperation(op *pb.RecordOperation) util.FieldErrors {
+	var errs util.FieldErrors
+	needsID, needsRecord := false, false
+	switch op.GetOp() {
+	case "add":
+		needsRecord = true
+	case "replace":
+		needsID, needsRecord = true, true
+	case "delete":
+		needsID = true
+	default:
+		return util.FieldErrors{{Field: "op", Message: `must be "add", "delete" or "replace"`}}
+	}
+
+	if needsID && op.GetId() == 0 {
+		errs = append(errs, &pb.FieldError{Field: "id", Message: "missing"})
+	}
+	if needsRecord {
+		if op.GetRecord() == nil {
+			return append(errs, &pb.FieldError{Field: "record", Message: "missing"})
+		}
+		errs = append(errs, prefixFields("record.", util.ValidateDNSRecord(op.GetRecord()))...)
+	}
+	return errs
+}
+
+// operationChange checks id may make op, and returns the change it makes to
+// the records in tx.
+func operationChange(tx util.RecordStore, id *util.Identity, op *pb.RecordOperation) (*pb.RecordChange, error) {
+	txAuth := auth.In(tx)
+	record := op.GetRecord()
+	if op.GetOp() == "add" {
+		// IDs are always picked by the store
+		record.Id, record.Revision = 0, 0
+		record.Disabled = false
+		if !id.Admin || record.GetUser() == "" {
+			record.User = id.User
+		}
+		return &pb.RecordChange{After: record}, txAuth.CanCreate(id, record)
+	}
+
+	old, err := tx.Get(op.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if err := txAuth.CanModify(id, old); err != nil {
+		return nil, err
+	}
+	if op.GetRevision() != 0 && op.GetRevision() != old.GetRevision() {
+		return nil, util.ErrConflict
+	}
+	if op.GetOp() == "delete" {
+		return &pb.RecordChange{Before: old}, nil
+	}
+
+	// the same as updateDNSRecord
+	record.Id = old.GetId()
+	if !id.Admin || record.GetUser() == "" {
+		record.User = old.GetUser()
+	}
+	record.Disabled = old.GetDisabled()
+	return &pb.RecordChange{Before: old, After: record}, txAuth.CanCreate(id, record)
+}
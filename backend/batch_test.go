@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+func TestBatchDNSRecords(t *testing.T) {
+	testAPI(t)
+	batch := func(user string, ops ...string) *pb.RecordChangeList {
+		t.Helper()
+		resp := serve(apiRequest(user, http.MethodPost, "/v1/batchDNSRecords",
+			`{"operations": [`+strings.Join(ops, ",")+`]}`))
+		if resp.Code != http.StatusOK {
+			t.Fatalf("batch as %s: status = %d: %s", user, resp.Code, resp.Body)
+		}
+		changes := &pb.RecordChangeList{}
+		readReply(t, resp, changes)
+		return changes
+	}
+	add := func(name string) string {
+		return fmt.Sprintf(`{"op": "add", "record": {"name": %q, "zone": "valid.zone.",
+			"type": 1, "value": "192.0.2.1", "ttl": 60}}`, name)
+	}
+
+	changes := batch("alice", add("one"), add("two"))
+	if len(changes.GetChanges()) != 2 {
+		t.Fatalf("adding two records made %v", changes)
+	}
+	one := changes.GetChanges()[0].GetAfter()
+	if one.GetId() == 0 || one.GetUser() != "alice" {
+		t.Errorf("added %v, want it numbered and owned by alice", one)
+	}
+	replace := fmt.Sprintf(`{"op": "replace", "id": "%d", "revision": "%d", "record": {"name": "one",
+		"zone": "valid.zone.", "type": 1, "value": "192.0.2.2", "ttl": 60}}`, one.GetId(), one.GetRevision())
+	deleteOne := fmt.Sprintf(`{"op": "delete", "id": "%d"}`, one.GetId())
+
+	for _, tc := range []struct {
+		name   string
+		user   string
+		body   string
+		status int
+	}{
+		{"changing a record twice", "alice", `{"operations": [` + replace + "," + deleteOne + `]}`,
+			http.StatusBadRequest},
+		{"too many operations", "alice",
+			`{"operations": [` + strings.Repeat(add("many")+",", maxBatchOperations) + add("many") + `]}`,
+			http.StatusBadRequest},
+		{"too large", "alice", `{"operations": [` + add(strings.Repeat("x", maxBatchSize)) + `]}`,
+			http.StatusRequestEntityTooLarge},
+		// the add is fine, but bob may not touch alice's record, so neither
+		// is made
+		{"someone else's record", "bob", `{"operations": [` + add("three") + "," + deleteOne + `]}`,
+			http.StatusForbidden},
+		{"no such record", "alice", `{"operations": [{"op": "delete", "id": "1000"}]}`,
+			http.StatusNotFound},
+	} {
+		resp := serve(apiRequest(tc.user, http.MethodPost, "/v1/batchDNSRecords", tc.body))
+		if resp.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d: %s", tc.name, resp.Code, tc.status, resp.Body)
+			continue
+		}
+		errBody := &pb.Error{}
+		readReply(t, resp, errBody)
+		if errBody.GetCode() != uint32(tc.status) {
+			t.Errorf("%s: error = %v", tc.name, errBody)
+		}
+		if tc.name == "changing a record twice" &&
+			(len(errBody.GetFields()) != 1 || errBody.GetFields()[0].GetField() != "operations[1].id") {
+			t.Errorf("%s: fields = %v, want operations[1].id", tc.name, errBody.GetFields())
+		}
+	}
+	if records, _ := store.List("valid.zone.", "three"); len(records) != 0 {
+		t.Errorf("half of a failed batch was made: %v", records)
+	}
+
+	// revisions are checked against the record as it is when the batch is
+	// made
+	batch("alice", replace)
+	resp := serve(apiRequest("alice", http.MethodPost, "/v1/batchDNSRecords",
+		`{"operations": [`+replace+`]}`))
+	if resp.Code != http.StatusPreconditionFailed {
+		t.Errorf("replacing at an old revision: status = %d, want 412: %s", resp.Code, resp.Body)
+	}
+}
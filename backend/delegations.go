@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+// delegateZone lets the user in the user field change every record in the
+// zone in the zone field. Only admins may delegate zones.
+func delegateZone(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	zone, user, ok := delegationForm(resp, req, id)
+	if !ok {
+		return
+	}
+	err := delegate(req, id, &pb.ZoneDelegation{Zone: zone, User: user})
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// undelegateZone takes a zone delegated by delegateZone away again.
+func undelegateZone(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	zone, user, ok := delegationForm(resp, req, id)
+	if !ok {
+		return
+	}
+	err := undelegate(req, id, &pb.ZoneDelegation{Zone: zone, User: user})
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// delegate makes delegation, which id may make, and audits it.
+func delegate(req *http.Request, id *util.Identity, delegation *pb.ZoneDelegation) error {
+	return store.Transaction(func(tx util.RecordStore) error {
+		if err := tx.Delegate(delegation.GetZone(), delegation.GetUser()); err != nil {
+			return err
+		}
+		return audit(tx, req, id, util.AuditDelegate, nil, delegation)
+	})
+}
+
+// undelegate takes delegation away, which id may do, and audits it.
+func undelegate(req *http.Request, id *util.Identity, delegation *pb.ZoneDelegation) error {
+	return store.Transaction(func(tx util.RecordStore) error {
+		if err := tx.Undelegate(delegation.GetZone(), delegation.GetUser()); err != nil {
+			return err
+		}
+		return audit(tx, req, id, util.AuditUndelegate, delegation, nil)
+	})
+}
+
+// listDelegations replies with who the zone in the zone field is delegated
+// to, or every delegation if it isn't set.
+func listDelegations(resp http.ResponseWriter, req *http.Request, _ *util.Identity) {
+	delegations, err := store.Delegations(req.FormValue("zone"))
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	writeProto(resp, http.StatusOK, &pb.ZoneDelegationList{Delegations: delegations})
+}
+
+// delegationForm reads the zone and user fields, and checks id may delegate
+// the zone to the user. If it returns false it has already replied.
+func delegationForm(resp http.ResponseWriter, req *http.Request, id *util.Identity) (string, string, bool) {
+	zone, user := req.FormValue("zone"), req.FormValue("user")
+	if err := checkDelegation(id, zone, user); err != nil {
+		writeStoreError(resp, err)
+		return "", "", false
+	}
+	return zone, user, true
+}
+
+// checkDelegation returns ErrForbidden unless id is an admin, and not using a
+// scoped token, or a FieldErrors if zone or user are missing.
+func checkDelegation(id *util.Identity, zone, user string) error {
+	if !id.Admin || !id.Unscoped() {
+		return util.ErrForbidden
+	}
+	var errs util.FieldErrors
+	for _, field := range []struct{ name, val string }{{"zone", zone}, {"user", user}} {
+		if field.val == "" {
+			errs = append(errs, &pb.FieldError{Field: field.name, Message: "missing"})
+		}
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+func TestDelegationEndpoints(t *testing.T) {
+	testAPI(t)
+	resp := serve(apiRequest("alice", http.MethodPost, "/v1/addDNSRecord",
+		"zone=valid.zone.&name=host&type=1&value=192.0.2.1&ttl=60"))
+	record := &pb.DNSRecord{}
+	readReply(t, resp, record)
+	// bob changing alice's record, which he can only do while the zone is
+	// delegated to him
+	update := func() int {
+		return serve(apiRequest("bob", http.MethodPost, "/v1/updateDNSRecord",
+			fmt.Sprintf("id=%d&zone=valid.zone.&name=host&type=1&value=192.0.2.2&ttl=60", record.GetId()))).Code
+	}
+
+	for _, tc := range []struct {
+		name   string
+		user   string
+		route  string
+		form   string
+		status int
+	}{
+		{"delegating as a user", "alice", "delegateZone", "zone=valid.zone.&user=bob", http.StatusForbidden},
+		{"delegating to nobody", "admin", "delegateZone", "zone=valid.zone.", http.StatusBadRequest},
+		{"delegating", "admin", "delegateZone", "zone=valid.zone.&user=bob", http.StatusNoContent},
+	} {
+		resp := serve(apiRequest(tc.user, http.MethodPost, "/v1/"+tc.route, tc.form))
+		if resp.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d: %s", tc.name, resp.Code, tc.status, resp.Body)
+		}
+	}
+
+	resp = serve(apiRequest("alice", http.MethodGet, "/v1/listDelegations?zone=valid.zone.", ""))
+	list := &pb.ZoneDelegationList{}
+	readReply(t, resp, list)
+	if resp.Code != http.StatusOK || len(list.GetDelegations()) != 1 || list.GetDelegations()[0].GetUser() != "bob" {
+		t.Errorf("listing delegations: status = %d, delegations = %v", resp.Code, list)
+	}
+	if status := update(); status != http.StatusOK {
+		t.Errorf("updating as a delegate: status = %d, want 200", status)
+	}
+
+	resp = serve(apiRequest("admin", http.MethodPost, "/v1/undelegateZone", "zone=valid.zone.&user=bob"))
+	if resp.Code != http.StatusNoContent {
+		t.Errorf("undelegating: status = %d: %s", resp.Code, resp.Body)
+	}
+	if status := update(); status != http.StatusForbidden {
+		t.Errorf("updating once no longer a delegate: status = %d, want 403", status)
+	}
+}
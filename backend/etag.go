@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
+)
+
+// etag returns the ETag of a record at revision, or of a zone at that serial.
+func etag(revision uint64) string {
+	return strconv.Quote(strconv.FormatUint(revision, 10))
+}
+
+// ifMatch reports whether req's If-Match header, if it has one, matches
+// something at revision. If it returns false it has already replied.
+func ifMatch(resp http.ResponseWriter, req *http.Request, revision uint64) bool {
+	header := req.Header.Get("If-Match")
+	if header == "" || strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		// weak ETags never match, as If-Match needs the strong comparison
+		if strings.TrimSpace(tag) == etag(revision) {
+			return true
+		}
+	}
+	resp.Header().Set("ETag", etag(revision))
+	writeError(resp, http.StatusPreconditionFailed, util.ErrConflict)
+	return false
+}
+
+// writeChangeError is writeStoreError for changes which may have been
+// conditional, which fail with 412 if so and something changed under them.
+func writeChangeError(resp http.ResponseWriter, conditional bool, err error) {
+	status := errorStatus(conditional, err)
+	if status == http.StatusInternalServerError {
+		err = internalError(err)
+	}
+	writeError(resp, status, err)
+}
@@ -0,0 +1,526 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
+	"github.com/gidoBOSSftw5731/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+// watchPollInterval is how often Watch looks for changes it isn't woken up
+// for, like those made by other instances or the LDAP sync.
+const watchPollInterval = 10 * time.Second
+
+// grpcCodes are the gRPC codes matching the statuses of errorStatus.
+var grpcCodes = map[int]codes.Code{
+	http.StatusBadRequest:          codes.InvalidArgument,
+	http.StatusForbidden:           codes.PermissionDenied,
+	http.StatusNotFound:            codes.NotFound,
+	http.StatusConflict:            codes.Aborted,
+	http.StatusPreconditionFailed:  codes.FailedPrecondition,
+	http.StatusInternalServerError: codes.Internal,
+}
+
+// registrationServer is the DeviceRegistration gRPC service. Its methods do
+// what the HTTP API does, mostly through the same functions.
+type registrationServer struct {
+	pb.UnimplementedDeviceRegistrationServer
+}
+
+// grpcServer starts the gRPC service, if it has an address to listen on. It
+// is served over TLS if it has a certificate.
+func grpcServer() {
+	if config.GetGrpcListenAddr() == "" {
+		return
+	}
+	log.Infoln("Starting gRPC server")
+	var opts []grpc.ServerOption
+	if config.GetGrpcTlsCertFile() != "" && config.GetGrpcTlsKeyFile() != "" {
+		creds, err := credentials.NewServerTLSFromFile(config.GetGrpcTlsCertFile(), config.GetGrpcTlsKeyFile())
+		if err != nil {
+			log.Errorln("Not starting gRPC server:", err)
+			return
+		}
+		opts = append(opts, grpc.Creds(creds))
+	} else {
+		log.Infof("gRPC server on %s has no certificate, so is in plaintext", config.GetGrpcListenAddr())
+	}
+	listener, err := net.Listen("tcp", config.GetGrpcListenAddr())
+	if err != nil {
+		log.Errorln("Not starting gRPC server:", err)
+		return
+	}
+	server := grpc.NewServer(opts...)
+	pb.RegisterDeviceRegistrationServer(server, &registrationServer{})
+	go func() {
+		log.Fatalln(server.Serve(listener))
+	}()
+}
+
+// identify authenticates a gRPC call the same way as an HTTP request, and
+// returns it dressed up as one so it can be audited like one too. Headers
+// only a reverse proxy should set are left out.
+func identify(ctx context.Context) (*http.Request, *util.Identity, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/", nil)
+	if err != nil {
+		return nil, nil, status.Error(codes.Internal, err.Error())
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, vals := range md {
+		// pseudo-headers like :authority aren't really headers
+		if strings.HasPrefix(key, ":") || proxyHeader(key) {
+			continue
+		}
+		for _, val := range vals {
+			req.Header.Add(key, val)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		req.RemoteAddr = p.Addr.String()
+	}
+
+	id, err := auth.Authenticate(req)
+	switch {
+	case errors.Is(err, util.ErrForbidden):
+		return nil, nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		log.Errorln("Unauthenticated gRPC call:", err)
+		return nil, nil, status.Error(codes.Unauthenticated, util.ErrUnauthenticated.Error())
+	}
+	return req, id, nil
+}
+
+// proxyHeader reports whether key is a header only a reverse proxy in front
+// of the HTTP API should set. gRPC calls come straight from the caller, so
+// it could be anyone claiming to be anybody, from anywhere.
+func proxyHeader(key string) bool {
+	for _, header := range []string{auth.Config.GetProxyUserHeader(), "X-Forwarded-For", "X-Real-Ip", "Forwarded"} {
+		if header != "" && strings.EqualFold(key, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// grpcError returns the status matching err, like writeChangeError, with the
+// pb.Error the HTTP API would reply with in its details.
+func grpcError(conditional bool, err error) error {
+	httpStatus := errorStatus(conditional, err)
+	if httpStatus == http.StatusInternalServerError {
+		err = internalError(err)
+	} else {
+		log.Errorln(err)
+	}
+	code := grpcCodes[httpStatus]
+	if errors.Is(err, util.ErrDuplicateRecord) {
+		code = codes.AlreadyExists
+	}
+	st := status.New(code, err.Error())
+	if detailed, detailsErr := st.WithDetails(errorBody(httpStatus, err)); detailsErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// change makes the changes ops make as id, like makeChanges. The fields of
+// ops are checked by the caller, so they can be named after those of its
+// request.
+func change(req *http.Request, id *util.Identity, ops ...*pb.RecordOperation) ([]*pb.RecordChange, error) {
+	changes, err := makeChanges(req, id, ops)
+	if err != nil {
+		return nil, grpcError(conditional(ops), err)
+	}
+	return changes, nil
+}
+
+func (s *registrationServer) CreateRecord(ctx context.Context, record *pb.DNSRecord) (*pb.DNSRecord, error) {
+	req, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := util.ValidateDNSRecord(record); err != nil {
+		return nil, grpcError(false, err)
+	}
+	changes, err := change(req, id, &pb.RecordOperation{Op: "add", Record: record})
+	if err != nil {
+		return nil, err
+	}
+	return changes[0].GetAfter(), nil
+}
+
+func (s *registrationServer) GetRecord(ctx context.Context, ref *pb.RecordRef) (*pb.DNSRecord, error) {
+	_, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	record, err := store.Get(ref.GetId())
+	if err == nil && !id.InScope(record) {
+		// tokens can't see past their scopes
+		err = util.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, grpcError(false, err)
+	}
+	return record, nil
+}
+
+func (s *registrationServer) ListRecords(ctx context.Context, query *pb.RecordQuery) (*pb.DNSRecordList, error) {
+	_, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if query.GetZone() == "" {
+		return nil, grpcError(false, fieldError("zone", "missing"))
+	}
+	records, err := store.List(query.GetZone(), query.GetName())
+	if err != nil {
+		return nil, grpcError(false, err)
+	}
+	return &pb.DNSRecordList{Records: inScope(id, records)}, nil
+}
+
+func (s *registrationServer) UpdateRecord(ctx context.Context, record *pb.DNSRecord) (*pb.DNSRecord, error) {
+	req, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	errs := prefixFields("", util.ValidateDNSRecord(record))
+	if record.GetId() == 0 {
+		errs = append(errs, &pb.FieldError{Field: "id", Message: "missing"})
+	}
+	if len(errs) != 0 {
+		return nil, grpcError(false, errs)
+	}
+	changes, err := change(req, id, &pb.RecordOperation{
+		Op:       "replace",
+		Id:       record.GetId(),
+		Record:   record,
+		Revision: record.GetRevision(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes[0].GetAfter(), nil
+}
+
+func (s *registrationServer) DeleteRecord(ctx context.Context, ref *pb.RecordRef) (*pb.RecordChange, error) {
+	req, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if ref.GetId() == 0 {
+		return nil, grpcError(false, fieldError("id", "missing"))
+	}
+	changes, err := change(req, id, &pb.RecordOperation{
+		Op:       "delete",
+		Id:       ref.GetId(),
+		Revision: ref.GetRevision(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes[0], nil
+}
+
+func (s *registrationServer) BatchRecords(ctx context.Context, batch *pb.RecordBatch) (*pb.RecordChangeList, error) {
+	req, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateBatch(batch); err != nil {
+		return nil, grpcError(false, err)
+	}
+	changes, err := change(req, id, batch.GetOperations()...)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RecordChangeList{Changes: changes}, nil
+}
+
+func (s *registrationServer) GetZone(ctx context.Context, query *pb.ZoneQuery) (*pb.ZoneSnapshot, error) {
+	_, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if query.GetZone() == "" {
+		return nil, grpcError(false, fieldError("zone", "missing"))
+	}
+	snapshot, err := zoneSnapshot(store, query.GetZone(), queryPoint(query))
+	if err != nil {
+		return nil, grpcError(false, err)
+	}
+	snapshot.Records = inScope(id, snapshot.GetRecords())
+	return snapshot, nil
+}
+
+func (s *registrationServer) ZoneHistory(ctx context.Context, query *pb.ZoneQuery) (*pb.RecordVersionList, error) {
+	_, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if query.GetZone() == "" {
+		return nil, grpcError(false, fieldError("zone", "missing"))
+	}
+	versions, err := store.ZoneHistory(query.GetZone(), queryPoint(query))
+	if err != nil {
+		return nil, grpcError(false, err)
+	}
+	return &pb.RecordVersionList{Versions: versionsInScope(id, versions)}, nil
+}
+
+func (s *registrationServer) RollbackZone(ctx context.Context, query *pb.ZoneQuery) (*pb.ZoneSnapshot, error) {
+	req, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var errs util.FieldErrors
+	if query.GetZone() == "" {
+		errs = append(errs, &pb.FieldError{Field: "zone", Message: "missing"})
+	}
+	if queryPoint(query) == (util.ZonePoint{}) {
+		errs = append(errs, &pb.FieldError{Field: "serial", Message: "missing, as is time"})
+	}
+	if len(errs) != 0 {
+		return nil, grpcError(false, errs)
+	}
+	if err := auth.CanManageZone(id, query.GetZone()); err != nil {
+		return nil, grpcError(false, err)
+	}
+	snapshot, err := rollback(req, id, query.GetZone(), queryPoint(query), query.GetIfSerial())
+	if err != nil {
+		return nil, grpcError(query.GetIfSerial() != 0, err)
+	}
+	return snapshot, nil
+}
+
+func (s *registrationServer) DelegateZone(ctx context.Context, delegation *pb.ZoneDelegation) (*pb.ZoneDelegation, error) {
+	req, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	delegation = &pb.ZoneDelegation{Zone: delegation.GetZone(), User: delegation.GetUser()}
+	err = checkDelegation(id, delegation.GetZone(), delegation.GetUser())
+	if err == nil {
+		err = delegate(req, id, delegation)
+	}
+	if err != nil {
+		return nil, grpcError(false, err)
+	}
+	return delegation, nil
+}
+
+func (s *registrationServer) UndelegateZone(ctx context.Context, delegation *pb.ZoneDelegation) (*pb.ZoneDelegation, error) {
+	req, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	delegation = &pb.ZoneDelegation{Zone: delegation.GetZone(), User: delegation.GetUser()}
+	err = checkDelegation(id, delegation.GetZone(), delegation.GetUser())
+	if err == nil {
+		err = undelegate(req, id, delegation)
+	}
+	if err != nil {
+		return nil, grpcError(false, err)
+	}
+	return delegation, nil
+}
+
+func (s *registrationServer) ListDelegations(ctx context.Context, query *pb.ZoneQuery) (*pb.ZoneDelegationList, error) {
+	if _, _, err := identify(ctx); err != nil {
+		return nil, err
+	}
+	delegations, err := store.Delegations(query.GetZone())
+	if err != nil {
+		return nil, grpcError(false, err)
+	}
+	return &pb.ZoneDelegationList{Delegations: delegations}, nil
+}
+
+func (s *registrationServer) ListDevices(ctx context.Context, query *pb.DeviceQuery) (*pb.DeviceList, error) {
+	_, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var records []*pb.DNSRecord
+	if query.GetZone() != "" {
+		records, err = store.List(query.GetZone(), "")
+	} else {
+		user := id.User
+		if id.Admin && query.GetUser() != "" {
+			user = query.GetUser()
+		}
+		records, err = store.OwnedRecords(user)
+	}
+	if err != nil {
+		return nil, grpcError(false, err)
+	}
+	return &pb.DeviceList{Devices: util.Devices(inScope(id, records))}, nil
+}
+
+func (s *registrationServer) GetDevice(ctx context.Context, device *pb.Device) (*pb.Device, error) {
+	_, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records, err := deviceRecords(id, device)
+	if err != nil {
+		return nil, grpcError(false, err)
+	}
+	return &pb.Device{Zone: device.GetZone(), Name: device.GetName(), Records: records}, nil
+}
+
+func (s *registrationServer) PutDevice(ctx context.Context, device *pb.Device) (*pb.RecordChangeList, error) {
+	req, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records, err := deviceRecords(id, device)
+	if err != nil && !errors.Is(err, util.ErrRecordNotFound) {
+		return nil, grpcError(false, err)
+	}
+
+	// the records which are kept are given, and the rest deleted first,
+	// so they can't clash with those replacing them
+	kept := map[uint64]*interface{}{}
+	var ops, given []*pb.RecordOperation
+	var errs util.FieldErrors
+	for i, record := range device.GetRecords() {
+		record.Zone, record.Name = device.GetZone(), device.GetName()
+		errs = append(errs, prefixFields(fmt.Sprintf("records[%d].", i), util.ValidateDNSRecord(record))...)
+		if record.GetId() == 0 {
+			given = append(given, &pb.RecordOperation{Op: "add", Record: record})
+			continue
+		}
+		kept[record.GetId()] = nil
+		given = append(given, &pb.RecordOperation{
+			Op:       "replace",
+			Id:       record.GetId(),
+			Record:   record,
+			Revision: record.GetRevision(),
+		})
+	}
+	for _, record := range records {
+		if _, ok := kept[record.GetId()]; !ok {
+			ops = append(ops, &pb.RecordOperation{Op: "delete", Id: record.GetId()})
+		}
+	}
+	if len(errs) != 0 {
+		return nil, grpcError(false, errs)
+	}
+	ops = append(ops, given...)
+	if len(ops) == 0 {
+		return &pb.RecordChangeList{}, nil
+	}
+
+	changes, err := change(req, id, ops...)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RecordChangeList{Changes: changes}, nil
+}
+
+func (s *registrationServer) DeleteDevice(ctx context.Context, device *pb.Device) (*pb.RecordChangeList, error) {
+	req, id, err := identify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records, err := deviceRecords(id, device)
+	if err != nil {
+		return nil, grpcError(false, err)
+	}
+	var ops []*pb.RecordOperation
+	for _, record := range records {
+		ops = append(ops, &pb.RecordOperation{Op: "delete", Id: record.GetId()})
+	}
+	changes, err := change(req, id, ops...)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RecordChangeList{Changes: changes}, nil
+}
+
+// Watch sends versions as they are made until the caller goes away. It is
+// woken up by changes made through the API, and looks for others every
+// watchPollInterval.
+func (s *registrationServer) Watch(query *pb.WatchRequest, stream pb.DeviceRegistration_WatchServer) error {
+	_, id, err := identify(stream.Context())
+	if err != nil {
+		return err
+	}
+	if query.GetZone() == "" {
+		return grpcError(false, fieldError("zone", "missing"))
+	}
+
+	seen := query.GetSerial()
+	for {
+		// wait on changes from before we look, so none are missed
+		changed := recordChanges.Wait()
+		serial, err := store.ZoneSerial(query.GetZone())
+		if err != nil {
+			return grpcError(false, err)
+		}
+		if serial > seen {
+			versions, err := store.ZoneHistorySince(query.GetZone(), seen)
+			if err != nil {
+				return grpcError(false, err)
+			}
+			for _, version := range versionsInScope(id, versions) {
+				if err := stream.Send(version); err != nil {
+					return err
+				}
+			}
+			if len(versions) != 0 {
+				seen = versions[len(versions)-1].GetSerial()
+			}
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-changed:
+		case <-time.After(watchPollInterval):
+		}
+	}
+}
+
+// deviceRecords returns the records of device which id can see, or
+// ErrRecordNotFound if there aren't any.
+func deviceRecords(id *util.Identity, device *pb.Device) ([]*pb.DNSRecord, error) {
+	var errs util.FieldErrors
+	for _, field := range []struct{ name, val string }{{"zone", device.GetZone()}, {"name", device.GetName()}} {
+		if field.val == "" {
+			errs = append(errs, &pb.FieldError{Field: field.name, Message: "missing"})
+		}
+	}
+	if len(errs) != 0 {
+		return nil, errs
+	}
+	records, err := store.List(device.GetZone(), device.GetName())
+	if err != nil {
+		return nil, err
+	}
+	records = inScope(id, records)
+	if len(records) == 0 {
+		return nil, util.ErrRecordNotFound
+	}
+	return records, nil
+}
+
+// queryPoint returns the point in the history of a zone query asks about.
+func queryPoint(query *pb.ZoneQuery) util.ZonePoint {
+	return util.ZonePoint{Serial: query.GetSerial(), Time: query.GetTime()}
+}
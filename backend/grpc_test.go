@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+func TestIdentifyIgnoresProxyHeaders(t *testing.T) {
+	store = util.NewMemoryStore()
+	var err error
+	auth, err = util.NewAuth(&pb.ServerConfig{AuthConf: &pb.AuthConfig{
+		ProxyUserHeader: "X-Forwarded-User",
+		TrustedProxies:  []string{"127.0.0.0/8"},
+	}}, store)
+	if err != nil {
+		t.Fatalf("error setting up auth: %v", err)
+	}
+	info := &pb.APIToken{User: "alice"}
+	token, err := util.NewToken(info)
+	if err != nil {
+		t.Fatalf("error making token: %v", err)
+	}
+	if err := store.CreateToken(info); err != nil {
+		t.Fatalf("error storing token: %v", err)
+	}
+
+	// from an address the HTTP API would believe proxy headers from
+	call := func(kv ...string) context.Context {
+		ctx := peer.NewContext(context.Background(),
+			&peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 12345}})
+		return metadata.NewIncomingContext(ctx, metadata.Pairs(kv...))
+	}
+
+	_, _, err = identify(call("x-forwarded-user", "root"))
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("claiming to be root: err = %v, want %v", err, codes.Unauthenticated)
+	}
+
+	req, id, err := identify(call("authorization", "Bearer "+token, "x-forwarded-for", "192.0.2.1"))
+	if err != nil || id.User != "alice" {
+		t.Fatalf("calling with a token: identity = %+v, %v, want alice", id, err)
+	}
+	if ip := auth.ClientIP(req); ip != "127.0.0.1" {
+		t.Errorf("client IP = %s, want the address of the caller", ip)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+// zoneHistory replies with the versions of the records in the zone in the
+// zone field, up to the point given by the serial or time fields (see
+// zonePoint), oldest first.
+func zoneHistory(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	zone, at, ok := zoneForm(resp, req)
+	if !ok {
+		return
+	}
+	versions, err := store.ZoneHistory(zone, at)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	writeProto(resp, http.StatusOK, &pb.RecordVersionList{Versions: versionsInScope(id, versions)})
+}
+
+// versionsInScope returns the versions of records id can see.
+func versionsInScope(id *util.Identity, versions []*pb.RecordVersion) []*pb.RecordVersion {
+	var scoped []*pb.RecordVersion
+	for _, version := range versions {
+		if id.InScope(&pb.DNSRecord{Zone: version.GetZone(), Name: version.GetName()}) {
+			scoped = append(scoped, version)
+		}
+	}
+	return scoped
+}
+
+// zoneAt replies with the records in the zone in the zone field as they were
+// at the point given by the serial or time fields, or now if neither is set.
+// The ETag is the serial they were at.
+func zoneAt(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	zone, at, ok := zoneForm(resp, req)
+	if !ok {
+		return
+	}
+	snapshot, err := zoneSnapshot(store, zone, at)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	snapshot.Records = inScope(id, snapshot.GetRecords())
+	resp.Header().Set("ETag", etag(snapshot.GetSerial()))
+	writeProto(resp, http.StatusOK, snapshot)
+}
+
+// zoneDiff replies with the changes made to the zone in the zone field
+// between two points, given by the from_serial or from_time fields and the
+// to_serial or to_time fields. Leaving out the end point means now.
+func zoneDiff(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	zone := req.FormValue("zone")
+	if zone == "" {
+		writeError(resp, http.StatusBadRequest, fieldError("zone", "missing"))
+		return
+	}
+	from, err := zonePoint(req, "from_")
+	if err == nil && from == (util.ZonePoint{}) {
+		err = fieldError("from_serial", "missing, as is from_time")
+	}
+	to, err2 := zonePoint(req, "to_")
+	if err == nil {
+		err = err2
+	}
+	if err != nil {
+		writeError(resp, http.StatusBadRequest, err)
+		return
+	}
+
+	before, err := zoneSnapshot(store, zone, from)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	after, err := zoneSnapshot(store, zone, to)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+
+	list := &pb.RecordChangeList{}
+	for _, change := range util.DiffZone(before.Records, after.Records) {
+		if record := change.GetBefore(); record != nil && !id.InScope(record) {
+			continue
+		}
+		if record := change.GetAfter(); record != nil && !id.InScope(record) {
+			continue
+		}
+		list.Changes = append(list.Changes, change)
+	}
+	writeProto(resp, http.StatusOK, list)
+}
+
+// rollbackZone puts the records in the zone in the zone field back to how
+// they were at the point given by the serial or time fields, all at once, and
+// replies with the zone as it is now. Only admins and those the zone is
+// delegated to may roll it back. If-Match is honoured, with the serial of the
+// zone as its ETag.
+func rollbackZone(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	zone, at, ok := zoneForm(resp, req)
+	if !ok {
+		return
+	}
+	if at == (util.ZonePoint{}) {
+		writeError(resp, http.StatusBadRequest, fieldError("serial", "missing, as is time"))
+		return
+	}
+	if err := auth.CanManageZone(id, zone); err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+
+	serial, err := store.ZoneSerial(zone)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	if !ifMatch(resp, req, serial) {
+		return
+	}
+	// this makes sure the zone hasn't changed since we read it
+	var ifSerial uint64
+	conditional := req.Header.Get("If-Match") != ""
+	if conditional {
+		ifSerial = serial
+	}
+	after, err := rollback(req, id, zone, at, ifSerial)
+	if err != nil {
+		writeChangeError(resp, conditional, err)
+		return
+	}
+	resp.Header().Set("ETag", etag(after.GetSerial()))
+	writeProto(resp, http.StatusOK, after)
+}
+
+// rollback rolls zone back to at, as long as it is still at ifSerial if that
+// is set and nobody ends up over their quota, audits it as done by id, and
+// returns the zone as it is now. Whether id may do so has already been
+// checked.
+func rollback(req *http.Request, id *util.Identity, zone string, at util.ZonePoint, ifSerial uint64) (*pb.ZoneSnapshot, error) {
+	var after *pb.ZoneSnapshot
+	err := store.Transaction(func(tx util.RecordStore) error {
+		before, err := zoneSnapshot(tx, zone, util.ZonePoint{})
+		if err != nil {
+			return err
+		}
+		changes, serial, err := tx.RollbackZone(zone, at, ifSerial)
+		if err != nil {
+			return err
+		}
+		if err := quotas.CheckChanges(tx, id, changes); err != nil {
+			return err
+		}
+		after, err = zoneSnapshot(tx, zone, util.ZonePoint{Serial: serial})
+		if err != nil {
+			return err
+		}
+		return audit(tx, req, id, util.AuditRollbackZone, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+	recordChanges.Changed()
+	return after, nil
+}
+
+// zoneSnapshot returns the records in zone as they were at at, as s has them.
+func zoneSnapshot(s util.RecordStore, zone string, at util.ZonePoint) (*pb.ZoneSnapshot, error) {
+	versions, err := s.ZoneHistory(zone, at)
+	if err != nil {
+		return nil, err
+	}
+	records, serial := util.ZoneAt(versions)
+	return &pb.ZoneSnapshot{Zone: zone, Serial: serial, Records: records}, nil
+}
+
+// zoneForm reads the zone field, and the point in its history given by the
+// serial or time fields. If it returns false it has already replied.
+func zoneForm(resp http.ResponseWriter, req *http.Request) (string, util.ZonePoint, bool) {
+	zone := req.FormValue("zone")
+	if zone == "" {
+		writeError(resp, http.StatusBadRequest, fieldError("zone", "missing"))
+		return "", util.ZonePoint{}, false
+	}
+	at, err := zonePoint(req, "")
+	if err != nil {
+		writeError(resp, http.StatusBadRequest, err)
+		return "", util.ZonePoint{}, false
+	}
+	return zone, at, true
+}
+
+// zonePoint reads a point in the history of a zone from the fields
+// <prefix>serial and <prefix>time, the latter in RFC 3339. Neither being set
+// means now.
+func zonePoint(req *http.Request, prefix string) (util.ZonePoint, error) {
+	var at util.ZonePoint
+	if serial := req.FormValue(prefix + "serial"); serial != "" {
+		var err error
+		at.Serial, err = strconv.ParseUint(serial, 10, 64)
+		if err != nil {
+			return at, fieldError(prefix+"serial", "must be a serial")
+		}
+	}
+	if t := req.FormValue(prefix + "time"); t != "" {
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return at, fieldError(prefix+"time", "must be an RFC 3339 time")
+		}
+		at.Time = parsed.Unix()
+	}
+	return at, nil
+}
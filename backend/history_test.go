@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+func TestZoneHistoryEndpoints(t *testing.T) {
+	testAPI(t)
+	for _, value := range []string{"192.0.2.1", "192.0.2.2"} {
+		resp := serve(apiRequest("alice", http.MethodPost, "/v1/addDNSRecord",
+			"zone=valid.zone.&name=host&type=1&ttl=60&value="+value))
+		if resp.Code != http.StatusCreated {
+			t.Fatalf("adding %s: status = %d: %s", value, resp.Code, resp.Body)
+		}
+	}
+
+	resp := serve(apiRequest("alice", http.MethodGet, "/v1/zoneHistory?zone=valid.zone.", ""))
+	versions := &pb.RecordVersionList{}
+	readReply(t, resp, versions)
+	if resp.Code != http.StatusOK || len(versions.GetVersions()) != 2 {
+		t.Errorf("history: status = %d, versions = %v", resp.Code, versions)
+	}
+
+	resp = serve(apiRequest("alice", http.MethodGet, "/v1/zoneAt?zone=valid.zone.&serial=1", ""))
+	snapshot := &pb.ZoneSnapshot{}
+	readReply(t, resp, snapshot)
+	if resp.Code != http.StatusOK || resp.Header().Get("ETag") != `"1"` || len(snapshot.GetRecords()) != 1 {
+		t.Errorf("zone at 1: status = %d, ETag = %s, snapshot = %v", resp.Code, resp.Header().Get("ETag"), snapshot)
+	}
+
+	resp = serve(apiRequest("alice", http.MethodGet, "/v1/zoneDiff?zone=valid.zone.&from_serial=1", ""))
+	changes := &pb.RecordChangeList{}
+	readReply(t, resp, changes)
+	if resp.Code != http.StatusOK || len(changes.GetChanges()) != 1 || changes.GetChanges()[0].GetBefore() != nil {
+		t.Errorf("diff from 1: status = %d, changes = %v, want the second record added", resp.Code, changes)
+	}
+	if resp := serve(apiRequest("alice", http.MethodGet, "/v1/zoneDiff?zone=valid.zone.", "")); resp.Code != http.StatusBadRequest {
+		t.Errorf("diff from nowhere: status = %d, want 400", resp.Code)
+	}
+
+	rollback := func(user, ifMatch string) *http.Request {
+		req := apiRequest(user, http.MethodPost, "/v1/rollbackZone", "zone=valid.zone.&serial=1")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		return req
+	}
+	// only admins and delegates may roll back
+	if resp := serve(rollback("alice", "")); resp.Code != http.StatusForbidden {
+		t.Errorf("rolling back as alice: status = %d, want 403", resp.Code)
+	}
+	if err := store.Delegate("valid.zone.", "bob"); err != nil {
+		t.Fatalf("error delegating: %v", err)
+	}
+	if resp := serve(rollback("bob", `"1"`)); resp.Code != http.StatusPreconditionFailed || resp.Header().Get("ETag") != `"2"` {
+		t.Errorf("rolling back an old serial: status, ETag = %d, %s, want 412, \"2\"", resp.Code, resp.Header().Get("ETag"))
+	}
+	resp = serve(rollback("bob", `"2"`))
+	readReply(t, resp, snapshot)
+	if resp.Code != http.StatusOK || resp.Header().Get("ETag") != `"3"` || len(snapshot.GetRecords()) != 1 {
+		t.Errorf("rolling back: status = %d, ETag = %s, snapshot = %v", resp.Code, resp.Header().Get("ETag"), snapshot)
+	}
+	if records, _ := store.List("valid.zone.", ""); len(records) != 1 {
+		t.Errorf("after rolling back the zone has %v", records)
+	}
+
+	// rolling forward again would take alice over her quota, which has since
+	// been lowered
+	quotas.Config.Users = map[string]*pb.Quota{"alice": {MaxRecords: 1}}
+	resp = serve(apiRequest("bob", http.MethodPost, "/v1/rollbackZone", "zone=valid.zone.&serial=2"))
+	if resp.Code != http.StatusForbidden {
+		t.Errorf("rolling back over alice's quota: status = %d, want 403: %s", resp.Code, resp.Body)
+	}
+	if serial, _ := store.ZoneSerial("valid.zone."); serial != 3 {
+		t.Errorf("serial = %d after a failed rollback, want 3", serial)
+	}
+}
@@ -1,42 +1,102 @@
 package main
 
 import (
+	"crypto/tls"
+	"errors"
+	"expvar"
 	"fmt"
 	"net/http"
+	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
 	"github.com/gidoBOSSftw5731/log"
 	"github.com/miekg/dns"
-	"gorm.io/gorm"
 
 	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
 )
 
+// How long keepServing waits before starting a server again, doubling from
+// minRetryWait with every failure up to maxRetryWait.
+const (
+	minRetryWait = time.Second
+	maxRetryWait = time.Minute
+)
+
 // Handler is the struct that holds the server
 type Handler struct{}
 
 var (
-	config *pb.ServerConfig
-	db     *gorm.DB
+	config     *pb.ServerConfig
+	store      util.RecordStore
+	auth       *util.Auth
+	quotas     *util.Quotas
+	dnsHandler util.DNSHandler
+	// recordChanges wakes up gRPC watchers when records are changed through
+	// the API.
+	recordChanges = util.NewChangeFeed()
 )
 
+// routes are the endpoints of the API, under /v1/. Each is described in
+// openapi.json.
+var routes = map[string]func(http.ResponseWriter, *http.Request, *util.Identity){
+	"addDNSRecord":    addDNSRecord,
+	"getDNSRecord":    getDNSRecord,
+	"listDNSRecords":  listDNSRecords,
+	"updateDNSRecord": updateDNSRecord,
+	"deleteDNSRecord": deleteDNSRecord,
+	"batchDNSRecords": batchDNSRecords,
+	"delegateZone":    delegateZone,
+	"undelegateZone":  undelegateZone,
+	"listDelegations": listDelegations,
+	"zoneHistory":     zoneHistory,
+	"zoneAt":          zoneAt,
+	"zoneDiff":        zoneDiff,
+	"rollbackZone":    rollbackZone,
+	"auditLog":        auditLog,
+	"quotaUsage":      quotaUsage,
+	"createToken":     createToken,
+	"listTokens":      listTokens,
+	"revokeToken":     revokeToken,
+}
+
 func main() {
 	// set log depth
 	log.SetCallDepth(4)
 
 	config = util.ReadConf(util.DefaultConfig, "../.env")
 
+	// the migrate subcommand manages the database schema, then exits
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err := migrate(os.Args[2:])
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+
 	var err error
-	// configure database
-	db, err = util.ConfDB(config.DBConf)
+	// configure the database, or wherever else records are kept
+	store, err = util.OpenStore(config)
+	if err != nil {
+		log.Panicln(err)
+	}
+	auth, err = util.NewAuth(config, store)
 	if err != nil {
 		log.Panicln(err)
 	}
+	if auth.Directory != nil && config.LdapConf.GetSyncInterval() != 0 {
+		go auth.Directory.Sync(store)
+	}
+	quotas = &util.Quotas{Config: config.GetQuotaConf(), Store: store,
+		Directory: auth.Directory}
 
 	// start DNS server
 	dnsServer()
+	grpcServer()
+	debugServer()
 
 	s := &Handler{}
 
@@ -45,42 +105,76 @@ func main() {
 
 // ServeHTTP is the function that handles the incoming requests
 func (h *Handler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	// DNS over HTTPS lives outside of the versioned API
+	if req.URL.Path == util.DoHPath {
+		dnsHandler.ServeDoH(resp, req)
+		return
+	}
+	// the description of the API is for anyone to read
+	if req.URL.Path == openAPIPath {
+		serveOpenAPI(resp, req)
+		return
+	}
+
 	// split request into parts
 	URLSplit := strings.Split(req.URL.Path, "/")
 
 	if len(URLSplit) < 3 {
-		log.Errorln("Invalid request")
-		resp.WriteHeader(http.StatusBadRequest)
+		writeError(resp, http.StatusBadRequest, errors.New("invalid request"))
 		return
 	}
 
 	// Check if the api version is correct:
 	if URLSplit[1] != "v1" {
-		log.Errorln("Invalid API version")
-		resp.WriteHeader(http.StatusBadRequest)
+		writeError(resp, http.StatusBadRequest, errors.New("invalid API version"))
 		return
 	}
 
-	switch URLSplit[2] {
-	case "addDNSRecord":
-		// parse a POST form into a DNSRecord
-		dnsRecord, err := util.ParseDNSRecord(req)
-		if err != nil {
-			log.Errorln(err)
-			resp.WriteHeader(http.StatusBadRequest)
-			return
-		}
-		db.Create(dnsRecord)
+	// everything in the API needs to know who is asking
+	id, err := auth.Authenticate(req)
+	switch {
+	case errors.Is(err, util.ErrForbidden):
+		writeError(resp, http.StatusForbidden, err)
+		return
+	case err != nil:
+		log.Errorln("Unauthenticated request:", err)
+		writeError(resp, http.StatusUnauthorized, util.ErrUnauthenticated)
+		return
+	}
+
+	handler, ok := routes[URLSplit[2]]
+	if !ok {
+		writeError(resp, http.StatusNotFound, errors.New("no such endpoint"))
+		return
 	}
+	handler(resp, req, id)
+}
 
+// debugServer serves counters, such as how often rate limiting kicks in, on
+// their own listener so they can be kept off the network.
+func debugServer() {
+	if config.GetDebugListenAddr() == "" {
+		return
+	}
+	debugMux := http.NewServeMux()
+	debugMux.Handle("/debug/vars", expvar.Handler())
+	go keepServing("debug", func() error {
+		return http.ListenAndServe(config.GetDebugListenAddr(), debugMux)
+	})
 }
 
 func dnsServer() {
 	log.Infoln("Starting DNS server")
 	dnsMux := dns.NewServeMux()
-	dnsHandler := util.DNSHandler{
-		Config: config,
-		DB:     db,
+	cookieSecret, err := util.NewCookieSecret(config.DnsConf.GetCookieSecret())
+	if err != nil {
+		log.Panicln(err)
+	}
+	dnsHandler = util.DNSHandler{
+		Config:       config,
+		Store:        store,
+		RRL:          util.NewRateLimiter(config.DnsConf.GetRrl()),
+		CookieSecret: cookieSecret,
 	}
 	dnsMux.HandleFunc(".", dnsHandler.HandleDNS)
 	server := &dns.Server{
@@ -93,21 +187,73 @@ func dnsServer() {
 		Net:     "tcp",
 		Handler: dnsMux,
 	}
-	go func() {
-		// Don't let the DNS server die, we need it to keep running
-		for {
-			log.Errorln(server.ListenAndServe())
+	// Don't let the DNS servers die, we need them to keep running
+	go keepServing("DNS over UDP", server.ListenAndServe)
+	go keepServing("DNS over TCP", func() error {
+		// https://github.com/rimiti/kill-port/blob/master/main.go
+		command := fmt.Sprintf("lsof -i tcp:%s | grep LISTEN | awk '{print $2}' | xargs kill -9",
+			config.DnsConf.GetListenPort()[1:])
+		util.Exec_cmd(exec.Command("bash", "-c", command))
+
+		return serverTCP.ListenAndServe()
+	})
+
+	// DNS over TLS and HTTPS are optional, and only started if we have a
+	// certificate. Either can be served without the other.
+	if config.DnsConf.GetTlsCertFile() == "" || config.DnsConf.GetTlsKeyFile() == "" {
+		return
+	}
+	cert, err := tls.LoadX509KeyPair(config.DnsConf.GetTlsCertFile(),
+		config.DnsConf.GetTlsKeyFile())
+	if err != nil {
+		log.Errorln("Not starting DNS over TLS or HTTPS servers:", err)
+		return
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if config.DnsConf.GetTlsListenPort() != "" {
+		serverTLS := &dns.Server{
+			Addr:      config.DnsConf.GetTlsListenPort(),
+			Net:       "tcp-tls",
+			Handler:   dnsMux,
+			TLSConfig: tlsConfig,
+		}
+		go keepServing("DNS over TLS", serverTLS.ListenAndServe)
+	}
+
+	if config.DnsConf.GetDohListenAddr() != "" {
+		dohMux := http.NewServeMux()
+		dohMux.HandleFunc(util.DoHPath, dnsHandler.ServeDoH)
+		serverDoH := &http.Server{
+			Addr:      config.DnsConf.GetDohListenAddr(),
+			Handler:   dohMux,
+			TLSConfig: tlsConfig,
+		}
+		go keepServing("DNS over HTTPS", func() error {
+			return serverDoH.ListenAndServeTLS("", "")
+		})
+	}
+}
+
+// keepServing runs serve again whenever it stops, until the program exits.
+// It waits longer after each failure in a row, so a port which can't be
+// bound doesn't fill the log.
+func keepServing(name string, serve func() error) {
+	wait := minRetryWait
+	for {
+		started := time.Now()
+		err := serve()
+		// a server which ran for a while before failing starts over
+		if time.Since(started) > maxRetryWait {
+			wait = minRetryWait
 		}
-	}()
-	go func() {
-		// Don't let the DNS server die, we need it to keep running
-		for {
-			// https://github.com/rimiti/kill-port/blob/master/main.go
-			command := fmt.Sprintf("lsof -i tcp:%s | grep LISTEN | awk '{print $2}' | xargs kill -9",
-				config.DnsConf.GetListenPort()[1:])
-			util.Exec_cmd(exec.Command("bash", "-c", command))
-
-			log.Errorln(serverTCP.ListenAndServe())
+		log.Errorf("%s server stopped, retrying in %v: %v", name, wait, err)
+		time.Sleep(wait)
+		if wait *= 2; wait > maxRetryWait {
+			wait = maxRetryWait
 		}
-	}()
+	}
 }
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+// testAPI sets the API up with nothing in an in-memory store. Callers are
+// whoever the X-Forwarded-User header says, as httptest requests come from
+// a trusted proxy, and the user admin is an admin.
+func testAPI(t *testing.T) {
+	t.Helper()
+	config = &pb.ServerConfig{AuthConf: &pb.AuthConfig{
+		ProxyUserHeader: "X-Forwarded-User",
+		TrustedProxies:  []string{"192.0.2.0/24"},
+		AdminUsers:      []string{"admin"},
+	}}
+	store = util.NewMemoryStore()
+	var err error
+	auth, err = util.NewAuth(config, store)
+	if err != nil {
+		t.Fatalf("error setting up auth: %v", err)
+	}
+	quotas = &util.Quotas{Config: &pb.QuotaConfig{}, Store: store}
+}
+
+// apiRequest returns a request to the API made by user. Bodies starting with
+// { are sent as JSON, and anything else as a form.
+func apiRequest(user, method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("X-Forwarded-User", user)
+	switch {
+	case strings.HasPrefix(body, "{"):
+		req.Header.Set("Content-Type", "application/json")
+	case body != "":
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return req
+}
+
+// serve has the API answer req.
+func serve(req *http.Request) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	(&Handler{}).ServeHTTP(resp, req)
+	return resp
+}
+
+// readReply decodes the JSON body of resp into msg.
+func readReply(t *testing.T, resp *httptest.ResponseRecorder, msg proto.Message) {
+	t.Helper()
+	if err := protojson.Unmarshal(resp.Body.Bytes(), msg); err != nil {
+		t.Fatalf("error decoding %q: %v", resp.Body.String(), err)
+	}
+}
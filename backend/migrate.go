@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
+)
+
+// migrate implements the migrate subcommand, which manages the database
+// schema without starting the server:
+//
+//	migrate [up]            run every pending migration
+//	migrate down <version>  undo migrations until the schema is at version
+//	migrate status          list migrations and whether they have been run
+func migrate(args []string) error {
+	db, err := util.OpenDB(config)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		args = []string{"up"}
+	}
+	switch args[0] {
+	case "up":
+		return util.Migrate(db)
+	case "down":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: migrate down <version>")
+		}
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[1], err)
+		}
+		return util.MigrateTo(db, uint32(version))
+	case "status":
+		status, err := util.MigrationStatus(db)
+		if err != nil {
+			return err
+		}
+		for _, line := range status {
+			fmt.Println(line)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown migrate command %q", args[0])
+}
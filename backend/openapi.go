@@ -0,0 +1,21 @@
+package main
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPIPath is where the OpenAPI document describing the API is served.
+const openAPIPath = "/openapi.json"
+
+// openAPI describes every endpoint in routes. Tests check it stays in step
+// with the handlers and drs.proto.
+//
+//go:embed openapi.json
+var openAPI []byte
+
+// serveOpenAPI replies with the OpenAPI document, which needs no login.
+func serveOpenAPI(resp http.ResponseWriter, _ *http.Request) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Write(openAPI)
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+// openAPIDoc is as much of an OpenAPI document as the tests look at.
+type openAPIDoc struct {
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components struct {
+		Responses map[string]struct {
+			Content map[string]openAPIMedia `json:"content"`
+		} `json:"responses"`
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	Parameters []struct {
+		Name string `json:"name"`
+		In   string `json:"in"`
+	} `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]openAPIMedia `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Ref string `json:"$ref"`
+	} `json:"responses"`
+}
+
+type openAPIMedia struct {
+	Schema openAPISchema `json:"schema"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	Type       string                    `json:"type"`
+	Format     string                    `json:"format"`
+	Items      *openAPISchema            `json:"items"`
+	Properties map[string]*openAPISchema `json:"properties"`
+}
+
+func readOpenAPI(t *testing.T) *openAPIDoc {
+	t.Helper()
+	doc := &openAPIDoc{}
+	if err := json.Unmarshal(openAPI, doc); err != nil {
+		t.Fatalf("error parsing openapi.json: %v", err)
+	}
+	return doc
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	resp := httptest.NewRecorder()
+	// no login is needed
+	(&Handler{}).ServeHTTP(resp, httptest.NewRequest("GET", openAPIPath, nil))
+	if resp.Code != http.StatusOK || resp.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("status, type = %d, %q", resp.Code, resp.Header().Get("Content-Type"))
+	}
+	if resp.Body.String() != string(openAPI) {
+		t.Error("served something other than openapi.json")
+	}
+}
+
+func TestOpenAPIPaths(t *testing.T) {
+	doc := readOpenAPI(t)
+	for route := range routes {
+		if _, ok := doc.Paths["/v1/"+route]; !ok {
+			t.Errorf("/v1/%s isn't documented", route)
+		}
+	}
+	for path, operations := range doc.Paths {
+		if _, ok := routes[strings.TrimPrefix(path, "/v1/")]; !ok {
+			t.Errorf("%s is documented, but isn't a route", path)
+		}
+		if len(operations) != 1 {
+			t.Errorf("%s has %d operations, want 1", path, len(operations))
+		}
+
+		for _, operation := range operations {
+			succeeds := false
+			for code, response := range operation.Responses {
+				if strings.HasPrefix(code, "2") {
+					succeeds = true
+					continue
+				}
+				// errors are always a pb.Error
+				name := strings.TrimPrefix(response.Ref, "#/components/responses/")
+				if doc.Components.Responses[name].Content["application/json"].Schema.Ref != "#/components/schemas/Error" {
+					t.Errorf("%s: %s response isn't an Error", path, code)
+				}
+			}
+			if !succeeds {
+				t.Errorf("%s: no successful response", path)
+			}
+			// anything in the API can fail to authenticate, or fail
+			for _, code := range []string{"401", "403", "500"} {
+				if _, ok := operation.Responses[code]; !ok {
+					t.Errorf("%s: %s response isn't documented", path, code)
+				}
+			}
+		}
+	}
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	doc := readOpenAPI(t)
+	messages := pb.File_drs_proto.Messages()
+	for name, schema := range doc.Components.Schemas {
+		if name == "DNSRecordForm" {
+			// the form fields are named like drs.proto
+			fields := messages.ByName("DNSRecord").Fields()
+			for property := range schema.Properties {
+				if fields.ByName(protoreflect.Name(property)) == nil {
+					t.Errorf("DNSRecordForm.%s isn't a field of DNSRecord", property)
+				}
+			}
+			continue
+		}
+		msg := messages.ByName(protoreflect.Name(name))
+		if msg == nil {
+			t.Errorf("schema %s isn't a message in drs.proto", name)
+			continue
+		}
+
+		fields := msg.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			field := fields.Get(i)
+			property, ok := schema.Properties[field.JSONName()]
+			if !ok {
+				t.Errorf("%s.%s isn't documented", name, field.JSONName())
+				continue
+			}
+			if field.IsList() {
+				if property.Type != "array" || property.Items == nil {
+					t.Errorf("%s.%s isn't an array", name, field.JSONName())
+					continue
+				}
+				property = property.Items
+			}
+			want := jsonSchema(field)
+			if property.Ref != want.Ref || property.Type != want.Type || property.Format != want.Format {
+				t.Errorf("%s.%s = %q %q %q, want %q %q %q", name, field.JSONName(),
+					property.Ref, property.Type, property.Format, want.Ref, want.Type, want.Format)
+			}
+		}
+		if len(schema.Properties) != fields.Len() {
+			t.Errorf("%s has %d properties, want %d", name, len(schema.Properties), fields.Len())
+		}
+	}
+
+	// everything referred to has to be there
+	var raw interface{}
+	if err := json.Unmarshal(openAPI, &raw); err != nil {
+		t.Fatalf("error parsing openapi.json: %v", err)
+	}
+	for _, ref := range openAPIRefs(raw) {
+		parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+		var found bool
+		switch parts[0] {
+		case "schemas":
+			_, found = doc.Components.Schemas[parts[1]]
+		case "responses":
+			_, found = doc.Components.Responses[parts[1]]
+		}
+		if len(parts) != 2 || !found {
+			t.Errorf("%s doesn't exist", ref)
+		}
+	}
+}
+
+// jsonSchema returns how protojson writes a field which isn't a list.
+func jsonSchema(field protoreflect.FieldDescriptor) openAPISchema {
+	switch field.Kind() {
+	case protoreflect.MessageKind:
+		return openAPISchema{Ref: "#/components/schemas/" + string(field.Message().Name())}
+	case protoreflect.BoolKind:
+		return openAPISchema{Type: "boolean"}
+	case protoreflect.Uint32Kind:
+		return openAPISchema{Type: "integer", Format: "uint32"}
+	case protoreflect.Uint64Kind:
+		// 64 bit numbers are strings in JSON
+		return openAPISchema{Type: "string", Format: "uint64"}
+	case protoreflect.Int64Kind:
+		return openAPISchema{Type: "string", Format: "int64"}
+	}
+	return openAPISchema{Type: "string"}
+}
+
+// openAPIRefs returns every $ref in a decoded JSON document.
+func openAPIRefs(v interface{}) []string {
+	var refs []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if ref, ok := val.(string); ok && key == "$ref" {
+				refs = append(refs, ref)
+			}
+			refs = append(refs, openAPIRefs(val)...)
+		}
+	case []interface{}:
+		for _, val := range v {
+			refs = append(refs, openAPIRefs(val)...)
+		}
+	}
+	return refs
+}
+
+func TestOpenAPIParameters(t *testing.T) {
+	doc := readOpenAPI(t)
+	handlers := parseHandlers(t)
+	for route := range routes {
+		operations := doc.Paths["/v1/"+route]
+		reads := handlers.reads(route, nil, map[string]bool{})
+
+		for _, operation := range operations {
+			var query []string
+			ifMatch := false
+			for _, param := range operation.Parameters {
+				switch {
+				case param.In == "query":
+					query = append(query, param.Name)
+				case param.In == "header" && param.Name == "If-Match":
+					ifMatch = true
+				}
+			}
+			var fields []string
+			for field := range reads.fields {
+				fields = append(fields, field)
+			}
+			sort.Strings(query)
+			sort.Strings(fields)
+			if strings.Join(query, ",") != strings.Join(fields, ",") {
+				t.Errorf("%s documents the fields %v, but reads %v", route, query, fields)
+			}
+
+			if ifMatch != reads.calls["ifMatch"] {
+				t.Errorf("%s: If-Match documented = %v, but honoured = %v", route, ifMatch, reads.calls["ifMatch"])
+			}
+			record := operation.RequestBody != nil &&
+				operation.RequestBody.Content["application/json"].Schema.Ref == "#/components/schemas/DNSRecord"
+			if record != reads.calls["util.ParseDNSRecord"] {
+				t.Errorf("%s: DNSRecord body documented = %v, but read = %v", route, record, reads.calls["util.ParseDNSRecord"])
+			}
+		}
+	}
+}
+
+// handlerSource is what the functions of this package read from requests,
+// found from their source.
+type handlerSource map[string]*ast.FuncDecl
+
+// handlerReads is the form fields a handler reads, and the functions it
+// calls, including through other functions in this package.
+type handlerReads struct {
+	fields map[string]bool
+	calls  map[string]bool
+}
+
+func parseHandlers(t *testing.T) handlerSource {
+	t.Helper()
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", nil, 0)
+	if err != nil {
+		t.Fatalf("error parsing handlers: %v", err)
+	}
+	funcs := handlerSource{}
+	for name, file := range pkgs["main"].Files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+				funcs[fn.Name.Name] = fn
+			}
+		}
+	}
+	return funcs
+}
+
+// reads returns what the function name reads, with the string arguments it
+// was called with in args, following calls it makes in this package. Field
+// names can be string literals, parameters followed by a literal, like in
+// zonePoint, or the keys of a map literal being ranged over, like in
+// auditLog.
+func (s handlerSource) reads(name string, args map[string]string, seen map[string]bool) handlerReads {
+	reads := handlerReads{fields: map[string]bool{}, calls: map[string]bool{}}
+	fn := s[name]
+	key := name + fmtArgs(args)
+	if fn == nil || seen[key] {
+		return reads
+	}
+	seen[key] = true
+
+	// the string literal keys of map literals ranged over, by loop variable
+	ranged := map[string][]string{}
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		loop, ok := n.(*ast.RangeStmt)
+		if !ok {
+			return true
+		}
+		key, isIdent := loop.Key.(*ast.Ident)
+		lit, isLit := loop.X.(*ast.CompositeLit)
+		if !isIdent || !isLit {
+			return true
+		}
+		for _, elt := range lit.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if s, ok := stringValue(kv.Key, args); ok {
+					ranged[key.Name] = append(ranged[key.Name], s)
+				}
+			}
+		}
+		return true
+	})
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		switch callee := call.Fun.(type) {
+		case *ast.SelectorExpr:
+			if pkg, ok := callee.X.(*ast.Ident); ok {
+				reads.calls[pkg.Name+"."+callee.Sel.Name] = true
+			}
+			if callee.Sel.Name != "FormValue" || len(call.Args) != 1 {
+				return true
+			}
+			if field, ok := stringValue(call.Args[0], args); ok {
+				reads.fields[field] = true
+			} else if ident, ok := call.Args[0].(*ast.Ident); ok {
+				for _, field := range ranged[ident.Name] {
+					reads.fields[field] = true
+				}
+			}
+
+		case *ast.Ident:
+			reads.calls[callee.Name] = true
+			calleeFn := s[callee.Name]
+			if calleeFn == nil {
+				return true
+			}
+			calleeArgs := map[string]string{}
+			i := 0
+			for _, param := range calleeFn.Type.Params.List {
+				for _, paramName := range param.Names {
+					if i < len(call.Args) {
+						if s, ok := stringValue(call.Args[i], args); ok {
+							calleeArgs[paramName.Name] = s
+						}
+					}
+					i++
+				}
+			}
+			nested := s.reads(callee.Name, calleeArgs, seen)
+			for field := range nested.fields {
+				reads.fields[field] = true
+			}
+			for call := range nested.calls {
+				reads.calls[call] = true
+			}
+		}
+		return true
+	})
+	return reads
+}
+
+// stringValue returns the string expr always is, given the string arguments
+// of the function it is in.
+func stringValue(expr ast.Expr, args map[string]string) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		if expr.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(expr.Value)
+		return s, err == nil
+	case *ast.Ident:
+		s, ok := args[expr.Name]
+		return s, ok
+	case *ast.BinaryExpr:
+		x, xOK := stringValue(expr.X, args)
+		y, yOK := stringValue(expr.Y, args)
+		return x + y, xOK && yOK && expr.Op == token.ADD
+	}
+	return "", false
+}
+
+// fmtArgs returns args in a stable order, to tell calls apart.
+func fmtArgs(args map[string]string) string {
+	var pairs []string
+	for name, val := range args {
+		pairs = append(pairs, name+"="+val)
+	}
+	sort.Strings(pairs)
+	return "(" + strings.Join(pairs, ",") + ")"
+}
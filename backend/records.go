@@ -0,0 +1,289 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
+	"github.com/gidoBOSSftw5731/log"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+// addDNSRecord creates a record from a POST form, and replies with the
+// record as it was stored, including its ID. The record is owned by whoever
+// made it, unless an admin says otherwise.
+func addDNSRecord(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	// parse a POST form into a DNSRecord
+	dnsRecord, err := util.ParseDNSRecord(req)
+	if err != nil {
+		writeError(resp, bodyErrorStatus(err), err)
+		return
+	}
+	// IDs are always picked by the store
+	dnsRecord.Id = 0
+	if !id.Admin || dnsRecord.GetUser() == "" {
+		dnsRecord.User = id.User
+	}
+	err = auth.CanCreate(id, dnsRecord)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	err = applyChanges(req, id, []*pb.RecordChange{{After: dnsRecord}})
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	resp.Header().Set("ETag", etag(dnsRecord.GetRevision()))
+	writeProto(resp, http.StatusCreated, dnsRecord)
+}
+
+// getDNSRecord replies with the record with the ID in the id field, and its
+// revision as the ETag.
+func getDNSRecord(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	recordID, err := formID(req)
+	if err != nil {
+		writeError(resp, http.StatusBadRequest, err)
+		return
+	}
+	dnsRecord, err := store.Get(recordID)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	// tokens can't see past their scopes
+	if !id.InScope(dnsRecord) {
+		writeStoreError(resp, util.ErrRecordNotFound)
+		return
+	}
+	resp.Header().Set("ETag", etag(dnsRecord.GetRevision()))
+	writeProto(resp, http.StatusOK, dnsRecord)
+}
+
+// listDNSRecords replies with every record in the zone field, or only those
+// for the name field if it is set. The ETag is the serial of the zone.
+func listDNSRecords(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	zone := req.FormValue("zone")
+	if zone == "" {
+		writeError(resp, http.StatusBadRequest, fieldError("zone", "missing"))
+		return
+	}
+	// read first, so the records are at least as new as the ETag
+	serial, err := store.ZoneSerial(zone)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	records, err := store.List(zone, req.FormValue("name"))
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	resp.Header().Set("ETag", etag(serial))
+	writeProto(resp, http.StatusOK, &pb.DNSRecordList{Records: inScope(id, records)})
+}
+
+// inScope returns the records id can see.
+func inScope(id *util.Identity, records []*pb.DNSRecord) []*pb.DNSRecord {
+	var scoped []*pb.DNSRecord
+	for _, record := range records {
+		if id.InScope(record) {
+			scoped = append(scoped, record)
+		}
+	}
+	return scoped
+}
+
+// updateDNSRecord replaces the record with the ID in the id field with the
+// rest of the form, which takes the same fields as addDNSRecord. The owner
+// stays the same unless an admin changes it. If-Match is honoured, with the
+// record's revision as its ETag.
+func updateDNSRecord(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	dnsRecord, err := util.ParseDNSRecord(req)
+	if err != nil {
+		writeError(resp, bodyErrorStatus(err), err)
+		return
+	}
+	// JSON bodies can say which record they are instead
+	recordID := dnsRecord.GetId()
+	if req.FormValue("id") != "" || recordID == 0 {
+		recordID, err = formID(req)
+		if err != nil {
+			writeError(resp, http.StatusBadRequest, err)
+			return
+		}
+	}
+	old, err := store.Get(recordID)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	err = auth.CanModify(id, old)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	if !ifMatch(resp, req, old.GetRevision()) {
+		return
+	}
+	if !id.Admin || dnsRecord.GetUser() == "" {
+		dnsRecord.User = old.GetUser()
+	}
+	// records are only enabled again by their owner coming back
+	dnsRecord.Disabled = old.GetDisabled()
+	// it might be moving somewhere the user can't go
+	err = auth.CanCreate(id, dnsRecord)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	dnsRecord.Id = recordID
+	// this only happens if nobody else changed it since we read it
+	err = applyChanges(req, id, []*pb.RecordChange{{Before: old, After: dnsRecord}})
+	if err != nil {
+		writeChangeError(resp, req.Header.Get("If-Match") != "", err)
+		return
+	}
+	resp.Header().Set("ETag", etag(dnsRecord.GetRevision()))
+	writeProto(resp, http.StatusOK, dnsRecord)
+}
+
+// deleteDNSRecord removes the record with the ID in the id field. If-Match
+// is honoured like with updateDNSRecord.
+func deleteDNSRecord(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	recordID, err := formID(req)
+	if err != nil {
+		writeError(resp, http.StatusBadRequest, err)
+		return
+	}
+	old, err := store.Get(recordID)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	err = auth.CanModify(id, old)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	if !ifMatch(resp, req, old.GetRevision()) {
+		return
+	}
+	err = applyChanges(req, id, []*pb.RecordChange{{Before: old}})
+	if err != nil {
+		writeChangeError(resp, req.Header.Get("If-Match") != "", err)
+		return
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// quotaUsage replies with how much of their quota the caller has used.
+// Admins can ask about the user in the user field, though that doesn't take
+// their groups into account.
+func quotaUsage(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	who := id
+	if user := req.FormValue("user"); id.Admin && user != "" {
+		who = &util.Identity{User: user}
+	}
+	usage, err := quotas.Usage(who)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	writeProto(resp, http.StatusOK, usage)
+}
+
+// formID parses the id field of a request.
+func formID(req *http.Request) (uint64, error) {
+	id, err := strconv.ParseUint(req.FormValue("id"), 10, 64)
+	if err != nil {
+		return 0, fieldError("id", "must be a record ID")
+	}
+	return id, nil
+}
+
+// writeStoreError replies with the status matching an error from the store,
+// or from checking whether the caller may do what they asked.
+func writeStoreError(resp http.ResponseWriter, err error) {
+	writeChangeError(resp, false, err)
+}
+
+// errorStatus returns the HTTP status matching an error from the store, or
+// from checking the caller may do what they asked, which is 500 for anything
+// unexpected. Conflicts are 412 for conditional changes.
+func errorStatus(conditional bool, err error) int {
+	var quotaErr *util.QuotaError
+	var fieldErrs util.FieldErrors
+	switch {
+	case errors.As(err, &fieldErrs):
+		return http.StatusBadRequest
+	case errors.As(err, &quotaErr), errors.Is(err, util.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, util.ErrRecordNotFound), errors.Is(err, util.ErrTokenNotFound):
+		return http.StatusNotFound
+	case conditional && errors.Is(err, util.ErrConflict):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, util.ErrConflict), errors.Is(err, util.ErrDuplicateRecord):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// internalError logs err, which isn't the caller's fault, and returns what to
+// tell them instead.
+func internalError(err error) error {
+	// the details are for us, not whoever is asking
+	log.Errorln("Error accessing records:", err)
+	return errors.New("internal error")
+}
+
+// writeError replies with err as a pb.Error.
+func writeError(resp http.ResponseWriter, status int, err error) {
+	if status != http.StatusInternalServerError {
+		log.Errorln(err)
+	}
+	writeProto(resp, status, errorBody(status, err))
+}
+
+// bodyErrorStatus returns the HTTP status for an error reading the body of
+// a request, which is 413 if it was too large.
+func bodyErrorStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// errorBody returns err as a pb.Error, listing the invalid fields if it is a
+// util.FieldErrors.
+func errorBody(status int, err error) *pb.Error {
+	body := &pb.Error{Code: uint32(status), Message: err.Error()}
+	var fieldErrs util.FieldErrors
+	if errors.As(err, &fieldErrs) {
+		body.Fields = fieldErrs
+	}
+	return body
+}
+
+// fieldError returns an error saying field is invalid, for writeError.
+func fieldError(field, message string) error {
+	return util.FieldErrors{{Field: field, Message: message}}
+}
+
+// writeProto replies with msg as JSON.
+func writeProto(resp http.ResponseWriter, status int, msg proto.Message) {
+	out, err := protojson.Marshal(msg)
+	if err != nil {
+		log.Errorln("Error marshalling response:", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(status)
+	resp.Write(out)
+}
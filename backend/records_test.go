@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+func TestRecordETags(t *testing.T) {
+	testAPI(t)
+	form := "zone=valid.zone.&name=host&type=1&value=192.0.2.1&ttl=60"
+
+	resp := serve(apiRequest("alice", http.MethodPost, "/v1/addDNSRecord", form))
+	if resp.Code != http.StatusCreated || resp.Header().Get("ETag") != `"1"` {
+		t.Fatalf("adding: status, ETag = %d, %s: %s", resp.Code, resp.Header().Get("ETag"), resp.Body)
+	}
+	record := &pb.DNSRecord{}
+	readReply(t, resp, record)
+	byID := fmt.Sprintf("id=%d", record.GetId())
+
+	resp = serve(apiRequest("alice", http.MethodGet, "/v1/getDNSRecord?"+byID, ""))
+	if resp.Code != http.StatusOK || resp.Header().Get("ETag") != `"1"` {
+		t.Errorf("getting: status, ETag = %d, %s", resp.Code, resp.Header().Get("ETag"))
+	}
+	// the ETag of a list of records is the serial of their zone
+	resp = serve(apiRequest("alice", http.MethodGet, "/v1/listDNSRecords?zone=valid.zone.", ""))
+	if resp.Code != http.StatusOK || resp.Header().Get("ETag") != `"1"` {
+		t.Errorf("listing: status, ETag = %d, %s", resp.Code, resp.Header().Get("ETag"))
+	}
+
+	update := func(ifMatch string) *http.Request {
+		req := apiRequest("alice", http.MethodPost, "/v1/updateDNSRecord",
+			byID+"&zone=valid.zone.&name=host&type=1&value=192.0.2.2&ttl=60")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		return req
+	}
+	for _, tc := range []struct {
+		ifMatch string
+		status  int
+		etag    string
+	}{
+		// weak ETags never match, and the current one is sent back
+		{`"2"`, http.StatusPreconditionFailed, `"1"`},
+		{`W/"1"`, http.StatusPreconditionFailed, `"1"`},
+		{`"0", "1"`, http.StatusOK, `"2"`},
+		// nor do old ones once the record has changed
+		{`"1"`, http.StatusPreconditionFailed, `"2"`},
+		{"*", http.StatusOK, `"3"`},
+		// changes without If-Match aren't conditional
+		{"", http.StatusOK, `"4"`},
+	} {
+		resp := serve(update(tc.ifMatch))
+		if resp.Code != tc.status || resp.Header().Get("ETag") != tc.etag {
+			t.Errorf("updating with If-Match %s: status, ETag = %d, %s, want %d, %s: %s", tc.ifMatch,
+				resp.Code, resp.Header().Get("ETag"), tc.status, tc.etag, resp.Body)
+		}
+		if tc.status == http.StatusPreconditionFailed {
+			errBody := &pb.Error{}
+			readReply(t, resp, errBody)
+			if errBody.GetCode() != http.StatusPreconditionFailed {
+				t.Errorf("updating with If-Match %s: error = %v", tc.ifMatch, errBody)
+			}
+		}
+	}
+
+	deleteAt := func(ifMatch string) *http.Request {
+		req := apiRequest("alice", http.MethodPost, "/v1/deleteDNSRecord", byID)
+		req.Header.Set("If-Match", ifMatch)
+		return req
+	}
+	if resp := serve(deleteAt(`"3"`)); resp.Code != http.StatusPreconditionFailed {
+		t.Errorf("deleting at an old revision: status = %d, want 412", resp.Code)
+	}
+	if resp := serve(deleteAt(`"4"`)); resp.Code != http.StatusNoContent {
+		t.Errorf("deleting: status = %d, want 204: %s", resp.Code, resp.Body)
+	}
+	if resp := serve(apiRequest("alice", http.MethodGet, "/v1/getDNSRecord?"+byID, "")); resp.Code != http.StatusNotFound {
+		t.Errorf("getting a deleted record: status = %d, want 404", resp.Code)
+	}
+}
+
+func TestRecordTooLarge(t *testing.T) {
+	testAPI(t)
+	body := `{"name": "` + strings.Repeat("a", util.MaxRecordSize) + `", "zone": "valid.zone."}`
+	for _, route := range []string{"addDNSRecord", "updateDNSRecord"} {
+		resp := serve(apiRequest("alice", http.MethodPost, "/v1/"+route, body))
+		if resp.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: status = %d, want 413", route, resp.Code)
+			continue
+		}
+		errBody := &pb.Error{}
+		readReply(t, resp, errBody)
+		if errBody.GetCode() != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: error = %v", route, errBody)
+		}
+	}
+}
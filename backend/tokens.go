@@ -0,0 +1,157 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gidoBOSSftw5731/DeviceRegistrationSystem/util"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+// createToken makes a new API token for the caller, and replies with it. It
+// takes the fields:
+//
+//	description  what the token is for
+//	write        whether it can change records, rather than only read them
+//	patterns     comma separated globs of the full names it is limited to
+//	expires_in   how long it lasts, such as "720h", or forever if unset
+//	user         admins only: makes a service token acting as this user,
+//	             who mustn't be in the directory
+func createToken(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	// a token could otherwise be used to make one with more access
+	if !id.Unscoped() {
+		writeStoreError(resp, util.ErrForbidden)
+		return
+	}
+
+	info := &pb.APIToken{
+		User:        id.User,
+		Description: req.FormValue("description"),
+		Patterns:    req.FormValue("patterns"),
+	}
+	if user := req.FormValue("user"); user != "" && user != id.User {
+		if !id.Admin {
+			writeStoreError(resp, util.ErrForbidden)
+			return
+		}
+		if err := auth.CheckServiceUser(user); err != nil {
+			writeStoreError(resp, err)
+			return
+		}
+		info.User = user
+		info.Service = true
+	}
+	if write := req.FormValue("write"); write != "" {
+		var err error
+		info.Write, err = strconv.ParseBool(write)
+		if err != nil {
+			writeError(resp, http.StatusBadRequest, fieldError("write", "must be true or false"))
+			return
+		}
+	}
+	if expiresIn := req.FormValue("expires_in"); expiresIn != "" {
+		d, err := time.ParseDuration(expiresIn)
+		if err != nil || d <= 0 {
+			writeError(resp, http.StatusBadRequest, fieldError("expires_in", "must be a positive duration"))
+			return
+		}
+		info.ExpiresAt = time.Now().Add(d).Unix()
+	}
+
+	token, err := util.NewToken(info)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	err = store.Transaction(func(tx util.RecordStore) error {
+		if err := tx.CreateToken(info); err != nil {
+			return err
+		}
+		return audit(tx, req, id, util.AuditCreateToken, nil, withoutHash(info))
+	})
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	writeProto(resp, http.StatusCreated, &pb.NewAPIToken{Info: withoutHash(info), Token: token})
+}
+
+// listTokens replies with the caller's API tokens. Admins can ask for those of
+// the user in the user field, or everyone's if it isn't set.
+func listTokens(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	user := id.User
+	if id.Admin {
+		user = req.FormValue("user")
+	}
+	tokens, err := store.Tokens(user)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+
+	list := &pb.APITokenList{}
+	for _, token := range tokens {
+		list.Tokens = append(list.Tokens, withoutHash(token))
+	}
+	writeProto(resp, http.StatusOK, list)
+}
+
+// revokeToken revokes the API token with the ID in the id field. Users can
+// revoke their own tokens, and admins anyone's.
+func revokeToken(resp http.ResponseWriter, req *http.Request, id *util.Identity) {
+	if !id.Unscoped() {
+		writeStoreError(resp, util.ErrForbidden)
+		return
+	}
+	tokenID, err := formID(req)
+	if err != nil {
+		writeError(resp, http.StatusBadRequest, err)
+		return
+	}
+
+	// users can only find their own tokens
+	owner := id.User
+	if id.Admin {
+		owner = ""
+	}
+	tokens, err := store.Tokens(owner)
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	var old *pb.APIToken
+	for _, token := range tokens {
+		if token.GetId() == tokenID {
+			old = withoutHash(token)
+		}
+	}
+	if old == nil {
+		writeStoreError(resp, util.ErrTokenNotFound)
+		return
+	}
+
+	revoked := withoutHash(old)
+	revoked.RevokedAt = time.Now().Unix()
+	err = store.Transaction(func(tx util.RecordStore) error {
+		if err := tx.RevokeToken(tokenID, revoked.GetRevokedAt()); err != nil {
+			return err
+		}
+		return audit(tx, req, id, util.AuditRevokeToken, old, revoked)
+	})
+	if err != nil {
+		writeStoreError(resp, err)
+		return
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// withoutHash returns a copy of token without its hash, which there's no need
+// to hand out.
+func withoutHash(token *pb.APIToken) *pb.APIToken {
+	token = proto.Clone(token).(*pb.APIToken)
+	token.Hash = ""
+	return token
+}
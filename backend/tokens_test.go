@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+func TestTokenEndpoints(t *testing.T) {
+	testAPI(t)
+	// withToken returns a request to the API authenticated by token alone
+	withToken := func(token, method, target, body string) *http.Request {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if body != "" {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		return req
+	}
+
+	resp := serve(apiRequest("alice", http.MethodPost, "/v1/createToken", "description=laptop"))
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("creating a token: status = %d: %s", resp.Code, resp.Body)
+	}
+	readOnly := &pb.NewAPIToken{}
+	readReply(t, resp, readOnly)
+	if readOnly.GetToken() == "" || readOnly.GetInfo().GetHash() != "" || readOnly.GetInfo().GetUser() != "alice" {
+		t.Errorf("new token = %v", readOnly)
+	}
+
+	resp = serve(withToken(readOnly.GetToken(), http.MethodGet, "/v1/listTokens", ""))
+	list := &pb.APITokenList{}
+	readReply(t, resp, list)
+	if resp.Code != http.StatusOK || len(list.GetTokens()) != 1 || list.GetTokens()[0].GetHash() != "" {
+		t.Errorf("listing tokens with one: status = %d, tokens = %v", resp.Code, list)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{"writing with a read-only token", withToken(readOnly.GetToken(), http.MethodPost, "/v1/addDNSRecord",
+			"zone=valid.zone.&name=host&type=1&value=192.0.2.1&ttl=60"), http.StatusForbidden},
+		{"making a token with a token", withToken(readOnly.GetToken(), http.MethodPost, "/v1/createToken",
+			"write=true"), http.StatusForbidden},
+		{"a service token made by a user", apiRequest("alice", http.MethodPost, "/v1/createToken",
+			"user=backup"), http.StatusForbidden},
+		{"an invalid expiry", apiRequest("alice", http.MethodPost, "/v1/createToken",
+			"expires_in=soon"), http.StatusBadRequest},
+		{"revoking someone else's token", apiRequest("bob", http.MethodPost, "/v1/revokeToken",
+			fmt.Sprintf("id=%d", readOnly.GetInfo().GetId())), http.StatusNotFound},
+	} {
+		if resp := serve(tc.req); resp.Code != tc.status {
+			t.Errorf("%s: status = %d, want %d: %s", tc.name, resp.Code, tc.status, resp.Body)
+		}
+	}
+
+	resp = serve(apiRequest("alice", http.MethodPost, "/v1/revokeToken",
+		fmt.Sprintf("id=%d", readOnly.GetInfo().GetId())))
+	if resp.Code != http.StatusNoContent {
+		t.Errorf("revoking: status = %d: %s", resp.Code, resp.Body)
+	}
+	if resp := serve(withToken(readOnly.GetToken(), http.MethodGet, "/v1/listTokens", "")); resp.Code != http.StatusUnauthorized {
+		t.Errorf("using a revoked token: status = %d, want 401", resp.Code)
+	}
+}
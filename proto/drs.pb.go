@@ -30,6 +30,26 @@ type ServerConfig struct {
 	DBConf     *DatabaseConfig `protobuf:"bytes,1,opt,name=DB_conf,json=DBConf,proto3" json:"DB_conf,omitempty"`
 	DnsConf    *DNSConfig      `protobuf:"bytes,2,opt,name=dns_conf,json=dnsConf,proto3" json:"dns_conf,omitempty"`
 	ListenAddr string          `protobuf:"bytes,3,opt,name=listen_addr,json=listenAddr,proto3" json:"listen_addr,omitempty"`
+	// storage_backend is where records are kept: "postgres" (the default),
+	// "sqlite" or "memory". Memory loses everything on exit.
+	StorageBackend string       `protobuf:"bytes,4,opt,name=storage_backend,json=storageBackend,proto3" json:"storage_backend,omitempty"`
+	AuthConf       *AuthConfig  `protobuf:"bytes,5,opt,name=auth_conf,json=authConf,proto3" json:"auth_conf,omitempty"`
+	LdapConf       *LDAPConfig  `protobuf:"bytes,6,opt,name=ldap_conf,json=ldapConf,proto3" json:"ldap_conf,omitempty"`
+	QuotaConf      *QuotaConfig `protobuf:"bytes,7,opt,name=quota_conf,json=quotaConf,proto3" json:"quota_conf,omitempty"`
+	// grpc_listen_addr is where the DeviceRegistration gRPC service is
+	// served. Empty means it isn't. Callers send the same credentials as to
+	// the HTTP API, so without grpc_tls_cert_file and grpc_tls_key_file it
+	// should stay on loopback.
+	GrpcListenAddr string `protobuf:"bytes,8,opt,name=grpc_listen_addr,json=grpcListenAddr,proto3" json:"grpc_listen_addr,omitempty"`
+	// debug_listen_addr is where counters, such as how often rate limiting
+	// kicks in, are served at /debug/vars. They say more about the machine
+	// than anyone on the API should see, so it should stay on loopback.
+	// Empty means they aren't served.
+	DebugListenAddr string `protobuf:"bytes,9,opt,name=debug_listen_addr,json=debugListenAddr,proto3" json:"debug_listen_addr,omitempty"`
+	// The gRPC service is served over TLS if both the certificate and key are
+	// set, and in plaintext otherwise.
+	GrpcTlsCertFile string `protobuf:"bytes,10,opt,name=grpc_tls_cert_file,json=grpcTlsCertFile,proto3" json:"grpc_tls_cert_file,omitempty"`
+	GrpcTlsKeyFile  string `protobuf:"bytes,11,opt,name=grpc_tls_key_file,json=grpcTlsKeyFile,proto3" json:"grpc_tls_key_file,omitempty"`
 }
 
 func (x *ServerConfig) Reset() {
@@ -85,21 +105,77 @@ func (x *ServerConfig) GetListenAddr() string {
 	return ""
 }
 
-// DatabaseConfig is the configuration for the Postgres database.
-type DatabaseConfig struct {
+func (x *ServerConfig) GetStorageBackend() string {
+	if x != nil {
+		return x.StorageBackend
+	}
+	return ""
+}
+
+func (x *ServerConfig) GetAuthConf() *AuthConfig {
+	if x != nil {
+		return x.AuthConf
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetLdapConf() *LDAPConfig {
+	if x != nil {
+		return x.LdapConf
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetQuotaConf() *QuotaConfig {
+	if x != nil {
+		return x.QuotaConf
+	}
+	return nil
+}
+
+func (x *ServerConfig) GetGrpcListenAddr() string {
+	if x != nil {
+		return x.GrpcListenAddr
+	}
+	return ""
+}
+
+func (x *ServerConfig) GetDebugListenAddr() string {
+	if x != nil {
+		return x.DebugListenAddr
+	}
+	return ""
+}
+
+func (x *ServerConfig) GetGrpcTlsCertFile() string {
+	if x != nil {
+		return x.GrpcTlsCertFile
+	}
+	return ""
+}
+
+func (x *ServerConfig) GetGrpcTlsKeyFile() string {
+	if x != nil {
+		return x.GrpcTlsKeyFile
+	}
+	return ""
+}
+
+// Quota limits how much a user can register. 0 means no limit.
+type Quota struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Hostname is in format host:port.
-	Hostname     string `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
-	Username     string `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
-	Password     string `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
-	DatabaseName string `protobuf:"bytes,4,opt,name=database_name,json=databaseName,proto3" json:"database_name,omitempty"`
+	// devices are distinct names, in any zone.
+	MaxDevices uint32 `protobuf:"varint,1,opt,name=max_devices,json=maxDevices,proto3" json:"max_devices,omitempty"`
+	MaxRecords uint32 `protobuf:"varint,2,opt,name=max_records,json=maxRecords,proto3" json:"max_records,omitempty"`
+	// wildcards are records whose name starts with "*".
+	MaxWildcards uint32 `protobuf:"varint,3,opt,name=max_wildcards,json=maxWildcards,proto3" json:"max_wildcards,omitempty"`
 }
 
-func (x *DatabaseConfig) Reset() {
-	*x = DatabaseConfig{}
+func (x *Quota) Reset() {
+	*x = Quota{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_drs_proto_msgTypes[1]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -107,13 +183,13 @@ func (x *DatabaseConfig) Reset() {
 	}
 }
 
-func (x *DatabaseConfig) String() string {
+func (x *Quota) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DatabaseConfig) ProtoMessage() {}
+func (*Quota) ProtoMessage() {}
 
-func (x *DatabaseConfig) ProtoReflect() protoreflect.Message {
+func (x *Quota) ProtoReflect() protoreflect.Message {
 	mi := &file_drs_proto_msgTypes[1]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -125,57 +201,48 @@ func (x *DatabaseConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DatabaseConfig.ProtoReflect.Descriptor instead.
-func (*DatabaseConfig) Descriptor() ([]byte, []int) {
+// Deprecated: Use Quota.ProtoReflect.Descriptor instead.
+func (*Quota) Descriptor() ([]byte, []int) {
 	return file_drs_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *DatabaseConfig) GetHostname() string {
-	if x != nil {
-		return x.Hostname
-	}
-	return ""
-}
-
-func (x *DatabaseConfig) GetUsername() string {
+func (x *Quota) GetMaxDevices() uint32 {
 	if x != nil {
-		return x.Username
+		return x.MaxDevices
 	}
-	return ""
+	return 0
 }
 
-func (x *DatabaseConfig) GetPassword() string {
+func (x *Quota) GetMaxRecords() uint32 {
 	if x != nil {
-		return x.Password
+		return x.MaxRecords
 	}
-	return ""
+	return 0
 }
 
-func (x *DatabaseConfig) GetDatabaseName() string {
+func (x *Quota) GetMaxWildcards() uint32 {
 	if x != nil {
-		return x.DatabaseName
+		return x.MaxWildcards
 	}
-	return ""
+	return 0
 }
 
-type DNSConfig struct {
+// QuotaConfig is who gets which quota. A user's own quota wins over those of
+// their groups, and the most generous group quota wins over the default.
+// Admins have no quota.
+type QuotaConfig struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	RootZones []string `protobuf:"bytes,1,rep,name=root_zones,json=rootZones,proto3" json:"root_zones,omitempty"`
-	AxfrList  []string `protobuf:"bytes,2,rep,name=axfr_list,json=axfrList,proto3" json:"axfr_list,omitempty"`
-	// listen port can be set to :53 to run standalong, however this requires running
-	// the service as root. As such, the default is to bind to :5353.
-	// You MUST preface it with a ':'
-	ListenPort string   `protobuf:"bytes,3,opt,name=listen_port,json=listenPort,proto3" json:"listen_port,omitempty"`
-	NsAddr     string   `protobuf:"bytes,4,opt,name=ns_addr,json=nsAddr,proto3" json:"ns_addr,omitempty"`
-	AdminEmail string   `protobuf:"bytes,5,opt,name=admin_email,json=adminEmail,proto3" json:"admin_email,omitempty"`
-	AxfrTo     []string `protobuf:"bytes,6,rep,name=axfr_to,json=axfrTo,proto3" json:"axfr_to,omitempty"`
+	DefaultQuota *Quota            `protobuf:"bytes,1,opt,name=default_quota,json=defaultQuota,proto3" json:"default_quota,omitempty"`
+	Users        map[string]*Quota `protobuf:"bytes,2,rep,name=users,proto3" json:"users,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// groups are keyed by the DN of the LDAP group.
+	Groups map[string]*Quota `protobuf:"bytes,3,rep,name=groups,proto3" json:"groups,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
-func (x *DNSConfig) Reset() {
-	*x = DNSConfig{}
+func (x *QuotaConfig) Reset() {
+	*x = QuotaConfig{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_drs_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -183,13 +250,13 @@ func (x *DNSConfig) Reset() {
 	}
 }
 
-func (x *DNSConfig) String() string {
+func (x *QuotaConfig) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DNSConfig) ProtoMessage() {}
+func (*QuotaConfig) ProtoMessage() {}
 
-func (x *DNSConfig) ProtoReflect() protoreflect.Message {
+func (x *QuotaConfig) ProtoReflect() protoreflect.Message {
 	mi := &file_drs_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -201,87 +268,160 @@ func (x *DNSConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DNSConfig.ProtoReflect.Descriptor instead.
-func (*DNSConfig) Descriptor() ([]byte, []int) {
+// Deprecated: Use QuotaConfig.ProtoReflect.Descriptor instead.
+func (*QuotaConfig) Descriptor() ([]byte, []int) {
 	return file_drs_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *DNSConfig) GetRootZones() []string {
+func (x *QuotaConfig) GetDefaultQuota() *Quota {
 	if x != nil {
-		return x.RootZones
+		return x.DefaultQuota
 	}
 	return nil
 }
 
-func (x *DNSConfig) GetAxfrList() []string {
+func (x *QuotaConfig) GetUsers() map[string]*Quota {
 	if x != nil {
-		return x.AxfrList
+		return x.Users
 	}
 	return nil
 }
 
-func (x *DNSConfig) GetListenPort() string {
+func (x *QuotaConfig) GetGroups() map[string]*Quota {
 	if x != nil {
-		return x.ListenPort
+		return x.Groups
 	}
-	return ""
+	return nil
 }
 
-func (x *DNSConfig) GetNsAddr() string {
+// QuotaUsage is how much of their quota a user has used.
+type QuotaUsage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	User      string `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	Devices   uint32 `protobuf:"varint,2,opt,name=devices,proto3" json:"devices,omitempty"`
+	Records   uint32 `protobuf:"varint,3,opt,name=records,proto3" json:"records,omitempty"`
+	Wildcards uint32 `protobuf:"varint,4,opt,name=wildcards,proto3" json:"wildcards,omitempty"`
+	Limit     *Quota `protobuf:"bytes,5,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (x *QuotaUsage) Reset() {
+	*x = QuotaUsage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QuotaUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaUsage) ProtoMessage() {}
+
+func (x *QuotaUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaUsage.ProtoReflect.Descriptor instead.
+func (*QuotaUsage) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *QuotaUsage) GetUser() string {
 	if x != nil {
-		return x.NsAddr
+		return x.User
 	}
 	return ""
 }
 
-func (x *DNSConfig) GetAdminEmail() string {
+func (x *QuotaUsage) GetDevices() uint32 {
 	if x != nil {
-		return x.AdminEmail
+		return x.Devices
 	}
-	return ""
+	return 0
 }
 
-func (x *DNSConfig) GetAxfrTo() []string {
+func (x *QuotaUsage) GetRecords() uint32 {
 	if x != nil {
-		return x.AxfrTo
+		return x.Records
+	}
+	return 0
+}
+
+func (x *QuotaUsage) GetWildcards() uint32 {
+	if x != nil {
+		return x.Wildcards
+	}
+	return 0
+}
+
+func (x *QuotaUsage) GetLimit() *Quota {
+	if x != nil {
+		return x.Limit
 	}
 	return nil
 }
 
-type DNSRecord struct {
+// LDAPConfig is the directory users, and whether they are admins, are looked
+// up in. LDAP is off if url isn't set.
+type LDAPConfig struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	// type should be the same as the decimal ID for the record type.
-	Type uint32 `protobuf:"varint,2,opt,name=type,proto3" json:"type,omitempty"`
-	// value is optional for some records, but required for many.
-	Value string `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
-	Ttl   uint32 `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
-	Zone  string `protobuf:"bytes,5,opt,name=zone,proto3" json:"zone,omitempty"`
-	User  string `protobuf:"bytes,6,opt,name=user,proto3" json:"user,omitempty"`
-	// priority is set for MX records.
-	Priority string `protobuf:"bytes,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	// url is the server, such as "ldaps://ldap.example.com".
+	Url      string `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	StartTls bool   `protobuf:"varint,2,opt,name=start_tls,json=startTls,proto3" json:"start_tls,omitempty"`
+	// ca_file holds PEM certificates trusted to sign the server's certificate,
+	// on top of the system's, for ldaps:// and start_tls.
+	CaFile string `protobuf:"bytes,11,opt,name=ca_file,json=caFile,proto3" json:"ca_file,omitempty"`
+	// bind_dn and bind_password are the account used to look users up.
+	BindDn       string `protobuf:"bytes,3,opt,name=bind_dn,json=bindDn,proto3" json:"bind_dn,omitempty"`
+	BindPassword string `protobuf:"bytes,4,opt,name=bind_password,json=bindPassword,proto3" json:"bind_password,omitempty"`
+	// user_base_dn is where users are searched for, with user_filter, in
+	// which %s is replaced by the username.
+	UserBaseDn string `protobuf:"bytes,5,opt,name=user_base_dn,json=userBaseDn,proto3" json:"user_base_dn,omitempty"`
+	UserFilter string `protobuf:"bytes,6,opt,name=user_filter,json=userFilter,proto3" json:"user_filter,omitempty"`
+	// group_attr is the attribute of a user listing the DNs of their groups.
+	GroupAttr string `protobuf:"bytes,7,opt,name=group_attr,json=groupAttr,proto3" json:"group_attr,omitempty"`
+	// members of admin_group_dn are admins. If member_group_dn is set only its
+	// members, and admins, may use the API at all.
+	AdminGroupDn  string `protobuf:"bytes,8,opt,name=admin_group_dn,json=adminGroupDn,proto3" json:"admin_group_dn,omitempty"`
+	MemberGroupDn string `protobuf:"bytes,9,opt,name=member_group_dn,json=memberGroupDn,proto3" json:"member_group_dn,omitempty"`
+	// sync_interval is how often, in seconds, records owned by users who
+	// have left the directory are disabled. 0 turns it off.
+	SyncInterval uint32 `protobuf:"varint,10,opt,name=sync_interval,json=syncInterval,proto3" json:"sync_interval,omitempty"`
 }
 
-func (x *DNSRecord) Reset() {
-	*x = DNSRecord{}
+func (x *LDAPConfig) Reset() {
+	*x = LDAPConfig{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_drs_proto_msgTypes[3]
+		mi := &file_drs_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *DNSRecord) String() string {
+func (x *LDAPConfig) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DNSRecord) ProtoMessage() {}
+func (*LDAPConfig) ProtoMessage() {}
 
-func (x *DNSRecord) ProtoReflect() protoreflect.Message {
-	mi := &file_drs_proto_msgTypes[3]
+func (x *LDAPConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -292,157 +432,3075 @@ func (x *DNSRecord) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DNSRecord.ProtoReflect.Descriptor instead.
-func (*DNSRecord) Descriptor() ([]byte, []int) {
-	return file_drs_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use LDAPConfig.ProtoReflect.Descriptor instead.
+func (*LDAPConfig) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *DNSRecord) GetName() string {
+func (x *LDAPConfig) GetUrl() string {
 	if x != nil {
-		return x.Name
+		return x.Url
 	}
 	return ""
 }
 
-func (x *DNSRecord) GetType() uint32 {
+func (x *LDAPConfig) GetStartTls() bool {
 	if x != nil {
-		return x.Type
+		return x.StartTls
 	}
-	return 0
+	return false
 }
 
-func (x *DNSRecord) GetValue() string {
+func (x *LDAPConfig) GetCaFile() string {
 	if x != nil {
-		return x.Value
+		return x.CaFile
 	}
 	return ""
 }
 
-func (x *DNSRecord) GetTtl() uint32 {
+func (x *LDAPConfig) GetBindDn() string {
 	if x != nil {
-		return x.Ttl
+		return x.BindDn
 	}
-	return 0
+	return ""
 }
 
-func (x *DNSRecord) GetZone() string {
+func (x *LDAPConfig) GetBindPassword() string {
 	if x != nil {
-		return x.Zone
+		return x.BindPassword
 	}
 	return ""
 }
 
-func (x *DNSRecord) GetUser() string {
+func (x *LDAPConfig) GetUserBaseDn() string {
 	if x != nil {
-		return x.User
+		return x.UserBaseDn
 	}
 	return ""
 }
 
-func (x *DNSRecord) GetPriority() string {
+func (x *LDAPConfig) GetUserFilter() string {
 	if x != nil {
-		return x.Priority
+		return x.UserFilter
 	}
 	return ""
 }
 
-var File_drs_proto protoreflect.FileDescriptor
-
-var file_drs_proto_rawDesc = []byte{
-	0x0a, 0x09, 0x64, 0x72, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08, 0x61, 0x70, 0x69,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x92, 0x01, 0x0a, 0x0c, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72,
-	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x31, 0x0a, 0x07, 0x44, 0x42, 0x5f, 0x63, 0x6f, 0x6e,
-	0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69,
-	0x67, 0x52, 0x06, 0x44, 0x42, 0x43, 0x6f, 0x6e, 0x66, 0x12, 0x2e, 0x0a, 0x08, 0x64, 0x6e, 0x73,
-	0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70,
-	0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x4e, 0x53, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x52, 0x07, 0x64, 0x6e, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x69, 0x73,
-	0x74, 0x65, 0x6e, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
-	0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x22, 0x89, 0x01, 0x0a, 0x0e, 0x44,
-	0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x1a, 0x0a,
-	0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x08, 0x68, 0x6f, 0x73, 0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65,
-	0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65,
-	0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72,
-	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72,
-	0x64, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x6e, 0x61,
-	0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x61, 0x74, 0x61, 0x62, 0x61,
-	0x73, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0xbb, 0x01, 0x0a, 0x09, 0x44, 0x4e, 0x53, 0x43, 0x6f,
-	0x6e, 0x66, 0x69, 0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x7a, 0x6f, 0x6e,
-	0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x72, 0x6f, 0x6f, 0x74, 0x5a, 0x6f,
-	0x6e, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x61, 0x78, 0x66, 0x72, 0x5f, 0x6c, 0x69, 0x73, 0x74,
-	0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x61, 0x78, 0x66, 0x72, 0x4c, 0x69, 0x73, 0x74,
-	0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x50, 0x6f, 0x72,
-	0x74, 0x12, 0x17, 0x0a, 0x07, 0x6e, 0x73, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x06, 0x6e, 0x73, 0x41, 0x64, 0x64, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x64,
-	0x6d, 0x69, 0x6e, 0x5f, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0a, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x17, 0x0a, 0x07, 0x61,
-	0x78, 0x66, 0x72, 0x5f, 0x74, 0x6f, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x61, 0x78,
-	0x66, 0x72, 0x54, 0x6f, 0x22, 0x9f, 0x01, 0x0a, 0x09, 0x44, 0x4e, 0x53, 0x52, 0x65, 0x63, 0x6f,
-	0x72, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x74,
-	0x74, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x06,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72,
-	0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72,
-	0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x42, 0x09, 0x5a, 0x07, 0x2e, 0x3b, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *LDAPConfig) GetGroupAttr() string {
+	if x != nil {
+		return x.GroupAttr
+	}
+	return ""
 }
 
-var (
-	file_drs_proto_rawDescOnce sync.Once
-	file_drs_proto_rawDescData = file_drs_proto_rawDesc
-)
+func (x *LDAPConfig) GetAdminGroupDn() string {
+	if x != nil {
+		return x.AdminGroupDn
+	}
+	return ""
+}
 
-func file_drs_proto_rawDescGZIP() []byte {
-	file_drs_proto_rawDescOnce.Do(func() {
-		file_drs_proto_rawDescData = protoimpl.X.CompressGZIP(file_drs_proto_rawDescData)
-	})
-	return file_drs_proto_rawDescData
+func (x *LDAPConfig) GetMemberGroupDn() string {
+	if x != nil {
+		return x.MemberGroupDn
+	}
+	return ""
 }
 
-var file_drs_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
-var file_drs_proto_goTypes = []interface{}{
-	(*ServerConfig)(nil),   // 0: apiproto.ServerConfig
-	(*DatabaseConfig)(nil), // 1: apiproto.DatabaseConfig
-	(*DNSConfig)(nil),      // 2: apiproto.DNSConfig
-	(*DNSRecord)(nil),      // 3: apiproto.DNSRecord
+func (x *LDAPConfig) GetSyncInterval() uint32 {
+	if x != nil {
+		return x.SyncInterval
+	}
+	return 0
 }
-var file_drs_proto_depIdxs = []int32{
-	1, // 0: apiproto.ServerConfig.DB_conf:type_name -> apiproto.DatabaseConfig
-	2, // 1: apiproto.ServerConfig.dns_conf:type_name -> apiproto.DNSConfig
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+
+// AuthConfig is how callers of the HTTP API are identified.
+type AuthConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// proxy_user_header is the header a reverse proxy in front of the API
+	// puts the name of the user it authenticated in, such as
+	// X-Forwarded-User. Unset, the default, nobody is believed.
+	ProxyUserHeader string `protobuf:"bytes,1,opt,name=proxy_user_header,json=proxyUserHeader,proto3" json:"proxy_user_header,omitempty"`
+	// trusted_proxies are the prefixes, in CIDR notation, proxy_user_header
+	// is believed from. Anyone else could claim to be anybody.
+	TrustedProxies []string `protobuf:"bytes,2,rep,name=trusted_proxies,json=trustedProxies,proto3" json:"trusted_proxies,omitempty"`
+	// admin_users may change any record, and delegate zones to other users.
+	AdminUsers []string `protobuf:"bytes,3,rep,name=admin_users,json=adminUsers,proto3" json:"admin_users,omitempty"`
+	// oidc_issuer is the URL of an OpenID Connect provider whose ID tokens are
+	// accepted as bearer tokens. OIDC is off if it isn't set.
+	OidcIssuer string `protobuf:"bytes,4,opt,name=oidc_issuer,json=oidcIssuer,proto3" json:"oidc_issuer,omitempty"`
+	// oidc_audience is the client ID tokens must have been issued to.
+	OidcAudience string `protobuf:"bytes,5,opt,name=oidc_audience,json=oidcAudience,proto3" json:"oidc_audience,omitempty"`
+	// oidc_user_claim is the claim holding the name of the user, which
+	// becomes the owner of the records they make.
+	OidcUserClaim string `protobuf:"bytes,6,opt,name=oidc_user_claim,json=oidcUserClaim,proto3" json:"oidc_user_claim,omitempty"`
 }
 
-func init() { file_drs_proto_init() }
-func file_drs_proto_init() {
-	if File_drs_proto != nil {
-		return
+func (x *AuthConfig) Reset() {
+	*x = AuthConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_drs_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ServerConfig); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+}
+
+func (x *AuthConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthConfig) ProtoMessage() {}
+
+func (x *AuthConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_drs_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DatabaseConfig); i {
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthConfig.ProtoReflect.Descriptor instead.
+func (*AuthConfig) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AuthConfig) GetProxyUserHeader() string {
+	if x != nil {
+		return x.ProxyUserHeader
+	}
+	return ""
+}
+
+func (x *AuthConfig) GetTrustedProxies() []string {
+	if x != nil {
+		return x.TrustedProxies
+	}
+	return nil
+}
+
+func (x *AuthConfig) GetAdminUsers() []string {
+	if x != nil {
+		return x.AdminUsers
+	}
+	return nil
+}
+
+func (x *AuthConfig) GetOidcIssuer() string {
+	if x != nil {
+		return x.OidcIssuer
+	}
+	return ""
+}
+
+func (x *AuthConfig) GetOidcAudience() string {
+	if x != nil {
+		return x.OidcAudience
+	}
+	return ""
+}
+
+func (x *AuthConfig) GetOidcUserClaim() string {
+	if x != nil {
+		return x.OidcUserClaim
+	}
+	return ""
+}
+
+// DatabaseConfig is the configuration for the Postgres or SQLite database.
+type DatabaseConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Hostname is in format host:port.
+	Hostname     string `protobuf:"bytes,1,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Username     string `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Password     string `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`
+	DatabaseName string `protobuf:"bytes,4,opt,name=database_name,json=databaseName,proto3" json:"database_name,omitempty"`
+	// sqlite_path is the database file used by the sqlite storage backend.
+	SqlitePath string `protobuf:"bytes,5,opt,name=sqlite_path,json=sqlitePath,proto3" json:"sqlite_path,omitempty"`
+}
+
+func (x *DatabaseConfig) Reset() {
+	*x = DatabaseConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DatabaseConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DatabaseConfig) ProtoMessage() {}
+
+func (x *DatabaseConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DatabaseConfig.ProtoReflect.Descriptor instead.
+func (*DatabaseConfig) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DatabaseConfig) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *DatabaseConfig) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *DatabaseConfig) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *DatabaseConfig) GetDatabaseName() string {
+	if x != nil {
+		return x.DatabaseName
+	}
+	return ""
+}
+
+func (x *DatabaseConfig) GetSqlitePath() string {
+	if x != nil {
+		return x.SqlitePath
+	}
+	return ""
+}
+
+type DNSConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RootZones []string `protobuf:"bytes,1,rep,name=root_zones,json=rootZones,proto3" json:"root_zones,omitempty"`
+	AxfrList  []string `protobuf:"bytes,2,rep,name=axfr_list,json=axfrList,proto3" json:"axfr_list,omitempty"`
+	// listen port can be set to :53 to run standalong, however this requires running
+	// the service as root. As such, the default is to bind to :5353.
+	// You MUST preface it with a ':'
+	ListenPort string   `protobuf:"bytes,3,opt,name=listen_port,json=listenPort,proto3" json:"listen_port,omitempty"`
+	NsAddr     string   `protobuf:"bytes,4,opt,name=ns_addr,json=nsAddr,proto3" json:"ns_addr,omitempty"`
+	AdminEmail string   `protobuf:"bytes,5,opt,name=admin_email,json=adminEmail,proto3" json:"admin_email,omitempty"`
+	AxfrTo     []string `protobuf:"bytes,6,rep,name=axfr_to,json=axfrTo,proto3" json:"axfr_to,omitempty"`
+	// max_udp_size caps the EDNS0 buffer size we honor and advertise for UDP
+	// responses. Anything that doesn't fit is truncated with TC set.
+	// 1232 avoids IP fragmentation on almost every network.
+	MaxUdpSize uint32 `protobuf:"varint,7,opt,name=max_udp_size,json=maxUdpSize,proto3" json:"max_udp_size,omitempty"`
+	// DNS over TLS (RFC 7858) is only served if both the certificate and key
+	// are set. Like listen_port, tls_listen_port must be prefaced with a ':'.
+	TlsListenPort string `protobuf:"bytes,8,opt,name=tls_listen_port,json=tlsListenPort,proto3" json:"tls_listen_port,omitempty"`
+	TlsCertFile   string `protobuf:"bytes,9,opt,name=tls_cert_file,json=tlsCertFile,proto3" json:"tls_cert_file,omitempty"`
+	TlsKeyFile    string `protobuf:"bytes,10,opt,name=tls_key_file,json=tlsKeyFile,proto3" json:"tls_key_file,omitempty"`
+	// doh_listen_addr, if set, serves DNS over HTTPS (RFC 8484) on its own TLS
+	// listener. It needs tls_cert_file and tls_key_file just like DNS over TLS,
+	// and is not started without them, but otherwise doesn't depend on DNS over
+	// TLS being served. DoH is always available on the API server at
+	// /dns-query regardless, for use behind a TLS proxy.
+	DohListenAddr string `protobuf:"bytes,11,opt,name=doh_listen_addr,json=dohListenAddr,proto3" json:"doh_listen_addr,omitempty"`
+	// Clients with a source address in internal_prefixes are answered from the
+	// internal view, everyone else from the external view. Queries from
+	// trusted_forwarders are matched on their EDNS Client Subnet instead.
+	InternalPrefixes  []string   `protobuf:"bytes,12,rep,name=internal_prefixes,json=internalPrefixes,proto3" json:"internal_prefixes,omitempty"`
+	TrustedForwarders []string   `protobuf:"bytes,13,rep,name=trusted_forwarders,json=trustedForwarders,proto3" json:"trusted_forwarders,omitempty"`
+	Rrl               *RRLConfig `protobuf:"bytes,14,opt,name=rrl,proto3" json:"rrl,omitempty"`
+	// cookie_secret is the hex encoded key server cookies (RFC 7873) are made
+	// with. It must be the same on every instance behind an anycast address.
+	// If unset, a random one is made at startup.
+	CookieSecret string `protobuf:"bytes,15,opt,name=cookie_secret,json=cookieSecret,proto3" json:"cookie_secret,omitempty"`
+	// require_server_cookie makes UDP clients that send a client cookie, but no
+	// valid server cookie, get BADCOOKIE instead of an answer.
+	RequireServerCookie bool `protobuf:"varint,16,opt,name=require_server_cookie,json=requireServerCookie,proto3" json:"require_server_cookie,omitempty"`
+	// identity is returned for hostname.bind and id.server CHAOS queries, and
+	// defaults to the hostname of the machine. version is returned for
+	// version.bind and version.server. Either can be hidden, in which case
+	// those queries are refused.
+	Identity     string `protobuf:"bytes,17,opt,name=identity,proto3" json:"identity,omitempty"`
+	Version      string `protobuf:"bytes,18,opt,name=version,proto3" json:"version,omitempty"`
+	HideIdentity bool   `protobuf:"varint,19,opt,name=hide_identity,json=hideIdentity,proto3" json:"hide_identity,omitempty"`
+	HideVersion  bool   `protobuf:"varint,20,opt,name=hide_version,json=hideVersion,proto3" json:"hide_version,omitempty"`
+	// nsid is sent in the EDNS NSID option (RFC 5001) to clients that ask for
+	// it. It defaults to identity, in which case it isn't sent if hide_identity
+	// is set. An nsid which is set is always sent.
+	Nsid string `protobuf:"bytes,21,opt,name=nsid,proto3" json:"nsid,omitempty"`
+}
+
+func (x *DNSConfig) Reset() {
+	*x = DNSConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DNSConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSConfig) ProtoMessage() {}
+
+func (x *DNSConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSConfig.ProtoReflect.Descriptor instead.
+func (*DNSConfig) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DNSConfig) GetRootZones() []string {
+	if x != nil {
+		return x.RootZones
+	}
+	return nil
+}
+
+func (x *DNSConfig) GetAxfrList() []string {
+	if x != nil {
+		return x.AxfrList
+	}
+	return nil
+}
+
+func (x *DNSConfig) GetListenPort() string {
+	if x != nil {
+		return x.ListenPort
+	}
+	return ""
+}
+
+func (x *DNSConfig) GetNsAddr() string {
+	if x != nil {
+		return x.NsAddr
+	}
+	return ""
+}
+
+func (x *DNSConfig) GetAdminEmail() string {
+	if x != nil {
+		return x.AdminEmail
+	}
+	return ""
+}
+
+func (x *DNSConfig) GetAxfrTo() []string {
+	if x != nil {
+		return x.AxfrTo
+	}
+	return nil
+}
+
+func (x *DNSConfig) GetMaxUdpSize() uint32 {
+	if x != nil {
+		return x.MaxUdpSize
+	}
+	return 0
+}
+
+func (x *DNSConfig) GetTlsListenPort() string {
+	if x != nil {
+		return x.TlsListenPort
+	}
+	return ""
+}
+
+func (x *DNSConfig) GetTlsCertFile() string {
+	if x != nil {
+		return x.TlsCertFile
+	}
+	return ""
+}
+
+func (x *DNSConfig) GetTlsKeyFile() string {
+	if x != nil {
+		return x.TlsKeyFile
+	}
+	return ""
+}
+
+func (x *DNSConfig) GetDohListenAddr() string {
+	if x != nil {
+		return x.DohListenAddr
+	}
+	return ""
+}
+
+func (x *DNSConfig) GetInternalPrefixes() []string {
+	if x != nil {
+		return x.InternalPrefixes
+	}
+	return nil
+}
+
+func (x *DNSConfig) GetTrustedForwarders() []string {
+	if x != nil {
+		return x.TrustedForwarders
+	}
+	return nil
+}
+
+func (x *DNSConfig) GetRrl() *RRLConfig {
+	if x != nil {
+		return x.Rrl
+	}
+	return nil
+}
+
+func (x *DNSConfig) GetCookieSecret() string {
+	if x != nil {
+		return x.CookieSecret
+	}
+	return ""
+}
+
+func (x *DNSConfig) GetRequireServerCookie() bool {
+	if x != nil {
+		return x.RequireServerCookie
+	}
+	return false
+}
+
+func (x *DNSConfig) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *DNSConfig) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *DNSConfig) GetHideIdentity() bool {
+	if x != nil {
+		return x.HideIdentity
+	}
+	return false
+}
+
+func (x *DNSConfig) GetHideVersion() bool {
+	if x != nil {
+		return x.HideVersion
+	}
+	return false
+}
+
+func (x *DNSConfig) GetNsid() string {
+	if x != nil {
+		return x.Nsid
+	}
+	return ""
+}
+
+// RRLConfig configures BIND style response rate limiting of UDP replies.
+type RRLConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// responses_per_second is the number of answers with data each client
+	// prefix may receive. 0 turns rate limiting off altogether.
+	ResponsesPerSecond uint32 `protobuf:"varint,1,opt,name=responses_per_second,json=responsesPerSecond,proto3" json:"responses_per_second,omitempty"`
+	// the limits for other kinds of response default to responses_per_second.
+	NodataPerSecond    uint32 `protobuf:"varint,2,opt,name=nodata_per_second,json=nodataPerSecond,proto3" json:"nodata_per_second,omitempty"`
+	NxdomainsPerSecond uint32 `protobuf:"varint,3,opt,name=nxdomains_per_second,json=nxdomainsPerSecond,proto3" json:"nxdomains_per_second,omitempty"`
+	ErrorsPerSecond    uint32 `protobuf:"varint,4,opt,name=errors_per_second,json=errorsPerSecond,proto3" json:"errors_per_second,omitempty"`
+	// window is how many seconds of excess a client prefix has to pay back
+	// before it is answered again.
+	Window uint32 `protobuf:"varint,5,opt,name=window,proto3" json:"window,omitempty"`
+	// Every slip'th limited response is sent as an empty reply with TC set so
+	// real clients can retry over TCP. 0 drops every limited response.
+	Slip             uint32 `protobuf:"varint,6,opt,name=slip,proto3" json:"slip,omitempty"`
+	Ipv4PrefixLength uint32 `protobuf:"varint,7,opt,name=ipv4_prefix_length,json=ipv4PrefixLength,proto3" json:"ipv4_prefix_length,omitempty"`
+	Ipv6PrefixLength uint32 `protobuf:"varint,8,opt,name=ipv6_prefix_length,json=ipv6PrefixLength,proto3" json:"ipv6_prefix_length,omitempty"`
+	// exempt_prefixes are never rate limited.
+	ExemptPrefixes []string `protobuf:"bytes,9,rep,name=exempt_prefixes,json=exemptPrefixes,proto3" json:"exempt_prefixes,omitempty"`
+}
+
+func (x *RRLConfig) Reset() {
+	*x = RRLConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RRLConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RRLConfig) ProtoMessage() {}
+
+func (x *RRLConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RRLConfig.ProtoReflect.Descriptor instead.
+func (*RRLConfig) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RRLConfig) GetResponsesPerSecond() uint32 {
+	if x != nil {
+		return x.ResponsesPerSecond
+	}
+	return 0
+}
+
+func (x *RRLConfig) GetNodataPerSecond() uint32 {
+	if x != nil {
+		return x.NodataPerSecond
+	}
+	return 0
+}
+
+func (x *RRLConfig) GetNxdomainsPerSecond() uint32 {
+	if x != nil {
+		return x.NxdomainsPerSecond
+	}
+	return 0
+}
+
+func (x *RRLConfig) GetErrorsPerSecond() uint32 {
+	if x != nil {
+		return x.ErrorsPerSecond
+	}
+	return 0
+}
+
+func (x *RRLConfig) GetWindow() uint32 {
+	if x != nil {
+		return x.Window
+	}
+	return 0
+}
+
+func (x *RRLConfig) GetSlip() uint32 {
+	if x != nil {
+		return x.Slip
+	}
+	return 0
+}
+
+func (x *RRLConfig) GetIpv4PrefixLength() uint32 {
+	if x != nil {
+		return x.Ipv4PrefixLength
+	}
+	return 0
+}
+
+func (x *RRLConfig) GetIpv6PrefixLength() uint32 {
+	if x != nil {
+		return x.Ipv6PrefixLength
+	}
+	return 0
+}
+
+func (x *RRLConfig) GetExemptPrefixes() []string {
+	if x != nil {
+		return x.ExemptPrefixes
+	}
+	return nil
+}
+
+type DNSRecord struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// type should be the same as the decimal ID for the record type.
+	Type uint32 `protobuf:"varint,2,opt,name=type,proto3" json:"type,omitempty"`
+	// value is optional for some records, but required for many.
+	Value string `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Ttl   uint32 `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Zone  string `protobuf:"bytes,5,opt,name=zone,proto3" json:"zone,omitempty"`
+	// user owns the record. It is whoever created it, unless an admin says
+	// otherwise.
+	User string `protobuf:"bytes,6,opt,name=user,proto3" json:"user,omitempty"`
+	// priority is set for MX records.
+	Priority string `protobuf:"bytes,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	// view limits who can see the record, either "internal" or "external".
+	// Records without a view are visible to everyone.
+	View string `protobuf:"bytes,8,opt,name=view,proto3" json:"view,omitempty"`
+	// id is assigned by the server when the record is created, and is how a
+	// record is referred to from then on.
+	Id uint64 `protobuf:"varint,9,opt,name=id,proto3" json:"id,omitempty"`
+	// disabled records aren't served. Records are disabled when their owner
+	// leaves the LDAP directory.
+	Disabled bool `protobuf:"varint,10,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	// revision goes up by one every time the record changes. It is the
+	// record's ETag in the API.
+	Revision uint64 `protobuf:"varint,11,opt,name=revision,proto3" json:"revision,omitempty"`
+}
+
+func (x *DNSRecord) Reset() {
+	*x = DNSRecord{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DNSRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSRecord) ProtoMessage() {}
+
+func (x *DNSRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSRecord.ProtoReflect.Descriptor instead.
+func (*DNSRecord) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DNSRecord) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DNSRecord) GetType() uint32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *DNSRecord) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *DNSRecord) GetTtl() uint32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *DNSRecord) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *DNSRecord) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *DNSRecord) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *DNSRecord) GetView() string {
+	if x != nil {
+		return x.View
+	}
+	return ""
+}
+
+func (x *DNSRecord) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DNSRecord) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *DNSRecord) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+// ZoneDelegation lets a user change every record in a zone, not just the
+// ones they own.
+type ZoneDelegation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Zone string `protobuf:"bytes,2,opt,name=zone,proto3" json:"zone,omitempty"`
+	User string `protobuf:"bytes,3,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (x *ZoneDelegation) Reset() {
+	*x = ZoneDelegation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ZoneDelegation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZoneDelegation) ProtoMessage() {}
+
+func (x *ZoneDelegation) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZoneDelegation.ProtoReflect.Descriptor instead.
+func (*ZoneDelegation) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ZoneDelegation) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ZoneDelegation) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *ZoneDelegation) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+type ZoneDelegationList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Delegations []*ZoneDelegation `protobuf:"bytes,1,rep,name=delegations,proto3" json:"delegations,omitempty"`
+}
+
+func (x *ZoneDelegationList) Reset() {
+	*x = ZoneDelegationList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ZoneDelegationList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZoneDelegationList) ProtoMessage() {}
+
+func (x *ZoneDelegationList) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZoneDelegationList.ProtoReflect.Descriptor instead.
+func (*ZoneDelegationList) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ZoneDelegationList) GetDelegations() []*ZoneDelegation {
+	if x != nil {
+		return x.Delegations
+	}
+	return nil
+}
+
+// APIToken lets a person or a service use the API without a login. Only a
+// hash of the token is kept.
+type APIToken struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// user is who the token acts as.
+	User string `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	// service tokens are made by admins for things which aren't people.
+	Service     bool   `protobuf:"varint,3,opt,name=service,proto3" json:"service,omitempty"`
+	Description string `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	// hash is the hex SHA-256 of the token, which is never stored.
+	Hash string `protobuf:"bytes,5,opt,name=hash,proto3" json:"hash,omitempty"`
+	// write lets the token change records, otherwise it can only read them.
+	Write bool `protobuf:"varint,6,opt,name=write,proto3" json:"write,omitempty"`
+	// patterns limits the token to records whose full names match one of
+	// these comma separated globs, such as "*.lab.example.com.". Empty
+	// means every record the user can get to.
+	Patterns string `protobuf:"bytes,7,opt,name=patterns,proto3" json:"patterns,omitempty"`
+	// created_at, expires_at and revoked_at are unix timestamps. 0 means the
+	// token never expires, or hasn't been revoked.
+	CreatedAt int64 `protobuf:"varint,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt int64 `protobuf:"varint,9,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	RevokedAt int64 `protobuf:"varint,10,opt,name=revoked_at,json=revokedAt,proto3" json:"revoked_at,omitempty"`
+}
+
+func (x *APIToken) Reset() {
+	*x = APIToken{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *APIToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APIToken) ProtoMessage() {}
+
+func (x *APIToken) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APIToken.ProtoReflect.Descriptor instead.
+func (*APIToken) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *APIToken) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *APIToken) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *APIToken) GetService() bool {
+	if x != nil {
+		return x.Service
+	}
+	return false
+}
+
+func (x *APIToken) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *APIToken) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *APIToken) GetWrite() bool {
+	if x != nil {
+		return x.Write
+	}
+	return false
+}
+
+func (x *APIToken) GetPatterns() string {
+	if x != nil {
+		return x.Patterns
+	}
+	return ""
+}
+
+func (x *APIToken) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *APIToken) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *APIToken) GetRevokedAt() int64 {
+	if x != nil {
+		return x.RevokedAt
+	}
+	return 0
+}
+
+// NewAPIToken is the reply to making a token, and the only time the token
+// itself is seen.
+type NewAPIToken struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Info  *APIToken `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	Token string    `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+}
+
+func (x *NewAPIToken) Reset() {
+	*x = NewAPIToken{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *NewAPIToken) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewAPIToken) ProtoMessage() {}
+
+func (x *NewAPIToken) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewAPIToken.ProtoReflect.Descriptor instead.
+func (*NewAPIToken) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *NewAPIToken) GetInfo() *APIToken {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *NewAPIToken) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type APITokenList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tokens []*APIToken `protobuf:"bytes,1,rep,name=tokens,proto3" json:"tokens,omitempty"`
+}
+
+func (x *APITokenList) Reset() {
+	*x = APITokenList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *APITokenList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*APITokenList) ProtoMessage() {}
+
+func (x *APITokenList) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use APITokenList.ProtoReflect.Descriptor instead.
+func (*APITokenList) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *APITokenList) GetTokens() []*APIToken {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+// AuditEntry records a single change made through the API. Entries are
+// never changed or deleted.
+type AuditEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// time is a unix timestamp.
+	Time int64 `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`
+	// actor is the user who made the change.
+	Actor    string `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	SourceIp string `protobuf:"bytes,4,opt,name=source_ip,json=sourceIp,proto3" json:"source_ip,omitempty"`
+	// action is what was done, such as "create_record".
+	Action string `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"`
+	// zone and name are what was changed, if it was a record or zone.
+	Zone string `protobuf:"bytes,6,opt,name=zone,proto3" json:"zone,omitempty"`
+	Name string `protobuf:"bytes,7,opt,name=name,proto3" json:"name,omitempty"`
+	// before and after are the changed thing, as JSON, if it existed.
+	Before string `protobuf:"bytes,8,opt,name=before,proto3" json:"before,omitempty"`
+	After  string `protobuf:"bytes,9,opt,name=after,proto3" json:"after,omitempty"`
+}
+
+func (x *AuditEntry) Reset() {
+	*x = AuditEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuditEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEntry) ProtoMessage() {}
+
+func (x *AuditEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEntry.ProtoReflect.Descriptor instead.
+func (*AuditEntry) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *AuditEntry) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *AuditEntry) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *AuditEntry) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetSourceIp() string {
+	if x != nil {
+		return x.SourceIp
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *AuditEntry) GetAfter() string {
+	if x != nil {
+		return x.After
+	}
+	return ""
+}
+
+type AuditEntryList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Entries []*AuditEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+}
+
+func (x *AuditEntryList) Reset() {
+	*x = AuditEntryList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuditEntryList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEntryList) ProtoMessage() {}
+
+func (x *AuditEntryList) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEntryList.ProtoReflect.Descriptor instead.
+func (*AuditEntryList) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AuditEntryList) GetEntries() []*AuditEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type DNSRecordList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Records []*DNSRecord `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+}
+
+func (x *DNSRecordList) Reset() {
+	*x = DNSRecordList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DNSRecordList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSRecordList) ProtoMessage() {}
+
+func (x *DNSRecordList) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSRecordList.ProtoReflect.Descriptor instead.
+func (*DNSRecordList) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *DNSRecordList) GetRecords() []*DNSRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+// RecordVersion is what a record looked like after a change to its zone.
+// Versions are written along with every change, so a zone can be shown as it
+// was at any point, and rolled back to it.
+type RecordVersion struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// zone is lower cased, with a trailing dot.
+	Zone string `protobuf:"bytes,2,opt,name=zone,proto3" json:"zone,omitempty"`
+	// serial is the serial of the zone after the change.
+	Serial uint64 `protobuf:"varint,3,opt,name=serial,proto3" json:"serial,omitempty"`
+	// time is a unix timestamp.
+	Time     int64  `protobuf:"varint,4,opt,name=time,proto3" json:"time,omitempty"`
+	RecordId uint64 `protobuf:"varint,5,opt,name=record_id,json=recordId,proto3" json:"record_id,omitempty"`
+	// deleted is set when the record was deleted, or moved to another zone.
+	Deleted bool `protobuf:"varint,6,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	// the rest are the fields of the record.
+	Name     string `protobuf:"bytes,7,opt,name=name,proto3" json:"name,omitempty"`
+	Type     uint32 `protobuf:"varint,8,opt,name=type,proto3" json:"type,omitempty"`
+	Value    string `protobuf:"bytes,9,opt,name=value,proto3" json:"value,omitempty"`
+	Ttl      uint32 `protobuf:"varint,10,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	User     string `protobuf:"bytes,11,opt,name=user,proto3" json:"user,omitempty"`
+	Priority string `protobuf:"bytes,12,opt,name=priority,proto3" json:"priority,omitempty"`
+	View     string `protobuf:"bytes,13,opt,name=view,proto3" json:"view,omitempty"`
+	Disabled bool   `protobuf:"varint,14,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	Revision uint64 `protobuf:"varint,15,opt,name=revision,proto3" json:"revision,omitempty"`
+}
+
+func (x *RecordVersion) Reset() {
+	*x = RecordVersion{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordVersion) ProtoMessage() {}
+
+func (x *RecordVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordVersion.ProtoReflect.Descriptor instead.
+func (*RecordVersion) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RecordVersion) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RecordVersion) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *RecordVersion) GetSerial() uint64 {
+	if x != nil {
+		return x.Serial
+	}
+	return 0
+}
+
+func (x *RecordVersion) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *RecordVersion) GetRecordId() uint64 {
+	if x != nil {
+		return x.RecordId
+	}
+	return 0
+}
+
+func (x *RecordVersion) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *RecordVersion) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RecordVersion) GetType() uint32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *RecordVersion) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *RecordVersion) GetTtl() uint32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *RecordVersion) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+func (x *RecordVersion) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *RecordVersion) GetView() string {
+	if x != nil {
+		return x.View
+	}
+	return ""
+}
+
+func (x *RecordVersion) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *RecordVersion) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+type RecordVersionList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Versions []*RecordVersion `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"`
+}
+
+func (x *RecordVersionList) Reset() {
+	*x = RecordVersionList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordVersionList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordVersionList) ProtoMessage() {}
+
+func (x *RecordVersionList) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordVersionList.ProtoReflect.Descriptor instead.
+func (*RecordVersionList) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RecordVersionList) GetVersions() []*RecordVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+// ZoneSerial counts the changes made to a zone. A change made in one go,
+// like a rollback, counts once however many records it touches.
+type ZoneSerial struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// zone is lower cased, with a trailing dot.
+	Zone   string `protobuf:"bytes,1,opt,name=zone,proto3" json:"zone,omitempty"`
+	Serial uint64 `protobuf:"varint,2,opt,name=serial,proto3" json:"serial,omitempty"`
+}
+
+func (x *ZoneSerial) Reset() {
+	*x = ZoneSerial{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ZoneSerial) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZoneSerial) ProtoMessage() {}
+
+func (x *ZoneSerial) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZoneSerial.ProtoReflect.Descriptor instead.
+func (*ZoneSerial) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ZoneSerial) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *ZoneSerial) GetSerial() uint64 {
+	if x != nil {
+		return x.Serial
+	}
+	return 0
+}
+
+// ZoneSnapshot is the records of a zone as they were at a serial.
+type ZoneSnapshot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Zone    string       `protobuf:"bytes,1,opt,name=zone,proto3" json:"zone,omitempty"`
+	Serial  uint64       `protobuf:"varint,2,opt,name=serial,proto3" json:"serial,omitempty"`
+	Records []*DNSRecord `protobuf:"bytes,3,rep,name=records,proto3" json:"records,omitempty"`
+}
+
+func (x *ZoneSnapshot) Reset() {
+	*x = ZoneSnapshot{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ZoneSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZoneSnapshot) ProtoMessage() {}
+
+func (x *ZoneSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZoneSnapshot.ProtoReflect.Descriptor instead.
+func (*ZoneSnapshot) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ZoneSnapshot) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *ZoneSnapshot) GetSerial() uint64 {
+	if x != nil {
+		return x.Serial
+	}
+	return 0
+}
+
+func (x *ZoneSnapshot) GetRecords() []*DNSRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+// RecordChange is a change to one record. before is unset for new records,
+// and after for deleted ones.
+type RecordChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Before *DNSRecord `protobuf:"bytes,1,opt,name=before,proto3" json:"before,omitempty"`
+	After  *DNSRecord `protobuf:"bytes,2,opt,name=after,proto3" json:"after,omitempty"`
+}
+
+func (x *RecordChange) Reset() {
+	*x = RecordChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordChange) ProtoMessage() {}
+
+func (x *RecordChange) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordChange.ProtoReflect.Descriptor instead.
+func (*RecordChange) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RecordChange) GetBefore() *DNSRecord {
+	if x != nil {
+		return x.Before
+	}
+	return nil
+}
+
+func (x *RecordChange) GetAfter() *DNSRecord {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+type RecordChangeList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Changes []*RecordChange `protobuf:"bytes,1,rep,name=changes,proto3" json:"changes,omitempty"`
+}
+
+func (x *RecordChangeList) Reset() {
+	*x = RecordChangeList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordChangeList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordChangeList) ProtoMessage() {}
+
+func (x *RecordChangeList) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordChangeList.ProtoReflect.Descriptor instead.
+func (*RecordChangeList) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RecordChangeList) GetChanges() []*RecordChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+// RecordOperation is one change in a RecordBatch.
+type RecordOperation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// op is "add", "delete" or "replace".
+	Op string `protobuf:"bytes,1,opt,name=op,proto3" json:"op,omitempty"`
+	// id is the record being deleted or replaced.
+	Id uint64 `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	// record is the record being added, or what the record is replaced with.
+	Record *DNSRecord `protobuf:"bytes,3,opt,name=record,proto3" json:"record,omitempty"`
+	// revision, if set, is the revision the record being deleted or replaced
+	// must still be at, like If-Match.
+	Revision uint64 `protobuf:"varint,4,opt,name=revision,proto3" json:"revision,omitempty"`
+}
+
+func (x *RecordOperation) Reset() {
+	*x = RecordOperation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordOperation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordOperation) ProtoMessage() {}
+
+func (x *RecordOperation) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordOperation.ProtoReflect.Descriptor instead.
+func (*RecordOperation) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RecordOperation) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
+func (x *RecordOperation) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RecordOperation) GetRecord() *DNSRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+func (x *RecordOperation) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+// RecordBatch is a list of changes to records which are made all at once, or
+// not at all.
+type RecordBatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Operations []*RecordOperation `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
+}
+
+func (x *RecordBatch) Reset() {
+	*x = RecordBatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordBatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordBatch) ProtoMessage() {}
+
+func (x *RecordBatch) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordBatch.ProtoReflect.Descriptor instead.
+func (*RecordBatch) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RecordBatch) GetOperations() []*RecordOperation {
+	if x != nil {
+		return x.Operations
+	}
+	return nil
+}
+
+// FieldError says what is wrong with one field of a request.
+type FieldError struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Field   string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *FieldError) Reset() {
+	*x = FieldError{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FieldError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldError) ProtoMessage() {}
+
+func (x *FieldError) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldError.ProtoReflect.Descriptor instead.
+func (*FieldError) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *FieldError) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Error is the body of every error the API replies with.
+type Error struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// code is the HTTP status code.
+	Code    uint32 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// fields lists every invalid field of the request, if that is what was
+	// wrong with it.
+	Fields []*FieldError `protobuf:"bytes,3,rep,name=fields,proto3" json:"fields,omitempty"`
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *Error) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Error) GetFields() []*FieldError {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+// RecordRef picks out a record by ID.
+type RecordRef struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// revision, if set, is the revision the record must still be at to be
+	// deleted, like If-Match.
+	Revision uint64 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+}
+
+func (x *RecordRef) Reset() {
+	*x = RecordRef{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordRef) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordRef) ProtoMessage() {}
+
+func (x *RecordRef) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordRef.ProtoReflect.Descriptor instead.
+func (*RecordRef) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RecordRef) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RecordRef) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+// RecordQuery picks out the records in a zone, or only those for a name.
+type RecordQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Zone string `protobuf:"bytes,1,opt,name=zone,proto3" json:"zone,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *RecordQuery) Reset() {
+	*x = RecordQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordQuery) ProtoMessage() {}
+
+func (x *RecordQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordQuery.ProtoReflect.Descriptor instead.
+func (*RecordQuery) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RecordQuery) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *RecordQuery) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// ZoneQuery picks out a zone as it was at a serial or unix time, or now if
+// neither is set.
+type ZoneQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Zone   string `protobuf:"bytes,1,opt,name=zone,proto3" json:"zone,omitempty"`
+	Serial uint64 `protobuf:"varint,2,opt,name=serial,proto3" json:"serial,omitempty"`
+	Time   int64  `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"`
+	// if_serial, if set, is the serial the zone must still be at to be
+	// rolled back, like If-Match.
+	IfSerial uint64 `protobuf:"varint,4,opt,name=if_serial,json=ifSerial,proto3" json:"if_serial,omitempty"`
+}
+
+func (x *ZoneQuery) Reset() {
+	*x = ZoneQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ZoneQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ZoneQuery) ProtoMessage() {}
+
+func (x *ZoneQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ZoneQuery.ProtoReflect.Descriptor instead.
+func (*ZoneQuery) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ZoneQuery) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *ZoneQuery) GetSerial() uint64 {
+	if x != nil {
+		return x.Serial
+	}
+	return 0
+}
+
+func (x *ZoneQuery) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *ZoneQuery) GetIfSerial() uint64 {
+	if x != nil {
+		return x.IfSerial
+	}
+	return 0
+}
+
+// Device is every record for a name in a zone.
+type Device struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Zone    string       `protobuf:"bytes,1,opt,name=zone,proto3" json:"zone,omitempty"`
+	Name    string       `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Records []*DNSRecord `protobuf:"bytes,3,rep,name=records,proto3" json:"records,omitempty"`
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Device) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *Device) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Device) GetRecords() []*DNSRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type DeviceList struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Devices []*Device `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+}
+
+func (x *DeviceList) Reset() {
+	*x = DeviceList{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeviceList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceList) ProtoMessage() {}
+
+func (x *DeviceList) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceList.ProtoReflect.Descriptor instead.
+func (*DeviceList) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DeviceList) GetDevices() []*Device {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+// DeviceQuery picks out the devices in zone, or if it isn't set those owned
+// by user. Only admins can ask about someone other than themselves.
+type DeviceQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Zone string `protobuf:"bytes,1,opt,name=zone,proto3" json:"zone,omitempty"`
+	User string `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (x *DeviceQuery) Reset() {
+	*x = DeviceQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeviceQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceQuery) ProtoMessage() {}
+
+func (x *DeviceQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceQuery.ProtoReflect.Descriptor instead.
+func (*DeviceQuery) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *DeviceQuery) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *DeviceQuery) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+// WatchRequest asks for the changes made to a zone after serial. 0 starts
+// from the beginning of its history.
+type WatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Zone   string `protobuf:"bytes,1,opt,name=zone,proto3" json:"zone,omitempty"`
+	Serial uint64 `protobuf:"varint,2,opt,name=serial,proto3" json:"serial,omitempty"`
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drs_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_drs_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_drs_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *WatchRequest) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *WatchRequest) GetSerial() uint64 {
+	if x != nil {
+		return x.Serial
+	}
+	return 0
+}
+
+var File_drs_proto protoreflect.FileDescriptor
+
+var file_drs_proto_rawDesc = []byte{
+	0x0a, 0x09, 0x64, 0x72, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08, 0x61, 0x70, 0x69,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x85, 0x04, 0x0a, 0x0c, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x31, 0x0a, 0x07, 0x44, 0x42, 0x5f, 0x63, 0x6f, 0x6e,
+	0x66, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x44, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x52, 0x06, 0x44, 0x42, 0x43, 0x6f, 0x6e, 0x66, 0x12, 0x2e, 0x0a, 0x08, 0x64, 0x6e, 0x73,
+	0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70,
+	0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x4e, 0x53, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x52, 0x07, 0x64, 0x6e, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x12, 0x1f, 0x0a, 0x0b, 0x6c, 0x69, 0x73,
+	0x74, 0x65, 0x6e, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x74,
+	0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x42, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x12, 0x31, 0x0a, 0x09, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x63, 0x6f, 0x6e, 0x66,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x08, 0x61, 0x75,
+	0x74, 0x68, 0x43, 0x6f, 0x6e, 0x66, 0x12, 0x31, 0x0a, 0x09, 0x6c, 0x64, 0x61, 0x70, 0x5f, 0x63,
+	0x6f, 0x6e, 0x66, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x69, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c, 0x44, 0x41, 0x50, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52,
+	0x08, 0x6c, 0x64, 0x61, 0x70, 0x43, 0x6f, 0x6e, 0x66, 0x12, 0x34, 0x0a, 0x0a, 0x71, 0x75, 0x6f,
+	0x74, 0x61, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
+	0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x52, 0x09, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x43, 0x6f, 0x6e, 0x66, 0x12,
+	0x28, 0x0a, 0x10, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x5f, 0x61,
+	0x64, 0x64, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x67, 0x72, 0x70, 0x63, 0x4c,
+	0x69, 0x73, 0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x12, 0x2a, 0x0a, 0x11, 0x64, 0x65, 0x62,
+	0x75, 0x67, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x64, 0x65, 0x62, 0x75, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x65,
+	0x6e, 0x41, 0x64, 0x64, 0x72, 0x12, 0x2b, 0x0a, 0x12, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x74, 0x6c,
+	0x73, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0f, 0x67, 0x72, 0x70, 0x63, 0x54, 0x6c, 0x73, 0x43, 0x65, 0x72, 0x74, 0x46, 0x69,
+	0x6c, 0x65, 0x12, 0x29, 0x0a, 0x11, 0x67, 0x72, 0x70, 0x63, 0x5f, 0x74, 0x6c, 0x73, 0x5f, 0x6b,
+	0x65, 0x79, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x67,
+	0x72, 0x70, 0x63, 0x54, 0x6c, 0x73, 0x4b, 0x65, 0x79, 0x46, 0x69, 0x6c, 0x65, 0x22, 0x6e, 0x0a,
+	0x05, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x5f, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6d, 0x61, 0x78,
+	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x61, 0x78, 0x5f, 0x72,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6d, 0x61,
+	0x78, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x5f,
+	0x77, 0x69, 0x6c, 0x64, 0x63, 0x61, 0x72, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0c, 0x6d, 0x61, 0x78, 0x57, 0x69, 0x6c, 0x64, 0x63, 0x61, 0x72, 0x64, 0x73, 0x22, 0xcd, 0x02,
+	0x0a, 0x0b, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x34, 0x0a,
+	0x0d, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x0c, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x51, 0x75,
+	0x6f, 0x74, 0x61, 0x12, 0x36, 0x0a, 0x05, 0x75, 0x73, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x20, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x51, 0x75,
+	0x6f, 0x74, 0x61, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x75, 0x73, 0x65, 0x72, 0x73, 0x12, 0x39, 0x0a, 0x06, 0x67,
+	0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x61, 0x70,
+	0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x1a, 0x49, 0x0a, 0x0a, 0x55, 0x73, 0x65, 0x72, 0x73, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x25, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x1a, 0x4a, 0x0a, 0x0b, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x25, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x51, 0x75, 0x6f,
+	0x74, 0x61, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x99, 0x01,
+	0x0a, 0x0a, 0x51, 0x75, 0x6f, 0x74, 0x61, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72,
+	0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x72, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x77, 0x69, 0x6c, 0x64, 0x63, 0x61, 0x72, 0x64,
+	0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x77, 0x69, 0x6c, 0x64, 0x63, 0x61, 0x72,
+	0x64, 0x73, 0x12, 0x25, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0f, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x51, 0x75, 0x6f,
+	0x74, 0x61, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0xe7, 0x02, 0x0a, 0x0a, 0x4c, 0x44,
+	0x41, 0x50, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x6c, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x5f, 0x74, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x54, 0x6c, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x63, 0x61, 0x5f, 0x66, 0x69,
+	0x6c, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x61, 0x46, 0x69, 0x6c, 0x65,
+	0x12, 0x17, 0x0a, 0x07, 0x62, 0x69, 0x6e, 0x64, 0x5f, 0x64, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x62, 0x69, 0x6e, 0x64, 0x44, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x62, 0x69, 0x6e,
+	0x64, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0c, 0x62, 0x69, 0x6e, 0x64, 0x50, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x20,
+	0x0a, 0x0c, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x64, 0x6e, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x42, 0x61, 0x73, 0x65, 0x44, 0x6e,
+	0x12, 0x1f, 0x0a, 0x0b, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x46, 0x69, 0x6c, 0x74, 0x65,
+	0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x61, 0x74, 0x74, 0x72, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x41, 0x74, 0x74, 0x72,
+	0x12, 0x24, 0x0a, 0x0e, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f,
+	0x64, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x61, 0x64, 0x6d, 0x69, 0x6e, 0x47,
+	0x72, 0x6f, 0x75, 0x70, 0x44, 0x6e, 0x12, 0x26, 0x0a, 0x0f, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72,
+	0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x64, 0x6e, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0d, 0x6d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x44, 0x6e, 0x12, 0x23,
+	0x0a, 0x0d, 0x73, 0x79, 0x6e, 0x63, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x73, 0x79, 0x6e, 0x63, 0x49, 0x6e, 0x74, 0x65, 0x72,
+	0x76, 0x61, 0x6c, 0x22, 0xf0, 0x01, 0x0a, 0x0a, 0x41, 0x75, 0x74, 0x68, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x12, 0x2a, 0x0a, 0x11, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x5f, 0x75, 0x73, 0x65, 0x72,
+	0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x70,
+	0x72, 0x6f, 0x78, 0x79, 0x55, 0x73, 0x65, 0x72, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x27,
+	0x0a, 0x0f, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x70, 0x72, 0x6f, 0x78, 0x69, 0x65,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64,
+	0x50, 0x72, 0x6f, 0x78, 0x69, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x64, 0x6d, 0x69, 0x6e,
+	0x5f, 0x75, 0x73, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x64,
+	0x6d, 0x69, 0x6e, 0x55, 0x73, 0x65, 0x72, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6f, 0x69, 0x64, 0x63,
+	0x5f, 0x69, 0x73, 0x73, 0x75, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6f,
+	0x69, 0x64, 0x63, 0x49, 0x73, 0x73, 0x75, 0x65, 0x72, 0x12, 0x23, 0x0a, 0x0d, 0x6f, 0x69, 0x64,
+	0x63, 0x5f, 0x61, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0c, 0x6f, 0x69, 0x64, 0x63, 0x41, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x26,
+	0x0a, 0x0f, 0x6f, 0x69, 0x64, 0x63, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x63, 0x6c, 0x61, 0x69,
+	0x6d, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6f, 0x69, 0x64, 0x63, 0x55, 0x73, 0x65,
+	0x72, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x22, 0xaa, 0x01, 0x0a, 0x0e, 0x44, 0x61, 0x74, 0x61, 0x62,
+	0x61, 0x73, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73,
+	0x74, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x68, 0x6f, 0x73,
+	0x74, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x23, 0x0a,
+	0x0d, 0x64, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x64, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x71, 0x6c, 0x69, 0x74, 0x65, 0x5f, 0x70, 0x61, 0x74,
+	0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x71, 0x6c, 0x69, 0x74, 0x65, 0x50,
+	0x61, 0x74, 0x68, 0x22, 0xe1, 0x05, 0x0a, 0x09, 0x44, 0x4e, 0x53, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x6f, 0x6f, 0x74, 0x5f, 0x7a, 0x6f, 0x6e, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x72, 0x6f, 0x6f, 0x74, 0x5a, 0x6f, 0x6e, 0x65, 0x73,
+	0x12, 0x1b, 0x0a, 0x09, 0x61, 0x78, 0x66, 0x72, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x08, 0x61, 0x78, 0x66, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x1f, 0x0a,
+	0x0b, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x17,
+	0x0a, 0x07, 0x6e, 0x73, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x6e, 0x73, 0x41, 0x64, 0x64, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x64, 0x6d, 0x69, 0x6e,
+	0x5f, 0x65, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x64,
+	0x6d, 0x69, 0x6e, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x17, 0x0a, 0x07, 0x61, 0x78, 0x66, 0x72,
+	0x5f, 0x74, 0x6f, 0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x61, 0x78, 0x66, 0x72, 0x54,
+	0x6f, 0x12, 0x20, 0x0a, 0x0c, 0x6d, 0x61, 0x78, 0x5f, 0x75, 0x64, 0x70, 0x5f, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x6d, 0x61, 0x78, 0x55, 0x64, 0x70, 0x53,
+	0x69, 0x7a, 0x65, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x6c, 0x73, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x65,
+	0x6e, 0x5f, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x6c,
+	0x73, 0x4c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x22, 0x0a, 0x0d, 0x74,
+	0x6c, 0x73, 0x5f, 0x63, 0x65, 0x72, 0x74, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x74, 0x6c, 0x73, 0x43, 0x65, 0x72, 0x74, 0x46, 0x69, 0x6c, 0x65, 0x12,
+	0x20, 0x0a, 0x0c, 0x74, 0x6c, 0x73, 0x5f, 0x6b, 0x65, 0x79, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x6c, 0x73, 0x4b, 0x65, 0x79, 0x46, 0x69, 0x6c,
+	0x65, 0x12, 0x26, 0x0a, 0x0f, 0x64, 0x6f, 0x68, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x65, 0x6e, 0x5f,
+	0x61, 0x64, 0x64, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x64, 0x6f, 0x68, 0x4c,
+	0x69, 0x73, 0x74, 0x65, 0x6e, 0x41, 0x64, 0x64, 0x72, 0x12, 0x2b, 0x0a, 0x11, 0x69, 0x6e, 0x74,
+	0x65, 0x72, 0x6e, 0x61, 0x6c, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x18, 0x0c,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x50, 0x72,
+	0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65,
+	0x64, 0x5f, 0x66, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x65, 0x72, 0x73, 0x18, 0x0d, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x11, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x46, 0x6f, 0x72, 0x77, 0x61,
+	0x72, 0x64, 0x65, 0x72, 0x73, 0x12, 0x25, 0x0a, 0x03, 0x72, 0x72, 0x6c, 0x18, 0x0e, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x52,
+	0x4c, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x03, 0x72, 0x72, 0x6c, 0x12, 0x23, 0x0a, 0x0d,
+	0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x5f, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x53, 0x65, 0x63, 0x72, 0x65,
+	0x74, 0x12, 0x32, 0x0a, 0x15, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x5f, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x5f, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x18, 0x10, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x13, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x43,
+	0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74,
+	0x79, 0x18, 0x11, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74,
+	0x79, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x12, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x68,
+	0x69, 0x64, 0x65, 0x5f, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x13, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0c, 0x68, 0x69, 0x64, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x74, 0x79,
+	0x12, 0x21, 0x0a, 0x0c, 0x68, 0x69, 0x64, 0x65, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x14, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x68, 0x69, 0x64, 0x65, 0x56, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x73, 0x69, 0x64, 0x18, 0x15, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x73, 0x69, 0x64, 0x22, 0xf8, 0x02, 0x0a, 0x09, 0x52, 0x52, 0x4c, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x30, 0x0a, 0x14, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x12, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x73, 0x50, 0x65,
+	0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x12, 0x2a, 0x0a, 0x11, 0x6e, 0x6f, 0x64, 0x61, 0x74,
+	0x61, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0f, 0x6e, 0x6f, 0x64, 0x61, 0x74, 0x61, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x12, 0x30, 0x0a, 0x14, 0x6e, 0x78, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73,
+	0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x12, 0x6e, 0x78, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x73, 0x50, 0x65, 0x72, 0x53,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x12, 0x2a, 0x0a, 0x11, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x5f,
+	0x70, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x69,
+	0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x73, 0x6c, 0x69, 0x70, 0x12, 0x2c, 0x0a,
+	0x12, 0x69, 0x70, 0x76, 0x34, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x5f, 0x6c, 0x65, 0x6e,
+	0x67, 0x74, 0x68, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x10, 0x69, 0x70, 0x76, 0x34, 0x50,
+	0x72, 0x65, 0x66, 0x69, 0x78, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x2c, 0x0a, 0x12, 0x69,
+	0x70, 0x76, 0x36, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74,
+	0x68, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x10, 0x69, 0x70, 0x76, 0x36, 0x50, 0x72, 0x65,
+	0x66, 0x69, 0x78, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x27, 0x0a, 0x0f, 0x65, 0x78, 0x65,
+	0x6d, 0x70, 0x74, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x18, 0x09, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0e, 0x65, 0x78, 0x65, 0x6d, 0x70, 0x74, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78,
+	0x65, 0x73, 0x22, 0xfb, 0x01, 0x0a, 0x09, 0x44, 0x4e, 0x53, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x10,
+	0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x74, 0x74, 0x6c,
+	0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x76, 0x69, 0x65, 0x77, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x76, 0x69, 0x65, 0x77, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x69, 0x73, 0x61,
+	0x62, 0x6c, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x64, 0x69, 0x73, 0x61,
+	0x62, 0x6c, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x0b, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x22, 0x48, 0x0a, 0x0e, 0x5a, 0x6f, 0x6e, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x22, 0x50, 0x0a, 0x12, 0x5a, 0x6f,
+	0x6e, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4c, 0x69, 0x73, 0x74,
+	0x12, 0x3a, 0x0a, 0x0b, 0x64, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x0b, 0x64, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x8d, 0x02, 0x0a,
+	0x08, 0x41, 0x50, 0x49, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x18, 0x0a,
+	0x07, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72,
+	0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65,
+	0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x61, 0x73,
+	0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x61, 0x73, 0x68, 0x12, 0x14, 0x0a,
+	0x05, 0x77, 0x72, 0x69, 0x74, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x77, 0x72,
+	0x69, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x73, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x73, 0x12,
+	0x1d, 0x0a, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74, 0x12, 0x1d, 0x0a,
+	0x0a, 0x72, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x72, 0x65, 0x76, 0x6f, 0x6b, 0x65, 0x64, 0x41, 0x74, 0x22, 0x4b, 0x0a, 0x0b,
+	0x4e, 0x65, 0x77, 0x41, 0x50, 0x49, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x26, 0x0a, 0x04, 0x69,
+	0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x61, 0x70, 0x69, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x41, 0x50, 0x49, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x04, 0x69,
+	0x6e, 0x66, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x3a, 0x0a, 0x0c, 0x41, 0x50, 0x49,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x06, 0x74, 0x6f, 0x6b,
+	0x65, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x61, 0x70, 0x69, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x41, 0x50, 0x49, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x06, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x22, 0xd1, 0x01, 0x0a, 0x0a, 0x41, 0x75, 0x64, 0x69, 0x74, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x63, 0x74, 0x6f,
+	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x1b,
+	0x0a, 0x09, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x69, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x49, 0x70, 0x12, 0x16, 0x0a, 0x06, 0x61,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x62,
+	0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x62, 0x65, 0x66,
+	0x6f, 0x72, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x22, 0x40, 0x0a, 0x0e, 0x41, 0x75, 0x64,
+	0x69, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x2e, 0x0a, 0x07, 0x65,
+	0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61,
+	0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x41, 0x75, 0x64, 0x69, 0x74, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x52, 0x07, 0x65, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x22, 0x3e, 0x0a, 0x0d, 0x44,
+	0x4e, 0x53, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x2d, 0x0a, 0x07,
+	0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e,
+	0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x4e, 0x53, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x52, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x22, 0xe2, 0x02, 0x0a, 0x0d,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a,
+	0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x7a, 0x6f, 0x6e,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x06, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12, 0x1b, 0x0a,
+	0x09, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x03, 0x74, 0x74, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x69, 0x6f,
+	0x72, 0x69, 0x74, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x76, 0x69, 0x65, 0x77, 0x18, 0x0d, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x76, 0x69, 0x65, 0x77, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x69, 0x73, 0x61,
+	0x62, 0x6c, 0x65, 0x64, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x64, 0x69, 0x73, 0x61,
+	0x62, 0x6c, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x0f, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x22, 0x48, 0x0a, 0x11, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x33, 0x0a, 0x08, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x08, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x38, 0x0a, 0x0a, 0x5a, 0x6f,
+	0x6e, 0x65, 0x53, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x73, 0x65,
+	0x72, 0x69, 0x61, 0x6c, 0x22, 0x69, 0x0a, 0x0c, 0x5a, 0x6f, 0x6e, 0x65, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x72, 0x69,
+	0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c,
+	0x12, 0x2d, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x4e, 0x53,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x22,
+	0x66, 0x0a, 0x0c, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12,
+	0x2b, 0x0a, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x13, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x4e, 0x53, 0x52, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x52, 0x06, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12, 0x29, 0x0a, 0x05,
+	0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70,
+	0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x4e, 0x53, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x52, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x22, 0x44, 0x0a, 0x10, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x30, 0x0a, 0x07, 0x63,
+	0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x61,
+	0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x73, 0x22, 0x7a, 0x0a,
+	0x0f, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x4f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x12, 0x0e, 0x0a, 0x02, 0x6f, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x6f, 0x70,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x2b, 0x0a, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x4e, 0x53, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x06, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x1a, 0x0a,
+	0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x48, 0x0a, 0x0b, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x39, 0x0a, 0x0a, 0x6f, 0x70, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x61,
+	0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x4f, 0x70,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x6f, 0x70, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x22, 0x3c, 0x0a, 0x0a, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x22, 0x63, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2c, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c,
+	0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x06,
+	0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x22, 0x37, 0x0a, 0x09, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x52, 0x65, 0x66, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x72, 0x65, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x22,
+	0x35, 0x0a, 0x0b, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x12,
+	0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x7a, 0x6f,
+	0x6e, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x68, 0x0a, 0x09, 0x5a, 0x6f, 0x6e, 0x65, 0x51, 0x75,
+	0x65, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x72, 0x69, 0x61,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74,
+	0x69, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x66, 0x5f, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x69, 0x66, 0x53, 0x65, 0x72, 0x69, 0x61, 0x6c,
+	0x22, 0x5f, 0x0a, 0x06, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f,
+	0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x2d, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44,
+	0x4e, 0x53, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x73, 0x22, 0x38, 0x0a, 0x0a, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12,
+	0x2a, 0x0a, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x10, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x52, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x22, 0x35, 0x0a, 0x0b, 0x44,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f,
+	0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x12,
+	0x0a, 0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73,
+	0x65, 0x72, 0x22, 0x3a, 0x0a, 0x0c, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x73, 0x65, 0x72, 0x69, 0x61, 0x6c, 0x32, 0xa6,
+	0x08, 0x0a, 0x12, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x38, 0x0a, 0x0c, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x44, 0x4e, 0x53, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x1a, 0x13, 0x2e, 0x61, 0x70, 0x69,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x4e, 0x53, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12,
+	0x35, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x13, 0x2e, 0x61,
+	0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x65,
+	0x66, 0x1a, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x4e, 0x53,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x3d, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
+	0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x15, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x17, 0x2e, 0x61,
+	0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x4e, 0x53, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x38, 0x0a, 0x0c, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x44, 0x4e, 0x53, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x1a, 0x13, 0x2e, 0x61, 0x70, 0x69,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x4e, 0x53, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12,
+	0x3b, 0x0a, 0x0c, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x12,
+	0x13, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x52, 0x65, 0x66, 0x1a, 0x16, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x41, 0x0a, 0x0c,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x12, 0x15, 0x2e, 0x61,
+	0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x1a, 0x1a, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12,
+	0x36, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x5a, 0x6f, 0x6e, 0x65, 0x12, 0x13, 0x2e, 0x61, 0x70, 0x69,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a,
+	0x16, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x53,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x3f, 0x0a, 0x0b, 0x5a, 0x6f, 0x6e, 0x65, 0x48,
+	0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x1b, 0x2e, 0x61, 0x70,
+	0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x3b, 0x0a, 0x0c, 0x52, 0x6f, 0x6c, 0x6c,
+	0x62, 0x61, 0x63, 0x6b, 0x5a, 0x6f, 0x6e, 0x65, 0x12, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x16, 0x2e,
+	0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x42, 0x0a, 0x0c, 0x44, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74,
+	0x65, 0x5a, 0x6f, 0x6e, 0x65, 0x12, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a,
+	0x18, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x44,
+	0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x44, 0x0a, 0x0e, 0x55, 0x6e, 0x64,
+	0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x65, 0x5a, 0x6f, 0x6e, 0x65, 0x12, 0x18, 0x2e, 0x61, 0x70,
+	0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x67,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x18, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12,
+	0x44, 0x0a, 0x0f, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x12, 0x13, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x5a, 0x6f,
+	0x6e, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x1c, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x5a, 0x6f, 0x6e, 0x65, 0x44, 0x65, 0x6c, 0x65, 0x67, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x3a, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x44, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x73, 0x12, 0x15, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x51, 0x75, 0x65, 0x72, 0x79, 0x1a, 0x14, 0x2e, 0x61, 0x70,
+	0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4c, 0x69, 0x73,
+	0x74, 0x12, 0x2f, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x10,
+	0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x1a, 0x10, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x39, 0x0a, 0x09, 0x50, 0x75, 0x74, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x10, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x1a, 0x1a, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x3c, 0x0a,
+	0x0c, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x10, 0x2e,
+	0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x1a,
+	0x1a, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x3a, 0x0a, 0x05, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x12, 0x16, 0x2e, 0x61, 0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x61,
+	0x70, 0x69, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x30, 0x01, 0x42, 0x09, 0x5a, 0x07, 0x2e, 0x3b, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_drs_proto_rawDescOnce sync.Once
+	file_drs_proto_rawDescData = file_drs_proto_rawDesc
+)
+
+func file_drs_proto_rawDescGZIP() []byte {
+	file_drs_proto_rawDescOnce.Do(func() {
+		file_drs_proto_rawDescData = protoimpl.X.CompressGZIP(file_drs_proto_rawDescData)
+	})
+	return file_drs_proto_rawDescData
+}
+
+var file_drs_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
+var file_drs_proto_goTypes = []interface{}{
+	(*ServerConfig)(nil),       // 0: apiproto.ServerConfig
+	(*Quota)(nil),              // 1: apiproto.Quota
+	(*QuotaConfig)(nil),        // 2: apiproto.QuotaConfig
+	(*QuotaUsage)(nil),         // 3: apiproto.QuotaUsage
+	(*LDAPConfig)(nil),         // 4: apiproto.LDAPConfig
+	(*AuthConfig)(nil),         // 5: apiproto.AuthConfig
+	(*DatabaseConfig)(nil),     // 6: apiproto.DatabaseConfig
+	(*DNSConfig)(nil),          // 7: apiproto.DNSConfig
+	(*RRLConfig)(nil),          // 8: apiproto.RRLConfig
+	(*DNSRecord)(nil),          // 9: apiproto.DNSRecord
+	(*ZoneDelegation)(nil),     // 10: apiproto.ZoneDelegation
+	(*ZoneDelegationList)(nil), // 11: apiproto.ZoneDelegationList
+	(*APIToken)(nil),           // 12: apiproto.APIToken
+	(*NewAPIToken)(nil),        // 13: apiproto.NewAPIToken
+	(*APITokenList)(nil),       // 14: apiproto.APITokenList
+	(*AuditEntry)(nil),         // 15: apiproto.AuditEntry
+	(*AuditEntryList)(nil),     // 16: apiproto.AuditEntryList
+	(*DNSRecordList)(nil),      // 17: apiproto.DNSRecordList
+	(*RecordVersion)(nil),      // 18: apiproto.RecordVersion
+	(*RecordVersionList)(nil),  // 19: apiproto.RecordVersionList
+	(*ZoneSerial)(nil),         // 20: apiproto.ZoneSerial
+	(*ZoneSnapshot)(nil),       // 21: apiproto.ZoneSnapshot
+	(*RecordChange)(nil),       // 22: apiproto.RecordChange
+	(*RecordChangeList)(nil),   // 23: apiproto.RecordChangeList
+	(*RecordOperation)(nil),    // 24: apiproto.RecordOperation
+	(*RecordBatch)(nil),        // 25: apiproto.RecordBatch
+	(*FieldError)(nil),         // 26: apiproto.FieldError
+	(*Error)(nil),              // 27: apiproto.Error
+	(*RecordRef)(nil),          // 28: apiproto.RecordRef
+	(*RecordQuery)(nil),        // 29: apiproto.RecordQuery
+	(*ZoneQuery)(nil),          // 30: apiproto.ZoneQuery
+	(*Device)(nil),             // 31: apiproto.Device
+	(*DeviceList)(nil),         // 32: apiproto.DeviceList
+	(*DeviceQuery)(nil),        // 33: apiproto.DeviceQuery
+	(*WatchRequest)(nil),       // 34: apiproto.WatchRequest
+	nil,                        // 35: apiproto.QuotaConfig.UsersEntry
+	nil,                        // 36: apiproto.QuotaConfig.GroupsEntry
+}
+var file_drs_proto_depIdxs = []int32{
+	6,  // 0: apiproto.ServerConfig.DB_conf:type_name -> apiproto.DatabaseConfig
+	7,  // 1: apiproto.ServerConfig.dns_conf:type_name -> apiproto.DNSConfig
+	5,  // 2: apiproto.ServerConfig.auth_conf:type_name -> apiproto.AuthConfig
+	4,  // 3: apiproto.ServerConfig.ldap_conf:type_name -> apiproto.LDAPConfig
+	2,  // 4: apiproto.ServerConfig.quota_conf:type_name -> apiproto.QuotaConfig
+	1,  // 5: apiproto.QuotaConfig.default_quota:type_name -> apiproto.Quota
+	35, // 6: apiproto.QuotaConfig.users:type_name -> apiproto.QuotaConfig.UsersEntry
+	36, // 7: apiproto.QuotaConfig.groups:type_name -> apiproto.QuotaConfig.GroupsEntry
+	1,  // 8: apiproto.QuotaUsage.limit:type_name -> apiproto.Quota
+	8,  // 9: apiproto.DNSConfig.rrl:type_name -> apiproto.RRLConfig
+	10, // 10: apiproto.ZoneDelegationList.delegations:type_name -> apiproto.ZoneDelegation
+	12, // 11: apiproto.NewAPIToken.info:type_name -> apiproto.APIToken
+	12, // 12: apiproto.APITokenList.tokens:type_name -> apiproto.APIToken
+	15, // 13: apiproto.AuditEntryList.entries:type_name -> apiproto.AuditEntry
+	9,  // 14: apiproto.DNSRecordList.records:type_name -> apiproto.DNSRecord
+	18, // 15: apiproto.RecordVersionList.versions:type_name -> apiproto.RecordVersion
+	9,  // 16: apiproto.ZoneSnapshot.records:type_name -> apiproto.DNSRecord
+	9,  // 17: apiproto.RecordChange.before:type_name -> apiproto.DNSRecord
+	9,  // 18: apiproto.RecordChange.after:type_name -> apiproto.DNSRecord
+	22, // 19: apiproto.RecordChangeList.changes:type_name -> apiproto.RecordChange
+	9,  // 20: apiproto.RecordOperation.record:type_name -> apiproto.DNSRecord
+	24, // 21: apiproto.RecordBatch.operations:type_name -> apiproto.RecordOperation
+	26, // 22: apiproto.Error.fields:type_name -> apiproto.FieldError
+	9,  // 23: apiproto.Device.records:type_name -> apiproto.DNSRecord
+	31, // 24: apiproto.DeviceList.devices:type_name -> apiproto.Device
+	1,  // 25: apiproto.QuotaConfig.UsersEntry.value:type_name -> apiproto.Quota
+	1,  // 26: apiproto.QuotaConfig.GroupsEntry.value:type_name -> apiproto.Quota
+	9,  // 27: apiproto.DeviceRegistration.CreateRecord:input_type -> apiproto.DNSRecord
+	28, // 28: apiproto.DeviceRegistration.GetRecord:input_type -> apiproto.RecordRef
+	29, // 29: apiproto.DeviceRegistration.ListRecords:input_type -> apiproto.RecordQuery
+	9,  // 30: apiproto.DeviceRegistration.UpdateRecord:input_type -> apiproto.DNSRecord
+	28, // 31: apiproto.DeviceRegistration.DeleteRecord:input_type -> apiproto.RecordRef
+	25, // 32: apiproto.DeviceRegistration.BatchRecords:input_type -> apiproto.RecordBatch
+	30, // 33: apiproto.DeviceRegistration.GetZone:input_type -> apiproto.ZoneQuery
+	30, // 34: apiproto.DeviceRegistration.ZoneHistory:input_type -> apiproto.ZoneQuery
+	30, // 35: apiproto.DeviceRegistration.RollbackZone:input_type -> apiproto.ZoneQuery
+	10, // 36: apiproto.DeviceRegistration.DelegateZone:input_type -> apiproto.ZoneDelegation
+	10, // 37: apiproto.DeviceRegistration.UndelegateZone:input_type -> apiproto.ZoneDelegation
+	30, // 38: apiproto.DeviceRegistration.ListDelegations:input_type -> apiproto.ZoneQuery
+	33, // 39: apiproto.DeviceRegistration.ListDevices:input_type -> apiproto.DeviceQuery
+	31, // 40: apiproto.DeviceRegistration.GetDevice:input_type -> apiproto.Device
+	31, // 41: apiproto.DeviceRegistration.PutDevice:input_type -> apiproto.Device
+	31, // 42: apiproto.DeviceRegistration.DeleteDevice:input_type -> apiproto.Device
+	34, // 43: apiproto.DeviceRegistration.Watch:input_type -> apiproto.WatchRequest
+	9,  // 44: apiproto.DeviceRegistration.CreateRecord:output_type -> apiproto.DNSRecord
+	9,  // 45: apiproto.DeviceRegistration.GetRecord:output_type -> apiproto.DNSRecord
+	17, // 46: apiproto.DeviceRegistration.ListRecords:output_type -> apiproto.DNSRecordList
+	9,  // 47: apiproto.DeviceRegistration.UpdateRecord:output_type -> apiproto.DNSRecord
+	22, // 48: apiproto.DeviceRegistration.DeleteRecord:output_type -> apiproto.RecordChange
+	23, // 49: apiproto.DeviceRegistration.BatchRecords:output_type -> apiproto.RecordChangeList
+	21, // 50: apiproto.DeviceRegistration.GetZone:output_type -> apiproto.ZoneSnapshot
+	19, // 51: apiproto.DeviceRegistration.ZoneHistory:output_type -> apiproto.RecordVersionList
+	21, // 52: apiproto.DeviceRegistration.RollbackZone:output_type -> apiproto.ZoneSnapshot
+	10, // 53: apiproto.DeviceRegistration.DelegateZone:output_type -> apiproto.ZoneDelegation
+	10, // 54: apiproto.DeviceRegistration.UndelegateZone:output_type -> apiproto.ZoneDelegation
+	11, // 55: apiproto.DeviceRegistration.ListDelegations:output_type -> apiproto.ZoneDelegationList
+	32, // 56: apiproto.DeviceRegistration.ListDevices:output_type -> apiproto.DeviceList
+	31, // 57: apiproto.DeviceRegistration.GetDevice:output_type -> apiproto.Device
+	23, // 58: apiproto.DeviceRegistration.PutDevice:output_type -> apiproto.RecordChangeList
+	23, // 59: apiproto.DeviceRegistration.DeleteDevice:output_type -> apiproto.RecordChangeList
+	18, // 60: apiproto.DeviceRegistration.Watch:output_type -> apiproto.RecordVersion
+	44, // [44:61] is the sub-list for method output_type
+	27, // [27:44] is the sub-list for method input_type
+	27, // [27:27] is the sub-list for extension type_name
+	27, // [27:27] is the sub-list for extension extendee
+	0,  // [0:27] is the sub-list for field type_name
+}
+
+func init() { file_drs_proto_init() }
+func file_drs_proto_init() {
+	if File_drs_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_drs_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ServerConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Quota); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QuotaConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QuotaUsage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LDAPConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuthConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DatabaseConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DNSConfig); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -453,8 +3511,8 @@ func file_drs_proto_init() {
 				return nil
 			}
 		}
-		file_drs_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DNSConfig); i {
+		file_drs_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RRLConfig); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -465,7 +3523,7 @@ func file_drs_proto_init() {
 				return nil
 			}
 		}
-		file_drs_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_drs_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DNSRecord); i {
 			case 0:
 				return &v.state
@@ -477,6 +3535,306 @@ func file_drs_proto_init() {
 				return nil
 			}
 		}
+		file_drs_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ZoneDelegation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ZoneDelegationList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*APIToken); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*NewAPIToken); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*APITokenList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuditEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuditEntryList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DNSRecordList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordVersion); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordVersionList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ZoneSerial); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ZoneSnapshot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordChangeList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordOperation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordBatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FieldError); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Error); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordRef); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ZoneQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Device); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeviceList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeviceQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drs_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -484,9 +3842,9 @@ func file_drs_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_drs_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   37,
 			NumExtensions: 0,
-			NumServices:   0,
+			NumServices:   1,
 		},
 		GoTypes:           file_drs_proto_goTypes,
 		DependencyIndexes: file_drs_proto_depIdxs,
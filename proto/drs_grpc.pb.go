@@ -0,0 +1,755 @@
+// Proto definition for API service.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.21.12
+// source: drs.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	DeviceRegistration_CreateRecord_FullMethodName    = "/apiproto.DeviceRegistration/CreateRecord"
+	DeviceRegistration_GetRecord_FullMethodName       = "/apiproto.DeviceRegistration/GetRecord"
+	DeviceRegistration_ListRecords_FullMethodName     = "/apiproto.DeviceRegistration/ListRecords"
+	DeviceRegistration_UpdateRecord_FullMethodName    = "/apiproto.DeviceRegistration/UpdateRecord"
+	DeviceRegistration_DeleteRecord_FullMethodName    = "/apiproto.DeviceRegistration/DeleteRecord"
+	DeviceRegistration_BatchRecords_FullMethodName    = "/apiproto.DeviceRegistration/BatchRecords"
+	DeviceRegistration_GetZone_FullMethodName         = "/apiproto.DeviceRegistration/GetZone"
+	DeviceRegistration_ZoneHistory_FullMethodName     = "/apiproto.DeviceRegistration/ZoneHistory"
+	DeviceRegistration_RollbackZone_FullMethodName    = "/apiproto.DeviceRegistration/RollbackZone"
+	DeviceRegistration_DelegateZone_FullMethodName    = "/apiproto.DeviceRegistration/DelegateZone"
+	DeviceRegistration_UndelegateZone_FullMethodName  = "/apiproto.DeviceRegistration/UndelegateZone"
+	DeviceRegistration_ListDelegations_FullMethodName = "/apiproto.DeviceRegistration/ListDelegations"
+	DeviceRegistration_ListDevices_FullMethodName     = "/apiproto.DeviceRegistration/ListDevices"
+	DeviceRegistration_GetDevice_FullMethodName       = "/apiproto.DeviceRegistration/GetDevice"
+	DeviceRegistration_PutDevice_FullMethodName       = "/apiproto.DeviceRegistration/PutDevice"
+	DeviceRegistration_DeleteDevice_FullMethodName    = "/apiproto.DeviceRegistration/DeleteDevice"
+	DeviceRegistration_Watch_FullMethodName           = "/apiproto.DeviceRegistration/Watch"
+)
+
+// DeviceRegistrationClient is the client API for DeviceRegistration service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DeviceRegistrationClient interface {
+	// CreateRecord adds a record, and returns it as it was stored.
+	CreateRecord(ctx context.Context, in *DNSRecord, opts ...grpc.CallOption) (*DNSRecord, error)
+	GetRecord(ctx context.Context, in *RecordRef, opts ...grpc.CallOption) (*DNSRecord, error)
+	ListRecords(ctx context.Context, in *RecordQuery, opts ...grpc.CallOption) (*DNSRecordList, error)
+	// UpdateRecord replaces the record with the same ID. If the revision is
+	// set the record must still be at it.
+	UpdateRecord(ctx context.Context, in *DNSRecord, opts ...grpc.CallOption) (*DNSRecord, error)
+	DeleteRecord(ctx context.Context, in *RecordRef, opts ...grpc.CallOption) (*RecordChange, error)
+	// BatchRecords makes every change in a batch, or none of them.
+	BatchRecords(ctx context.Context, in *RecordBatch, opts ...grpc.CallOption) (*RecordChangeList, error)
+	GetZone(ctx context.Context, in *ZoneQuery, opts ...grpc.CallOption) (*ZoneSnapshot, error)
+	ZoneHistory(ctx context.Context, in *ZoneQuery, opts ...grpc.CallOption) (*RecordVersionList, error)
+	// RollbackZone puts a zone back to how it was at the serial or time,
+	// and returns it as it is now.
+	RollbackZone(ctx context.Context, in *ZoneQuery, opts ...grpc.CallOption) (*ZoneSnapshot, error)
+	DelegateZone(ctx context.Context, in *ZoneDelegation, opts ...grpc.CallOption) (*ZoneDelegation, error)
+	UndelegateZone(ctx context.Context, in *ZoneDelegation, opts ...grpc.CallOption) (*ZoneDelegation, error)
+	ListDelegations(ctx context.Context, in *ZoneQuery, opts ...grpc.CallOption) (*ZoneDelegationList, error)
+	ListDevices(ctx context.Context, in *DeviceQuery, opts ...grpc.CallOption) (*DeviceList, error)
+	GetDevice(ctx context.Context, in *Device, opts ...grpc.CallOption) (*Device, error)
+	// PutDevice makes the records of a device those given, all at once.
+	// Records with an ID replace that record, those without one are added,
+	// and the device's records which aren't given are deleted.
+	PutDevice(ctx context.Context, in *Device, opts ...grpc.CallOption) (*RecordChangeList, error)
+	// DeleteDevice deletes every record of a device, all at once.
+	DeleteDevice(ctx context.Context, in *Device, opts ...grpc.CallOption) (*RecordChangeList, error)
+	// Watch sends the versions of the records in a zone as they are made,
+	// starting with those made since the serial asked for.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (DeviceRegistration_WatchClient, error)
+}
+
+type deviceRegistrationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDeviceRegistrationClient(cc grpc.ClientConnInterface) DeviceRegistrationClient {
+	return &deviceRegistrationClient{cc}
+}
+
+func (c *deviceRegistrationClient) CreateRecord(ctx context.Context, in *DNSRecord, opts ...grpc.CallOption) (*DNSRecord, error) {
+	out := new(DNSRecord)
+	err := c.cc.Invoke(ctx, DeviceRegistration_CreateRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) GetRecord(ctx context.Context, in *RecordRef, opts ...grpc.CallOption) (*DNSRecord, error) {
+	out := new(DNSRecord)
+	err := c.cc.Invoke(ctx, DeviceRegistration_GetRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) ListRecords(ctx context.Context, in *RecordQuery, opts ...grpc.CallOption) (*DNSRecordList, error) {
+	out := new(DNSRecordList)
+	err := c.cc.Invoke(ctx, DeviceRegistration_ListRecords_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) UpdateRecord(ctx context.Context, in *DNSRecord, opts ...grpc.CallOption) (*DNSRecord, error) {
+	out := new(DNSRecord)
+	err := c.cc.Invoke(ctx, DeviceRegistration_UpdateRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) DeleteRecord(ctx context.Context, in *RecordRef, opts ...grpc.CallOption) (*RecordChange, error) {
+	out := new(RecordChange)
+	err := c.cc.Invoke(ctx, DeviceRegistration_DeleteRecord_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) BatchRecords(ctx context.Context, in *RecordBatch, opts ...grpc.CallOption) (*RecordChangeList, error) {
+	out := new(RecordChangeList)
+	err := c.cc.Invoke(ctx, DeviceRegistration_BatchRecords_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) GetZone(ctx context.Context, in *ZoneQuery, opts ...grpc.CallOption) (*ZoneSnapshot, error) {
+	out := new(ZoneSnapshot)
+	err := c.cc.Invoke(ctx, DeviceRegistration_GetZone_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) ZoneHistory(ctx context.Context, in *ZoneQuery, opts ...grpc.CallOption) (*RecordVersionList, error) {
+	out := new(RecordVersionList)
+	err := c.cc.Invoke(ctx, DeviceRegistration_ZoneHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) RollbackZone(ctx context.Context, in *ZoneQuery, opts ...grpc.CallOption) (*ZoneSnapshot, error) {
+	out := new(ZoneSnapshot)
+	err := c.cc.Invoke(ctx, DeviceRegistration_RollbackZone_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) DelegateZone(ctx context.Context, in *ZoneDelegation, opts ...grpc.CallOption) (*ZoneDelegation, error) {
+	out := new(ZoneDelegation)
+	err := c.cc.Invoke(ctx, DeviceRegistration_DelegateZone_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) UndelegateZone(ctx context.Context, in *ZoneDelegation, opts ...grpc.CallOption) (*ZoneDelegation, error) {
+	out := new(ZoneDelegation)
+	err := c.cc.Invoke(ctx, DeviceRegistration_UndelegateZone_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) ListDelegations(ctx context.Context, in *ZoneQuery, opts ...grpc.CallOption) (*ZoneDelegationList, error) {
+	out := new(ZoneDelegationList)
+	err := c.cc.Invoke(ctx, DeviceRegistration_ListDelegations_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) ListDevices(ctx context.Context, in *DeviceQuery, opts ...grpc.CallOption) (*DeviceList, error) {
+	out := new(DeviceList)
+	err := c.cc.Invoke(ctx, DeviceRegistration_ListDevices_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) GetDevice(ctx context.Context, in *Device, opts ...grpc.CallOption) (*Device, error) {
+	out := new(Device)
+	err := c.cc.Invoke(ctx, DeviceRegistration_GetDevice_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) PutDevice(ctx context.Context, in *Device, opts ...grpc.CallOption) (*RecordChangeList, error) {
+	out := new(RecordChangeList)
+	err := c.cc.Invoke(ctx, DeviceRegistration_PutDevice_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) DeleteDevice(ctx context.Context, in *Device, opts ...grpc.CallOption) (*RecordChangeList, error) {
+	out := new(RecordChangeList)
+	err := c.cc.Invoke(ctx, DeviceRegistration_DeleteDevice_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceRegistrationClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (DeviceRegistration_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &DeviceRegistration_ServiceDesc.Streams[0], DeviceRegistration_Watch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &deviceRegistrationWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type DeviceRegistration_WatchClient interface {
+	Recv() (*RecordVersion, error)
+	grpc.ClientStream
+}
+
+type deviceRegistrationWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *deviceRegistrationWatchClient) Recv() (*RecordVersion, error) {
+	m := new(RecordVersion)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DeviceRegistrationServer is the server API for DeviceRegistration service.
+// All implementations must embed UnimplementedDeviceRegistrationServer
+// for forward compatibility
+type DeviceRegistrationServer interface {
+	// CreateRecord adds a record, and returns it as it was stored.
+	CreateRecord(context.Context, *DNSRecord) (*DNSRecord, error)
+	GetRecord(context.Context, *RecordRef) (*DNSRecord, error)
+	ListRecords(context.Context, *RecordQuery) (*DNSRecordList, error)
+	// UpdateRecord replaces the record with the same ID. If the revision is
+	// set the record must still be at it.
+	UpdateRecord(context.Context, *DNSRecord) (*DNSRecord, error)
+	DeleteRecord(context.Context, *RecordRef) (*RecordChange, error)
+	// BatchRecords makes every change in a batch, or none of them.
+	BatchRecords(context.Context, *RecordBatch) (*RecordChangeList, error)
+	GetZone(context.Context, *ZoneQuery) (*ZoneSnapshot, error)
+	ZoneHistory(context.Context, *ZoneQuery) (*RecordVersionList, error)
+	// RollbackZone puts a zone back to how it was at the serial or time,
+	// and returns it as it is now.
+	RollbackZone(context.Context, *ZoneQuery) (*ZoneSnapshot, error)
+	DelegateZone(context.Context, *ZoneDelegation) (*ZoneDelegation, error)
+	UndelegateZone(context.Context, *ZoneDelegation) (*ZoneDelegation, error)
+	ListDelegations(context.Context, *ZoneQuery) (*ZoneDelegationList, error)
+	ListDevices(context.Context, *DeviceQuery) (*DeviceList, error)
+	GetDevice(context.Context, *Device) (*Device, error)
+	// PutDevice makes the records of a device those given, all at once.
+	// Records with an ID replace that record, those without one are added,
+	// and the device's records which aren't given are deleted.
+	PutDevice(context.Context, *Device) (*RecordChangeList, error)
+	// DeleteDevice deletes every record of a device, all at once.
+	DeleteDevice(context.Context, *Device) (*RecordChangeList, error)
+	// Watch sends the versions of the records in a zone as they are made,
+	// starting with those made since the serial asked for.
+	Watch(*WatchRequest, DeviceRegistration_WatchServer) error
+	mustEmbedUnimplementedDeviceRegistrationServer()
+}
+
+// UnimplementedDeviceRegistrationServer must be embedded to have forward compatible implementations.
+type UnimplementedDeviceRegistrationServer struct {
+}
+
+func (UnimplementedDeviceRegistrationServer) CreateRecord(context.Context, *DNSRecord) (*DNSRecord, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRecord not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) GetRecord(context.Context, *RecordRef) (*DNSRecord, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecord not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) ListRecords(context.Context, *RecordQuery) (*DNSRecordList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRecords not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) UpdateRecord(context.Context, *DNSRecord) (*DNSRecord, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRecord not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) DeleteRecord(context.Context, *RecordRef) (*RecordChange, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRecord not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) BatchRecords(context.Context, *RecordBatch) (*RecordChangeList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchRecords not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) GetZone(context.Context, *ZoneQuery) (*ZoneSnapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetZone not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) ZoneHistory(context.Context, *ZoneQuery) (*RecordVersionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ZoneHistory not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) RollbackZone(context.Context, *ZoneQuery) (*ZoneSnapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackZone not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) DelegateZone(context.Context, *ZoneDelegation) (*ZoneDelegation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DelegateZone not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) UndelegateZone(context.Context, *ZoneDelegation) (*ZoneDelegation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UndelegateZone not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) ListDelegations(context.Context, *ZoneQuery) (*ZoneDelegationList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDelegations not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) ListDevices(context.Context, *DeviceQuery) (*DeviceList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDevices not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) GetDevice(context.Context, *Device) (*Device, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDevice not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) PutDevice(context.Context, *Device) (*RecordChangeList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutDevice not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) DeleteDevice(context.Context, *Device) (*RecordChangeList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDevice not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) Watch(*WatchRequest, DeviceRegistration_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedDeviceRegistrationServer) mustEmbedUnimplementedDeviceRegistrationServer() {}
+
+// UnsafeDeviceRegistrationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DeviceRegistrationServer will
+// result in compilation errors.
+type UnsafeDeviceRegistrationServer interface {
+	mustEmbedUnimplementedDeviceRegistrationServer()
+}
+
+func RegisterDeviceRegistrationServer(s grpc.ServiceRegistrar, srv DeviceRegistrationServer) {
+	s.RegisterService(&DeviceRegistration_ServiceDesc, srv)
+}
+
+func _DeviceRegistration_CreateRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DNSRecord)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).CreateRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_CreateRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).CreateRecord(ctx, req.(*DNSRecord))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_GetRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).GetRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_GetRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).GetRecord(ctx, req.(*RecordRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_ListRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).ListRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_ListRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).ListRecords(ctx, req.(*RecordQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_UpdateRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DNSRecord)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).UpdateRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_UpdateRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).UpdateRecord(ctx, req.(*DNSRecord))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_DeleteRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).DeleteRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_DeleteRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).DeleteRecord(ctx, req.(*RecordRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_BatchRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordBatch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).BatchRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_BatchRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).BatchRecords(ctx, req.(*RecordBatch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_GetZone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ZoneQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).GetZone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_GetZone_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).GetZone(ctx, req.(*ZoneQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_ZoneHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ZoneQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).ZoneHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_ZoneHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).ZoneHistory(ctx, req.(*ZoneQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_RollbackZone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ZoneQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).RollbackZone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_RollbackZone_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).RollbackZone(ctx, req.(*ZoneQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_DelegateZone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ZoneDelegation)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).DelegateZone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_DelegateZone_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).DelegateZone(ctx, req.(*ZoneDelegation))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_UndelegateZone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ZoneDelegation)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).UndelegateZone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_UndelegateZone_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).UndelegateZone(ctx, req.(*ZoneDelegation))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_ListDelegations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ZoneQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).ListDelegations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_ListDelegations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).ListDelegations(ctx, req.(*ZoneQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_ListDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).ListDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_ListDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).ListDevices(ctx, req.(*DeviceQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_GetDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Device)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).GetDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_GetDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).GetDevice(ctx, req.(*Device))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_PutDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Device)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).PutDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_PutDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).PutDevice(ctx, req.(*Device))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_DeleteDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Device)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceRegistrationServer).DeleteDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceRegistration_DeleteDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceRegistrationServer).DeleteDevice(ctx, req.(*Device))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceRegistration_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DeviceRegistrationServer).Watch(m, &deviceRegistrationWatchServer{stream})
+}
+
+type DeviceRegistration_WatchServer interface {
+	Send(*RecordVersion) error
+	grpc.ServerStream
+}
+
+type deviceRegistrationWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *deviceRegistrationWatchServer) Send(m *RecordVersion) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// DeviceRegistration_ServiceDesc is the grpc.ServiceDesc for DeviceRegistration service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DeviceRegistration_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "apiproto.DeviceRegistration",
+	HandlerType: (*DeviceRegistrationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateRecord",
+			Handler:    _DeviceRegistration_CreateRecord_Handler,
+		},
+		{
+			MethodName: "GetRecord",
+			Handler:    _DeviceRegistration_GetRecord_Handler,
+		},
+		{
+			MethodName: "ListRecords",
+			Handler:    _DeviceRegistration_ListRecords_Handler,
+		},
+		{
+			MethodName: "UpdateRecord",
+			Handler:    _DeviceRegistration_UpdateRecord_Handler,
+		},
+		{
+			MethodName: "DeleteRecord",
+			Handler:    _DeviceRegistration_DeleteRecord_Handler,
+		},
+		{
+			MethodName: "BatchRecords",
+			Handler:    _DeviceRegistration_BatchRecords_Handler,
+		},
+		{
+			MethodName: "GetZone",
+			Handler:    _DeviceRegistration_GetZone_Handler,
+		},
+		{
+			MethodName: "ZoneHistory",
+			Handler:    _DeviceRegistration_ZoneHistory_Handler,
+		},
+		{
+			MethodName: "RollbackZone",
+			Handler:    _DeviceRegistration_RollbackZone_Handler,
+		},
+		{
+			MethodName: "DelegateZone",
+			Handler:    _DeviceRegistration_DelegateZone_Handler,
+		},
+		{
+			MethodName: "UndelegateZone",
+			Handler:    _DeviceRegistration_UndelegateZone_Handler,
+		},
+		{
+			MethodName: "ListDelegations",
+			Handler:    _DeviceRegistration_ListDelegations_Handler,
+		},
+		{
+			MethodName: "ListDevices",
+			Handler:    _DeviceRegistration_ListDevices_Handler,
+		},
+		{
+			MethodName: "GetDevice",
+			Handler:    _DeviceRegistration_GetDevice_Handler,
+		},
+		{
+			MethodName: "PutDevice",
+			Handler:    _DeviceRegistration_PutDevice_Handler,
+		},
+		{
+			MethodName: "DeleteDevice",
+			Handler:    _DeviceRegistration_DeleteDevice_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _DeviceRegistration_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "drs.proto",
+}
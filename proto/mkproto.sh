@@ -1,2 +1,2 @@
 #!/bin/sh
-/usr/bin/protoc drs.proto --go_out=. -I=.
\ No newline at end of file
+/usr/bin/protoc drs.proto --go_out=. --go-grpc_out=. -I=.
\ No newline at end of file
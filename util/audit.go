@@ -0,0 +1,72 @@
+package util
+
+import (
+	"strings"
+	"time"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/miekg/dns"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// The actions recorded in the audit log.
+const (
+	AuditCreateRecord = "create_record"
+	AuditUpdateRecord = "update_record"
+	AuditDeleteRecord = "delete_record"
+	AuditDelegate     = "delegate_zone"
+	AuditUndelegate   = "undelegate_zone"
+	AuditCreateToken  = "create_token"
+	AuditRevokeToken  = "revoke_token"
+	AuditRollbackZone = "rollback_zone"
+)
+
+// NewAuditEntry describes id doing action from sourceIP, changing before into
+// after. Either can be nil, for things which didn't exist before or don't
+// any more.
+func NewAuditEntry(id *Identity, sourceIP, action string, before, after proto.Message) (*pb.AuditEntry, error) {
+	entry := &pb.AuditEntry{
+		Time:     time.Now().Unix(),
+		Actor:    id.User,
+		SourceIp: sourceIP,
+		Action:   action,
+	}
+
+	for _, state := range []struct {
+		msg proto.Message
+		out *string
+	}{
+		{before, &entry.Before},
+		{after, &entry.After},
+	} {
+		if state.msg == nil {
+			continue
+		}
+		out, err := protojson.Marshal(state.msg)
+		if err != nil {
+			return nil, err
+		}
+		*state.out = string(out)
+
+		// whichever of before and after exists says what was changed
+		switch msg := state.msg.(type) {
+		case *pb.DNSRecord:
+			entry.Zone, entry.Name = auditZone(msg.GetZone()), strings.ToLower(msg.GetName())
+		case *pb.ZoneDelegation:
+			entry.Zone = auditZone(msg.GetZone())
+		case *pb.ZoneSnapshot:
+			entry.Zone = auditZone(msg.GetZone())
+		}
+	}
+	return entry, nil
+}
+
+// auditZone normalizes zones in the audit log, so they can be searched for
+// however they were written.
+func auditZone(zone string) string {
+	if zone == "" {
+		return ""
+	}
+	return strings.ToLower(dns.Fqdn(zone))
+}
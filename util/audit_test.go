@@ -0,0 +1,95 @@
+package util
+
+import (
+	"testing"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/miekg/dns"
+)
+
+func TestNewAuditEntry(t *testing.T) {
+	id := &Identity{User: "alice"}
+	record := &pb.DNSRecord{Name: "Host", Zone: "Valid.Zone", User: "alice",
+		Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 60}
+
+	entry, err := NewAuditEntry(id, "192.0.2.10", AuditDeleteRecord, record, nil)
+	if err != nil {
+		t.Fatalf("error making entry: %v", err)
+	}
+	if entry.GetActor() != "alice" || entry.GetSourceIp() != "192.0.2.10" ||
+		entry.GetAction() != AuditDeleteRecord || entry.GetTime() == 0 {
+		t.Errorf("entry = %+v", entry)
+	}
+	if entry.GetZone() != "valid.zone." || entry.GetName() != "host" {
+		t.Errorf("entry zone, name = %q, %q, want valid.zone., host", entry.GetZone(), entry.GetName())
+	}
+	if entry.GetBefore() == "" || entry.GetAfter() != "" {
+		t.Errorf("entry before, after = %q, %q", entry.GetBefore(), entry.GetAfter())
+	}
+
+	entry, err = NewAuditEntry(id, "", AuditDelegate, nil, &pb.ZoneDelegation{Zone: "valid.zone", User: "bob"})
+	if err != nil {
+		t.Fatalf("error making entry: %v", err)
+	}
+	if entry.GetZone() != "valid.zone." || entry.GetBefore() != "" || entry.GetAfter() == "" {
+		t.Errorf("delegation entry = %+v", entry)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		for i, entry := range []*pb.AuditEntry{
+			{Time: 100, Actor: "alice", Action: AuditCreateRecord, Zone: "valid.zone.", Name: "a"},
+			{Time: 200, Actor: "bob", Action: AuditCreateRecord, Zone: "valid.zone.", Name: "b"},
+			{Time: 300, Actor: "alice", Action: AuditDeleteRecord, Zone: "other.zone.", Name: "a"},
+			{Time: 400, Actor: "admin", Action: AuditDelegate, Zone: "valid.zone."},
+		} {
+			if err := store.AppendAudit(entry); err != nil {
+				t.Fatalf("%s: error appending entry %d: %v", backend, i, err)
+			}
+		}
+
+		for _, tc := range []struct {
+			query AuditQuery
+			want  []int64
+		}{
+			{AuditQuery{}, []int64{100, 200, 300, 400}},
+			{AuditQuery{User: "alice"}, []int64{100, 300}},
+			{AuditQuery{Zone: "VALID.zone"}, []int64{100, 200, 400}},
+			{AuditQuery{Zone: "valid.zone.", Name: "A"}, []int64{100}},
+			{AuditQuery{Since: 200, Until: 300}, []int64{200, 300}},
+			{AuditQuery{Limit: 2, Offset: 1}, []int64{200, 300}},
+		} {
+			entries, err := store.AuditLog(tc.query)
+			if err != nil {
+				t.Errorf("%s: %+v: error reading log: %v", backend, tc.query, err)
+				continue
+			}
+			var got []int64
+			for _, entry := range entries {
+				got = append(got, entry.GetTime())
+			}
+			if len(got) != len(tc.want) {
+				t.Errorf("%s: %+v: got entries at %v, want %v", backend, tc.query, got, tc.want)
+				continue
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("%s: %+v: got entries at %v, want %v", backend, tc.query, got, tc.want)
+					break
+				}
+			}
+		}
+
+		// the database itself refuses to change history
+		if s, ok := store.(*gormStore); ok {
+			if err := s.db.Model(&pb.AuditEntry{}).Where("actor = ?", "bob").
+				Update("actor", "mallory").Error; err == nil {
+				t.Errorf("%s: audit entry updated", backend)
+			}
+			if err := s.db.Where("actor = ?", "bob").Delete(&pb.AuditEntry{}).Error; err == nil {
+				t.Errorf("%s: audit entry deleted", backend)
+			}
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+var (
+	// ErrUnauthenticated is returned when we can't tell who made a request.
+	ErrUnauthenticated = errors.New("not authenticated")
+	// ErrForbidden is returned when someone tries to do something they
+	// aren't allowed to.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Identity is who made an API request.
+type Identity struct {
+	User string
+	// Admin may change anything
+	Admin bool
+	// ReadOnly and Patterns are the scopes of the API token used, if any.
+	// Patterns are globs matched against the full names of records.
+	ReadOnly bool
+	Patterns []string
+	// Service is set for service tokens, whose users needn't be people in
+	// the directory.
+	Service bool
+	// Groups are the DNs of the LDAP groups the user is in.
+	Groups []string
+}
+
+// Authenticator works out who made a request. It returns a nil Identity if the
+// request doesn't carry the kind of credentials it understands, so the next
+// one can have a go, and an error if the credentials are there but wrong.
+type Authenticator interface {
+	Authenticate(req *http.Request) (*Identity, error)
+}
+
+// Auth authenticates API requests and decides what they may change.
+type Auth struct {
+	Config *pb.AuthConfig
+	Store  RecordStore
+	// Authenticators are tried in order until one recognizes the request.
+	Authenticators []Authenticator
+	// Directory, if set, decides who is an admin, and who may use the API at
+	// all, whichever way they were authenticated.
+	Directory *LDAPDirectory
+}
+
+// In returns a copy of a which reads delegations and records through tx, so
+// changes made in a Transaction can be checked in it.
+func (a *Auth) In(tx RecordStore) *Auth {
+	inTx := *a
+	inTx.Store = tx
+	return &inTx
+}
+
+// NewAuth returns an Auth with every authenticator enabled in serverConf.
+func NewAuth(serverConf *pb.ServerConfig, store RecordStore) (*Auth, error) {
+	conf := serverConf.GetAuthConf()
+	a := &Auth{Config: conf, Store: store}
+	a.Authenticators = append(a.Authenticators, TokenAuthenticator{Store: store})
+	if serverConf.GetLdapConf().GetUrl() != "" {
+		a.Directory = &LDAPDirectory{Config: serverConf.GetLdapConf()}
+		a.Authenticators = append(a.Authenticators, a.Directory)
+	}
+	if conf.GetOidcIssuer() != "" {
+		oidc, err := NewOIDCAuthenticator(context.Background(), conf)
+		if err != nil {
+			return nil, err
+		}
+		a.Authenticators = append(a.Authenticators, oidc)
+	}
+	if conf.GetProxyUserHeader() != "" {
+		a.Authenticators = append(a.Authenticators, ProxyAuthenticator{
+			Header:  conf.GetProxyUserHeader(),
+			Trusted: conf.GetTrustedProxies(),
+		})
+	}
+	return a, nil
+}
+
+// Authenticate returns who made req, or ErrUnauthenticated if none of the
+// authenticators know. Users the directory doesn't let in get ErrForbidden.
+func (a *Auth) Authenticate(req *http.Request) (*Identity, error) {
+	for _, authenticator := range a.Authenticators {
+		id, err := authenticator.Authenticate(req)
+		if err != nil {
+			return nil, err
+		}
+		if id == nil {
+			continue
+		}
+		if a.Directory != nil && authenticator != Authenticator(a.Directory) && !id.Service {
+			if err := a.Directory.Apply(id); err != nil {
+				return nil, err
+			}
+		}
+		for _, admin := range a.Config.GetAdminUsers() {
+			if id.User == admin {
+				id.Admin = true
+			}
+		}
+		return id, nil
+	}
+	return nil, ErrUnauthenticated
+}
+
+// CanModify returns ErrForbidden unless id may create, change or delete
+// record. That is admins, the owner of the record, and anyone the zone of the
+// record has been delegated to, as long as it is in the scopes of id.
+func (a *Auth) CanModify(id *Identity, record *pb.DNSRecord) error {
+	if id.ReadOnly || !id.InScope(record) {
+		return ErrForbidden
+	}
+	if id.Admin || record.GetUser() == id.User {
+		return nil
+	}
+	delegated, err := a.Store.IsDelegated(record.GetZone(), id.User)
+	if err != nil {
+		return err
+	}
+	if !delegated {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// CanCreate is CanModify for new records, which also stops users adding
+// records to a name someone else already has records at.
+func (a *Auth) CanCreate(id *Identity, record *pb.DNSRecord) error {
+	if id.ReadOnly || !id.InScope(record) {
+		return ErrForbidden
+	}
+	if id.Admin {
+		return nil
+	}
+	delegated, err := a.Store.IsDelegated(record.GetZone(), id.User)
+	if err != nil || delegated {
+		return err
+	}
+	if record.GetUser() != id.User {
+		return ErrForbidden
+	}
+
+	existing, err := a.Store.List(record.GetZone(), record.GetName())
+	if err != nil {
+		return err
+	}
+	for _, r := range existing {
+		if r.GetUser() != id.User {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
+
+// CanManageZone returns ErrForbidden unless id may change every record in
+// zone at once, which takes being an admin or having the zone delegated, and
+// not using a scoped token.
+func (a *Auth) CanManageZone(id *Identity, zone string) error {
+	if !id.Unscoped() {
+		return ErrForbidden
+	}
+	if id.Admin {
+		return nil
+	}
+	delegated, err := a.Store.IsDelegated(zone, id.User)
+	if err != nil {
+		return err
+	}
+	if !delegated {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// CheckServiceUser returns a FieldErrors if user is in the directory, so can't
+// be given service tokens. Those skip the directory, and the records of their
+// users are kept when it syncs, so a directory account with one would keep
+// working after it was removed.
+func (a *Auth) CheckServiceUser(user string) error {
+	if a.Directory == nil {
+		return nil
+	}
+	// the cache could be out of date, and this isn't done often
+	entry, err := a.Directory.search(user)
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		return FieldErrors{{Field: "user", Message: "is in the directory, service tokens are for other accounts"}}
+	}
+	return nil
+}
+
+// ClientIP returns the address req came from, looking past our trusted
+// proxies.
+func (a *Auth) ClientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	forwarded := req.Header.Get("X-Forwarded-For")
+	if forwarded == "" || !prefixesContain(a.Config.GetTrustedProxies(), net.ParseIP(host)) {
+		return host
+	}
+	// the proxy adds whoever connected to it to the end
+	hops := strings.Split(forwarded, ",")
+	return strings.TrimSpace(hops[len(hops)-1])
+}
+
+// ProxyAuthenticator believes the user named in a header set by a reverse
+// proxy which has already authenticated them, as long as the request came
+// from one of the Trusted prefixes.
+type ProxyAuthenticator struct {
+	Header  string
+	Trusted []string
+}
+
+func (p ProxyAuthenticator) Authenticate(req *http.Request) (*Identity, error) {
+	user := req.Header.Get(p.Header)
+	if user == "" {
+		return nil, nil
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	if !prefixesContain(p.Trusted, net.ParseIP(host)) {
+		return nil, ErrUnauthenticated
+	}
+	return &Identity{User: user}, nil
+}
@@ -0,0 +1,180 @@
+package util
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/miekg/dns"
+)
+
+func TestProxyAuthenticator(t *testing.T) {
+	auth, err := NewAuth(&pb.ServerConfig{AuthConf: &pb.AuthConfig{
+		ProxyUserHeader: "X-Forwarded-User",
+		TrustedProxies:  []string{"127.0.0.0/8"},
+		AdminUsers:      []string{"root"},
+	}}, NewMemoryStore())
+	if err != nil {
+		t.Fatalf("error setting up auth: %v", err)
+	}
+
+	for _, tc := range []struct {
+		remoteAddr, user string
+		want             *Identity
+		err              error
+	}{
+		{"127.0.0.1:1234", "alice", &Identity{User: "alice"}, nil},
+		{"127.0.0.1:1234", "root", &Identity{User: "root", Admin: true}, nil},
+		{"192.0.2.1:1234", "alice", nil, ErrUnauthenticated},
+		{"127.0.0.1:1234", "", nil, ErrUnauthenticated},
+	} {
+		req := httptest.NewRequest("GET", "/v1/listDNSRecords", nil)
+		req.RemoteAddr = tc.remoteAddr
+		if tc.user != "" {
+			req.Header.Set("X-Forwarded-User", tc.user)
+		}
+
+		id, err := auth.Authenticate(req)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s from %s: err = %v, want %v", tc.user, tc.remoteAddr, err, tc.err)
+			continue
+		}
+		if tc.want != nil && (id.User != tc.want.User || id.Admin != tc.want.Admin) {
+			t.Errorf("%s from %s: identity = %+v, want %+v", tc.user, tc.remoteAddr, id, tc.want)
+		}
+	}
+}
+
+func TestProxyAuthenticatorOff(t *testing.T) {
+	// the default trusts loopback, but believes no header until one is set
+	auth, err := NewAuth(&pb.ServerConfig{AuthConf: DefaultConfig.GetAuthConf()}, NewMemoryStore())
+	if err != nil {
+		t.Fatalf("error setting up auth: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/v1/listDNSRecords", nil)
+	req.RemoteAddr = "127.0.0.1:1234"
+	req.Header.Set("X-Forwarded-User", "alice")
+	if id, err := auth.Authenticate(req); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("X-Forwarded-User by default: identity = %+v, err = %v, want %v", id, err, ErrUnauthenticated)
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	store := NewMemoryStore()
+	auth, err := NewAuth(&pb.ServerConfig{}, store)
+	if err != nil {
+		t.Fatalf("error setting up auth: %v", err)
+	}
+	if err := store.Delegate("delegated.zone.", "bob"); err != nil {
+		t.Fatalf("error delegating zone: %v", err)
+	}
+
+	alices := &pb.DNSRecord{Name: "host", Zone: "valid.zone.", User: "alice",
+		Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 60}
+	delegated := &pb.DNSRecord{Name: "host", Zone: "DELEGATED.zone", User: "alice",
+		Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 60}
+	for _, record := range []*pb.DNSRecord{alices, delegated} {
+		if err := store.Create(record); err != nil {
+			t.Fatalf("error creating record: %v", err)
+		}
+	}
+
+	alice := &Identity{User: "alice"}
+	bob := &Identity{User: "bob"}
+	admin := &Identity{User: "root", Admin: true}
+	for _, tc := range []struct {
+		desc   string
+		id     *Identity
+		record *pb.DNSRecord
+		create bool
+		err    error
+	}{
+		{"owner", alice, alices, false, nil},
+		{"someone else", bob, alices, false, ErrForbidden},
+		{"admin", admin, alices, false, nil},
+		{"delegated zone", bob, delegated, false, nil},
+		{"new record at own name", alice, &pb.DNSRecord{Name: "host", Zone: "valid.zone.",
+			User: "alice", Type: uint32(dns.TypeAAAA), Value: "2001:db8::1"}, true, nil},
+		{"new record at someone else's name", bob, &pb.DNSRecord{Name: "host", Zone: "valid.zone.",
+			User: "bob", Type: uint32(dns.TypeAAAA), Value: "2001:db8::1"}, true, ErrForbidden},
+		{"new record for someone else", bob, &pb.DNSRecord{Name: "new", Zone: "valid.zone.",
+			User: "alice", Type: uint32(dns.TypeA), Value: "192.0.2.2"}, true, ErrForbidden},
+		{"new record in delegated zone", bob, &pb.DNSRecord{Name: "host", Zone: "delegated.zone.",
+			User: "bob", Type: uint32(dns.TypeAAAA), Value: "2001:db8::1"}, true, nil},
+	} {
+		var err error
+		if tc.create {
+			err = auth.CanCreate(tc.id, tc.record)
+		} else {
+			err = auth.CanModify(tc.id, tc.record)
+		}
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: err = %v, want %v", tc.desc, err, tc.err)
+		}
+	}
+}
+
+func TestTokenAuthenticator(t *testing.T) {
+	store := NewMemoryStore()
+	auth, err := NewAuth(&pb.ServerConfig{AuthConf: &pb.AuthConfig{AdminUsers: []string{"root"}}}, store)
+	if err != nil {
+		t.Fatalf("error setting up auth: %v", err)
+	}
+
+	newToken := func(info *pb.APIToken) string {
+		t.Helper()
+		token, err := NewToken(info)
+		if err != nil {
+			t.Fatalf("error making token: %v", err)
+		}
+		if err := store.CreateToken(info); err != nil {
+			t.Fatalf("error storing token: %v", err)
+		}
+		return token
+	}
+	write := newToken(&pb.APIToken{User: "alice", Write: true})
+	read := newToken(&pb.APIToken{User: "alice"})
+	scoped := newToken(&pb.APIToken{User: "root", Write: true, Patterns: "*.lab.valid.zone."})
+	expired := newToken(&pb.APIToken{User: "alice", ExpiresAt: time.Now().Add(-time.Minute).Unix()})
+	revoked := newToken(&pb.APIToken{User: "alice"})
+	tokens, _ := store.Tokens("alice")
+	if err := store.RevokeToken(tokens[len(tokens)-1].GetId(), time.Now().Unix()); err != nil {
+		t.Fatalf("error revoking token: %v", err)
+	}
+
+	authenticate := func(token string) (*Identity, error) {
+		req := httptest.NewRequest("GET", "/v1/listDNSRecords", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return auth.Authenticate(req)
+	}
+	for _, token := range []string{expired, revoked, "drs_made_up"} {
+		if _, err := authenticate(token); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("token %s: err = %v, want ErrUnauthenticated", token, err)
+		}
+	}
+
+	host := &pb.DNSRecord{Name: "host", Zone: "valid.zone.", User: "alice",
+		Type: uint32(dns.TypeA), Value: "192.0.2.1"}
+	labHost := &pb.DNSRecord{Name: "host.lab", Zone: "valid.zone.", User: "alice",
+		Type: uint32(dns.TypeA), Value: "192.0.2.1"}
+	for _, tc := range []struct {
+		token  string
+		record *pb.DNSRecord
+		err    error
+	}{
+		{write, host, nil},
+		{read, host, ErrForbidden},
+		{scoped, labHost, nil},
+		{scoped, host, ErrForbidden},
+	} {
+		id, err := authenticate(tc.token)
+		if err != nil {
+			t.Fatalf("error authenticating: %v", err)
+		}
+		if err := auth.CanModify(id, tc.record); !errors.Is(err, tc.err) {
+			t.Errorf("%+v changing %s: err = %v, want %v", id, tc.record.GetName(), err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package util
+
+import (
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gidoBOSSftw5731/log"
+	"github.com/miekg/dns"
+)
+
+// handleChaos answers the CHAOS class TXT queries used to find out which
+// server answered and what it is running (RFC 4892).
+func (h DNSHandler) handleChaos(resp dns.ResponseWriter, req, m *dns.Msg) {
+	q := req.Question[0]
+	conf := h.Config.GetDnsConf()
+
+	var txt string
+	switch strings.ToLower(q.Name) {
+	case "version.bind.", "version.server.":
+		if conf.GetHideVersion() {
+			m.SetRcode(req, dns.RcodeRefused)
+			h.writeMsg(resp, req, m)
+			return
+		}
+		txt = h.version()
+	case "hostname.bind.", "id.server.":
+		if conf.GetHideIdentity() {
+			m.SetRcode(req, dns.RcodeRefused)
+			h.writeMsg(resp, req, m)
+			return
+		}
+		txt = h.identity()
+	default:
+		log.Errorf("Unknown CHAOS query for %s", q.Name)
+		m.SetRcode(req, dns.RcodeRefused)
+		h.writeMsg(resp, req, m)
+		return
+	}
+
+	if q.Qtype == dns.TypeTXT || q.Qtype == dns.TypeANY {
+		m.Answer = append(m.Answer, &dns.TXT{
+			Hdr: dns.RR_Header{
+				Name:   q.Name,
+				Rrtype: dns.TypeTXT,
+				Class:  dns.ClassCHAOS,
+				Ttl:    0,
+			},
+			Txt: []string{txt},
+		})
+	}
+	h.writeMsg(resp, req, m)
+}
+
+// identity returns the name this instance goes by, defaulting to the hostname
+// of the machine.
+func (h DNSHandler) identity() string {
+	if id := h.Config.GetDnsConf().GetIdentity(); id != "" {
+		return id
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		log.Errorln("Error getting hostname:", err)
+	}
+	return hostname
+}
+
+// nsid returns the name server identifier for NSID, or "" if we shouldn't
+// send one. Hiding the identity only hides it when it would stand in for an
+// NSID the operator hasn't set.
+func (h DNSHandler) nsid() string {
+	conf := h.Config.GetDnsConf()
+	switch {
+	case conf.GetNsid() != "":
+		return conf.GetNsid()
+	case conf.GetHideIdentity():
+		return ""
+	}
+	return h.identity()
+}
+
+// version returns the configured version string, or the version of the
+// binary if there isn't one.
+func (h DNSHandler) version() string {
+	if v := h.Config.GetDnsConf().GetVersion(); v != "" {
+		return v
+	}
+	v := "DeviceRegistrationSystem"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		v += " " + info.Main.Version
+	}
+	return v
+}
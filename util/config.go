@@ -2,9 +2,13 @@ package util
 
 import (
 	"database/sql"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/glebarez/sqlite"
 	"github.com/subosito/gotenv"
 	"github.com/uptrace/bun/driver/pgdriver"
 	"gorm.io/driver/postgres"
@@ -18,15 +22,47 @@ var (
 			Username:     "DRSUser",
 			Password:     "BEAPROANDCHANGEME!",
 			DatabaseName: "DRS",
+			SqlitePath:   "drs.db",
 		},
 		DnsConf: &pb.DNSConfig{
-			RootZones:  []string{"cshtest.clickable.systems."},
-			ListenPort: ":5353",
-			NsAddr:     "cshtestns.clickable.systems.",
-			AdminEmail: "hostmaster.csh.rit.edu.",
-			AxfrTo:     []string{"127.0.0.1"},
+			RootZones:     []string{"cshtest.clickable.systems."},
+			ListenPort:    ":5353",
+			NsAddr:        "cshtestns.clickable.systems.",
+			AdminEmail:    "hostmaster.csh.rit.edu.",
+			AxfrTo:        []string{"127.0.0.1"},
+			MaxUdpSize:    1232,
+			TlsListenPort: ":853",
+			InternalPrefixes: []string{"10.0.0.0/8", "172.16.0.0/12",
+				"192.168.0.0/16", "127.0.0.0/8", "fc00::/7", "::1/128"},
+			Rrl: &pb.RRLConfig{
+				ResponsesPerSecond: 20,
+				Window:             15,
+				Slip:               2,
+				Ipv4PrefixLength:   24,
+				Ipv6PrefixLength:   56,
+				ExemptPrefixes:     []string{"127.0.0.0/8", "::1/128"},
+			},
 		},
-		ListenAddr: ":8090",
+		AuthConf: &pb.AuthConfig{
+			TrustedProxies: []string{"127.0.0.0/8", "::1/128"},
+			OidcUserClaim:  "preferred_username",
+		},
+		LdapConf: &pb.LDAPConfig{
+			UserFilter:   "(uid=%s)",
+			GroupAttr:    "memberOf",
+			SyncInterval: 3600,
+		},
+		QuotaConf: &pb.QuotaConfig{
+			DefaultQuota: &pb.Quota{
+				MaxDevices:   20,
+				MaxRecords:   100,
+				MaxWildcards: 2,
+			},
+		},
+		ListenAddr:      ":8090",
+		GrpcListenAddr:  "localhost:8091",
+		DebugListenAddr: "localhost:8092",
+		StorageBackend:  StoragePostgres,
 	}
 )
 
@@ -43,22 +79,114 @@ func ReadConf(defaultConfig *pb.ServerConfig, envPath ...string) *pb.ServerConfi
 	conf := defaultConfig
 	// Read config from environment variables and replace default values if necessary
 	for env, val := range map[string]*string{
-		"DB_HOSTNAME":      &conf.DBConf.Hostname,
-		"DB_USERNAME":      &conf.DBConf.Username,
-		"DB_PASSWORD":      &conf.DBConf.Password,
-		"LISTEN_ADDR":      &conf.ListenAddr,
-		"DB_DATABASE_NAME": &conf.DBConf.DatabaseName,
-		"DNS_ROOT_ZONE":    &conf.DnsConf.RootZones[0],
-		"LISTEN_PORT":      &conf.DnsConf.ListenPort,
-		"NS_ADDR":          &conf.DnsConf.NsAddr,
-		"ADMIN_EMAIL":      &conf.DnsConf.AdminEmail,
-		"AXFR_TO":          &conf.DnsConf.AxfrTo[0],
+		"DB_HOSTNAME":            &conf.DBConf.Hostname,
+		"DB_USERNAME":            &conf.DBConf.Username,
+		"DB_PASSWORD":            &conf.DBConf.Password,
+		"LISTEN_ADDR":            &conf.ListenAddr,
+		"GRPC_LISTEN_ADDR":       &conf.GrpcListenAddr,
+		"DEBUG_LISTEN_ADDR":      &conf.DebugListenAddr,
+		"GRPC_TLS_CERT":          &conf.GrpcTlsCertFile,
+		"GRPC_TLS_KEY":           &conf.GrpcTlsKeyFile,
+		"DB_DATABASE_NAME":       &conf.DBConf.DatabaseName,
+		"DB_SQLITE_PATH":         &conf.DBConf.SqlitePath,
+		"STORAGE_BACKEND":        &conf.StorageBackend,
+		"DNS_ROOT_ZONE":          &conf.DnsConf.RootZones[0],
+		"LISTEN_PORT":            &conf.DnsConf.ListenPort,
+		"NS_ADDR":                &conf.DnsConf.NsAddr,
+		"ADMIN_EMAIL":            &conf.DnsConf.AdminEmail,
+		"AXFR_TO":                &conf.DnsConf.AxfrTo[0],
+		"DNS_TLS_LISTEN_PORT":    &conf.DnsConf.TlsListenPort,
+		"DNS_TLS_CERT":           &conf.DnsConf.TlsCertFile,
+		"DNS_TLS_KEY":            &conf.DnsConf.TlsKeyFile,
+		"DNS_DOH_LISTEN_ADDR":    &conf.DnsConf.DohListenAddr,
+		"DNS_COOKIE_SECRET":      &conf.DnsConf.CookieSecret,
+		"DNS_IDENTITY":           &conf.DnsConf.Identity,
+		"DNS_VERSION":            &conf.DnsConf.Version,
+		"DNS_NSID":               &conf.DnsConf.Nsid,
+		"AUTH_PROXY_USER_HEADER": &conf.AuthConf.ProxyUserHeader,
+		"OIDC_ISSUER":            &conf.AuthConf.OidcIssuer,
+		"OIDC_AUDIENCE":          &conf.AuthConf.OidcAudience,
+		"OIDC_USER_CLAIM":        &conf.AuthConf.OidcUserClaim,
+		"LDAP_URL":               &conf.LdapConf.Url,
+		"LDAP_CA_FILE":           &conf.LdapConf.CaFile,
+		"LDAP_BIND_DN":           &conf.LdapConf.BindDn,
+		"LDAP_BIND_PASSWORD":     &conf.LdapConf.BindPassword,
+		"LDAP_USER_BASE_DN":      &conf.LdapConf.UserBaseDn,
+		"LDAP_USER_FILTER":       &conf.LdapConf.UserFilter,
+		"LDAP_GROUP_ATTR":        &conf.LdapConf.GroupAttr,
+		"LDAP_ADMIN_GROUP_DN":    &conf.LdapConf.AdminGroupDn,
+		"LDAP_MEMBER_GROUP_DN":   &conf.LdapConf.MemberGroupDn,
 	} {
 		if os.Getenv(env) != "" {
 			*val = os.Getenv(env)
 		}
 	}
 
+	// do the same as above for non-string fields
+	for env, val := range map[string]*uint32{
+		"DNS_MAX_UDP_SIZE":         &conf.DnsConf.MaxUdpSize,
+		"RRL_RESPONSES_PER_SECOND": &conf.DnsConf.Rrl.ResponsesPerSecond,
+		"RRL_NODATA_PER_SECOND":    &conf.DnsConf.Rrl.NodataPerSecond,
+		"RRL_NXDOMAINS_PER_SECOND": &conf.DnsConf.Rrl.NxdomainsPerSecond,
+		"RRL_ERRORS_PER_SECOND":    &conf.DnsConf.Rrl.ErrorsPerSecond,
+		"RRL_WINDOW":               &conf.DnsConf.Rrl.Window,
+		"RRL_SLIP":                 &conf.DnsConf.Rrl.Slip,
+		"LDAP_SYNC_INTERVAL":       &conf.LdapConf.SyncInterval,
+		"QUOTA_MAX_DEVICES":        &conf.QuotaConf.DefaultQuota.MaxDevices,
+		"QUOTA_MAX_RECORDS":        &conf.QuotaConf.DefaultQuota.MaxRecords,
+		"QUOTA_MAX_WILDCARDS":      &conf.QuotaConf.DefaultQuota.MaxWildcards,
+	} {
+		if os.Getenv(env) != "" {
+			i, err := strconv.Atoi(os.Getenv(env))
+			if err != nil {
+				panic(err)
+			}
+			*val = uint32(i)
+		}
+	}
+
+	for env, val := range map[string]*bool{
+		"DNS_REQUIRE_SERVER_COOKIE": &conf.DnsConf.RequireServerCookie,
+		"DNS_HIDE_IDENTITY":         &conf.DnsConf.HideIdentity,
+		"DNS_HIDE_VERSION":          &conf.DnsConf.HideVersion,
+		"LDAP_START_TLS":            &conf.LdapConf.StartTls,
+	} {
+		if os.Getenv(env) != "" {
+			b, err := strconv.ParseBool(os.Getenv(env))
+			if err != nil {
+				panic(err)
+			}
+			*val = b
+		}
+	}
+
+	// lists are comma separated
+	for env, val := range map[string]*[]string{
+		"DNS_INTERNAL_PREFIXES":  &conf.DnsConf.InternalPrefixes,
+		"DNS_TRUSTED_FORWARDERS": &conf.DnsConf.TrustedForwarders,
+		"RRL_EXEMPT_PREFIXES":    &conf.DnsConf.Rrl.ExemptPrefixes,
+		"AUTH_TRUSTED_PROXIES":   &conf.AuthConf.TrustedProxies,
+		"AUTH_ADMIN_USERS":       &conf.AuthConf.AdminUsers,
+	} {
+		if os.Getenv(env) != "" {
+			*val = strings.Split(os.Getenv(env), ",")
+		}
+	}
+
+	// quotas are semicolon separated, as group DNs have commas in them
+	for env, val := range map[string]*map[string]*pb.Quota{
+		"QUOTA_USERS":  &conf.QuotaConf.Users,
+		"QUOTA_GROUPS": &conf.QuotaConf.Groups,
+	} {
+		if os.Getenv(env) != "" {
+			quotas, err := ParseQuotas(os.Getenv(env))
+			if err != nil {
+				panic(err)
+			}
+			*val = quotas
+		}
+	}
+
 	// Check zones for trailing period
 	for _, zone := range append([]string{
 		conf.DnsConf.GetNsAddr(),
@@ -72,33 +200,45 @@ func ReadConf(defaultConfig *pb.ServerConfig, envPath ...string) *pb.ServerConfi
 	return conf
 }
 
-// confDB configures the database with a table for each of the structs used.
+// OpenDB opens the SQL database of the storage backend in conf, without
+// touching its schema.
+func OpenDB(conf *pb.ServerConfig) (*gorm.DB, error) {
+	switch conf.GetStorageBackend() {
+	case StoragePostgres, "":
+		dbConf := conf.GetDBConf()
+		// Create the backend sql driver db for gorm to use.
+		// this is the bun package but I can not be bothered to configure this again.
+		// It just creates the backend database anyway, so it does not matter.
+		sqldb := sql.OpenDB(pgdriver.NewConnector(
+			pgdriver.WithAddr(dbConf.GetHostname()),
+			pgdriver.WithUser(dbConf.GetUsername()),
+			pgdriver.WithPassword(dbConf.GetPassword()),
+			pgdriver.WithDatabase(dbConf.GetDatabaseName()),
+		))
+
+		return gorm.Open(postgres.New(postgres.Config{
+			Conn: sqldb,
+		}), &gorm.Config{TranslateError: true})
+
+	case StorageSQLite:
+		return gorm.Open(sqlite.Open(conf.GetDBConf().GetSqlitePath()),
+			&gorm.Config{TranslateError: true})
+	}
+	return nil, fmt.Errorf("storage backend %q has no database", conf.GetStorageBackend())
+}
+
+// confDB opens the database and brings its schema up to date by running any
+// migrations it hasn't had yet.
 // All structs being used should originate from the pb package for standard access.
-func ConfDB(dbConf *pb.DatabaseConfig) (*gorm.DB, error) {
-	// Create the backend sql driver db for gorm to use.
-	// this is the bun package but I can not be bothered to configure this again.
-	// It just creates the backend database anyway, so it does not matter.
-	sqldb := sql.OpenDB(pgdriver.NewConnector(
-		pgdriver.WithAddr(dbConf.GetHostname()),
-		pgdriver.WithUser(dbConf.GetUsername()),
-		pgdriver.WithPassword(dbConf.GetPassword()),
-		pgdriver.WithDatabase(dbConf.GetDatabaseName()),
-	))
-
-	db, err := gorm.Open(postgres.New(postgres.Config{
-		Conn: sqldb,
-	}))
+func ConfDB(conf *pb.ServerConfig) (*gorm.DB, error) {
+	db, err := OpenDB(conf)
 	if err != nil {
 		return nil, err
 	}
 
-	// create tables if they don't exist
-	for _, v := range []interface{}{&pb.DNSRecord{}} {
-		err = db.AutoMigrate(v)
-		if err != nil {
-			return nil, err
-			//log.Errorln("Error migrating table: ", err)
-		}
+	err = Migrate(db)
+	if err != nil {
+		return nil, err
 	}
 
 	return db, nil
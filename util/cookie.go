@@ -0,0 +1,134 @@
+package util
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gidoBOSSftw5731/log"
+	"github.com/miekg/dns"
+)
+
+const (
+	clientCookieLen = 8
+	// server cookies are laid out as in RFC 9018: version, three reserved
+	// bytes, a timestamp and an 8 byte hash
+	serverCookieLen     = 16
+	serverCookieVersion = 1
+	// how long a server cookie stays valid, and how far into the future its
+	// timestamp may be to allow for clock skew between instances
+	serverCookieLifetime = time.Hour
+	serverCookieSkew     = 5 * time.Minute
+)
+
+// dnsCookie is the state of the COOKIE option (RFC 7873) of a query.
+type dnsCookie struct {
+	// present is set if the query had a COOKIE option at all
+	present bool
+	// malformed is set if the option had the wrong length
+	malformed bool
+	client    []byte
+	// valid is set if the server cookie is one we made for this client
+	valid bool
+}
+
+// NewCookieSecret decodes the hex encoded secret used to make server cookies,
+// or makes a random one if secret is empty.
+func NewCookieSecret(secret string) ([]byte, error) {
+	if secret != "" {
+		key, err := hex.DecodeString(secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cookie secret: %w", err)
+		}
+		if len(key) < 16 {
+			return nil, fmt.Errorf("cookie secret must be at least 16 bytes")
+		}
+		return key, nil
+	}
+
+	log.Infoln("No cookie secret configured, generating a random one")
+	key := make([]byte, 16)
+	_, err := rand.Read(key)
+	return key, err
+}
+
+// checkCookie parses and validates the COOKIE option of req. Cookies are
+// ignored entirely if we have no secret to make them with.
+func (h DNSHandler) checkCookie(resp dns.ResponseWriter, req *dns.Msg) dnsCookie {
+	var c dnsCookie
+	opt := req.IsEdns0()
+	if h.CookieSecret == nil || opt == nil {
+		return c
+	}
+
+	for _, o := range opt.Option {
+		e, ok := o.(*dns.EDNS0_COOKIE)
+		if !ok {
+			continue
+		}
+		c.present = true
+
+		cookie, err := hex.DecodeString(e.Cookie)
+		// RFC 7873 section 5.2.2, the client cookie is always 8 bytes and the
+		// server cookie, if there is one, is between 8 and 32
+		if err != nil || len(cookie) < clientCookieLen ||
+			(len(cookie) > clientCookieLen && len(cookie) < clientCookieLen+8) ||
+			len(cookie) > clientCookieLen+32 {
+			c.malformed = true
+			return c
+		}
+		c.client = cookie[:clientCookieLen]
+
+		server := cookie[clientCookieLen:]
+		if len(server) != serverCookieLen || server[0] != serverCookieVersion {
+			return c
+		}
+		ts := time.Unix(int64(binary.BigEndian.Uint32(server[4:8])), 0)
+		if time.Since(ts) > serverCookieLifetime || time.Until(ts) > serverCookieSkew {
+			return c
+		}
+		c.valid = hmac.Equal(server, h.serverCookie(c.client, ts,
+			addrIP(resp.RemoteAddr())))
+		return c
+	}
+	return c
+}
+
+// serverCookie makes the server cookie for a client cookie, time and client
+// address. The hash is a truncated HMAC-SHA256 rather than the SipHash of
+// RFC 9018, so it only has to agree between instances of this server.
+func (h DNSHandler) serverCookie(client []byte, ts time.Time, ip net.IP) []byte {
+	cookie := make([]byte, 8, serverCookieLen)
+	cookie[0] = serverCookieVersion
+	binary.BigEndian.PutUint32(cookie[4:8], uint32(ts.Unix()))
+
+	mac := hmac.New(sha256.New, h.CookieSecret)
+	mac.Write(client)
+	mac.Write(cookie)
+	mac.Write(ip.To16())
+	return append(cookie, mac.Sum(nil)[:serverCookieLen-8]...)
+}
+
+// cookieOption returns a COOKIE option echoing the client cookie along with
+// a fresh server cookie.
+func (h DNSHandler) cookieOption(resp dns.ResponseWriter, c dnsCookie) *dns.EDNS0_COOKIE {
+	server := h.serverCookie(c.client, time.Now(), addrIP(resp.RemoteAddr()))
+	return &dns.EDNS0_COOKIE{
+		Code:   dns.EDNS0COOKIE,
+		Cookie: hex.EncodeToString(bytes.Join([][]byte{c.client, server}, nil)),
+	}
+}
+
+// badCookie returns a BADCOOKIE reply to req, which tells the client to retry
+// with the server cookie it carries.
+func badCookie(req *dns.Msg) *dns.Msg {
+	m := new(dns.Msg)
+	m.SetRcode(req, dns.RcodeBadCookie)
+	return m
+}
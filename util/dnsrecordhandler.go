@@ -19,15 +19,21 @@ var (
 )
 
 func (h DNSHandler) genSOA(resp dns.ResponseWriter, req *dns.Msg,
-	name string) dns.RR {
-	// check the db for the SOA record
-	var pbrr pb.DNSRecord
-	h.DB.Where("LOWER(name) = LOWER(?) AND type = ?", name, dns.TypeSOA).First(&pbrr)
+	zone, name string) dns.RR {
+	// check the store for the SOA record
+	pbrr := &pb.DNSRecord{}
+	records, err := h.Store.Lookup(zone, name, "")
+	if err != nil {
+		log.Errorln("Error looking up SOA record:", err)
+	}
+	if soa := ofType(records, dns.TypeSOA); len(soa) > 0 {
+		pbrr = soa[0]
+	}
 
 	// Set the SOA record
 	rr := new(dns.SOA)
 	rr.Hdr = dns.RR_Header{
-		Name:   processFullName(&pbrr),
+		Name:   processFullName(pbrr),
 		Rrtype: dns.TypeSOA,
 		Class:  dns.ClassINET,
 		Ttl:    pbrr.GetTtl(),
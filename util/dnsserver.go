@@ -1,17 +1,27 @@
 package util
 
 import (
+	"encoding/hex"
+	"net"
 	"strings"
 
 	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
 	"github.com/gidoBOSSftw5731/log"
 	"github.com/miekg/dns"
-	"gorm.io/gorm"
 )
 
+// anyHINFOTTL is the TTL of the HINFO record synthesized for ANY queries,
+// as recommended by RFC 8482 section 4.2.
+const anyHINFOTTL = 86400
+
 type DNSHandler struct {
 	Config *pb.ServerConfig
-	DB     *gorm.DB
+	// Store holds the records we answer from.
+	Store RecordStore
+	// RRL rate limits UDP responses, it is nil if rate limiting is off.
+	RRL *RateLimiter
+	// CookieSecret is the key for DNS cookies, which are off if it is nil.
+	CookieSecret []byte
 }
 
 func (h DNSHandler) HandleDNS(resp dns.ResponseWriter, req *dns.Msg) {
@@ -27,12 +37,42 @@ func (h DNSHandler) HandleDNS(resp dns.ResponseWriter, req *dns.Msg) {
 	q := req.Question[0]
 	log.Tracef("%#v", q)
 
+	// We only speak EDNS version 0, RFC 6891 section 6.1.3
+	if opt := req.IsEdns0(); opt != nil && opt.Version() != 0 {
+		log.Errorf("Unsupported EDNS version %d", opt.Version())
+		m.SetRcode(req, dns.RcodeBadVers)
+		h.writeMsg(resp, req, m)
+		return
+	}
+
+	// RFC 7873 section 5.2, broken cookies are a FORMERR, and if we insist on
+	// cookies, UDP clients have to come back with a valid one
+	cookie := h.checkCookie(resp, req)
+	if cookie.malformed {
+		log.Errorln("Malformed DNS cookie from", resp.RemoteAddr())
+		m.SetRcode(req, dns.RcodeFormatError)
+		h.writeMsg(resp, req, m)
+		return
+	}
+	if _, udp := resp.RemoteAddr().(*net.UDPAddr); udp && cookie.present &&
+		!cookie.valid && h.Config.GetDnsConf().GetRequireServerCookie() {
+		h.writeMsg(resp, req, badCookie(req))
+		return
+	}
+
+	// CHAOS class queries are about the server, not about any of our zones
+	if q.Qclass == dns.ClassCHAOS {
+		h.handleChaos(resp, req, m)
+		return
+	}
+
 	// Check that the name being requested is part of a zone we manage
-	var name string
-	for _, zone := range h.Config.GetDnsConf().GetRootZones() {
-		if dns.IsSubDomain(zone, q.Name) {
-			log.Traceln("Found zone", zone, "for name", q.Name)
-			name = q.Name[:len(q.Name)-len(zone)-1]
+	var name, zone string
+	for _, z := range h.Config.GetDnsConf().GetRootZones() {
+		if dns.IsSubDomain(z, q.Name) {
+			log.Traceln("Found zone", z, "for name", q.Name)
+			zone = z
+			name = strings.TrimSuffix(q.Name[:len(q.Name)-len(z)], ".")
 			if name == "" {
 				name = "@"
 			}
@@ -44,64 +84,41 @@ func (h DNSHandler) HandleDNS(resp dns.ResponseWriter, req *dns.Msg) {
 		log.Errorf("Requested name %s (%v) is not part of a zone we manage",
 			name, q.Name)
 		m.SetRcode(req, dns.RcodeNameError)
-		err := resp.WriteMsg(m)
-		if err != nil {
-			log.Errorf("Error writing response: %s", err)
-		}
+		h.writeMsg(resp, req, m)
 		return
 	}
 
-	// check if the requested name is in the database at all, otherwise return NXDOMAIN
-	// This is designed to assume that it is more efficient to query the database twice,
-	// once for "the first record for this name" and once for "all records for this name
-	// and type," as opposed to querying the database once for "all records for this name"
-	// and then filtering out the ones that don't match the type.
-	// The reasoning behind this is because it's almost certainly faster for the database to
-	// look at the index twice than it is for us to iterate over an entire slice of all records
-	// for a name.
-	result := h.DB.Where("LOWER(name) = LOWER(?) AND LOWER(zone) = LOWER (?)", name, req.Question[0].Name[len(name)+1:]).Take(&pb.DNSRecord{})
-	switch result.Error {
-	case nil:
-		// do nothing
-	case gorm.ErrRecordNotFound:
+	// records tagged with a view are only visible to clients in that view
+	view := h.clientView(resp, req)
+	log.Traceln("Answering", q.Name, "from", view, "view")
+
+	// get all the records for the name at once, if there aren't any return
+	// NXDOMAIN
+	records, err := h.Store.Lookup(zone, name, view)
+	if err != nil {
+		log.Errorf("Error querying database: %s", err)
+		m.SetRcode(req, dns.RcodeServerFailure)
+		h.writeMsg(resp, req, m)
+		return
+	}
+	if len(records) == 0 {
 		log.Errorf("Requested name %s (%v) not found in database",
 			name, q.Name)
 		m.SetRcode(req, dns.RcodeNameError)
-		err := resp.WriteMsg(m)
-		if err != nil {
-			log.Errorf("Error writing response: %s", err)
-		}
-		return
-	default:
-		log.Errorf("Error querying database: %s", result.Error)
-		m.SetRcode(req, dns.RcodeServerFailure)
-		err := resp.WriteMsg(m)
-		if err != nil {
-			log.Errorf("Error writing response: %s", err)
-		}
+		h.writeMsg(resp, req, m)
 		return
 	}
 
 	// switch based on the type of query
 	switch q.Qtype {
 	case dns.TypeSOA:
-		m.Answer = append(m.Answer, h.genSOA(resp, req, name))
+		m.Answer = append(m.Answer, h.genSOA(resp, req, zone, name))
 	case dns.TypeAXFR:
 		// check if the requester is in the axfr allowed list
-		var allowed bool
-		for _, addr := range h.Config.DnsConf.GetAxfrTo() {
-			if strings.HasPrefix(resp.RemoteAddr().String(), addr) {
-				allowed = true
-				break
-			}
-		}
-		if !allowed {
+		if !h.transferAllowed(resp) {
 			log.Errorf("AXFR request from %s denied", resp.RemoteAddr().String())
 			m.SetRcode(req, dns.RcodeRefused)
-			err := resp.WriteMsg(m)
-			if err != nil {
-				log.Errorf("Error writing response: %s", err)
-			}
+			h.writeMsg(resp, req, m)
 			return
 		}
 
@@ -112,36 +129,179 @@ func (h DNSHandler) HandleDNS(resp dns.ResponseWriter, req *dns.Msg) {
 		m.MsgHdr.RecursionAvailable = false
 
 		// generate the SOA record and prepend it to the response
-		m.Answer = []dns.RR{h.genSOA(resp, req, name)}
+		m.Answer = []dns.RR{h.genSOA(resp, req, zone, name)}
 
-		// get all non-SOA records for the requested name
-		var records []*pb.DNSRecord
-		h.DB.Where("LOWER(name) = LOWER(?) AND type != ?", name, dns.TypeSOA).Find(&records)
-		m.Answer = append(m.Answer, h.autoRRFormatter(records)...)
+		// add all non-SOA records for the requested name
+		m.Answer = append(m.Answer, h.autoRRFormatter(withoutType(records, dns.TypeSOA))...)
 
 		// add the SOA record to the end of the response, as per RFC 5936
-		m.Answer = append(m.Answer, h.genSOA(resp, req, name))
+		m.Answer = append(m.Answer, h.genSOA(resp, req, zone, name))
 		log.Tracef("%#v", m.Answer)
 		m.SetRcode(req, dns.RcodeSuccess)
-		err := resp.WriteMsg(m)
-		if err != nil {
-			log.Errorf("Error writing response: %s", err)
-		}
+		h.writeMsg(resp, req, m)
+		return
+
+	case dns.TypeANY:
+		m.Answer = append(m.Answer, h.answerANY(resp, q, records)...)
 
 	default:
-		var records []*pb.DNSRecord
-		h.DB.Where("LOWER(name) = LOWER(?) AND type = ?", name, q.Qtype).Find(&records)
 		if _, ok := recordToFmt[q.Qtype]; !ok {
 			log.Errorf("Unsupported query type %d", q.Qtype)
 			m.SetRcode(req, dns.RcodeNotImplemented)
 			break
 		}
-		m.Answer = append(m.Answer, h.autoRRFormatter(records)...)
+		m.Answer = append(m.Answer, h.autoRRFormatter(ofType(records, q.Qtype))...)
+	}
+
+	h.writeMsg(resp, req, m)
+}
+
+// writeMsg sends m as the reply to req. UDP replies are rate limited first,
+// unless the client has a valid DNS cookie. If the client used EDNS0 an OPT
+// record is echoed back, and UDP replies are truncated (with TC set) to
+// whichever is smaller of the client's advertised buffer size and our
+// configured cap.
+func (h DNSHandler) writeMsg(resp dns.ResponseWriter, req, m *dns.Msg) {
+	_, udp := resp.RemoteAddr().(*net.UDPAddr)
+	cookie := h.checkCookie(resp, req)
+	// a valid server cookie proves the client isn't spoofed, so it can't be
+	// using us as a reflector
+	if udp && !cookie.valid {
+		switch action := h.RRL.check(addrIP(resp.RemoteAddr()), m); {
+		case action != rrlAllow && cookie.present && !cookie.malformed:
+			// RFC 7873 section 5.4, give the client a cookie to come back with
+			m = badCookie(req)
+		case action == rrlDrop:
+			return
+		case action == rrlSlip:
+			// an empty truncated reply lets real clients retry over TCP
+			slip := new(dns.Msg)
+			slip.SetReply(req)
+			slip.Truncated = true
+			m = slip
+		}
+	}
+
+	size := uint16(dns.MinMsgSize)
+	if opt := req.IsEdns0(); opt != nil {
+		if m.IsEdns0() == nil {
+			m.SetEdns0(h.maxUDPSize(), false)
+		}
+		// RFC 7871 section 7.2.1, tell forwarders the answer is good for the
+		// whole subnet they asked about
+		if ecs := h.clientSubnet(resp, req); ecs != nil {
+			m.IsEdns0().Option = append(m.IsEdns0().Option, &dns.EDNS0_SUBNET{
+				Code:          dns.EDNS0SUBNET,
+				Family:        ecs.Family,
+				SourceNetmask: ecs.SourceNetmask,
+				SourceScope:   ecs.SourceNetmask,
+				Address:       ecs.Address,
+			})
+		}
+		if cookie.present && !cookie.malformed {
+			m.IsEdns0().Option = append(m.IsEdns0().Option, h.cookieOption(resp, cookie))
+		}
+		if nsid := h.nsid(); nsid != "" && hasEDNSOption(req, dns.EDNS0NSID) {
+			m.IsEdns0().Option = append(m.IsEdns0().Option, &dns.EDNS0_NSID{
+				Code: dns.EDNS0NSID,
+				Nsid: hex.EncodeToString([]byte(nsid)),
+			})
+		}
+		if opt.UDPSize() > size {
+			size = opt.UDPSize()
+		}
+		if size > h.maxUDPSize() {
+			size = h.maxUDPSize()
+		}
+	}
+
+	if udp {
+		m.Truncate(int(size))
 	}
 
 	err := resp.WriteMsg(m)
 	if err != nil {
 		log.Errorf("Error writing response: %s", err)
-		log.Tracef("%#v", m.Answer[0])
 	}
 }
+
+// hasEDNSOption reports whether req has an EDNS option with the given code.
+func hasEDNSOption(req *dns.Msg, code uint16) bool {
+	opt := req.IsEdns0()
+	if opt == nil {
+		return false
+	}
+	for _, o := range opt.Option {
+		if o.Option() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// maxUDPSize returns the configured EDNS0 buffer size cap, never going below
+// the 512 bytes every DNS client has to accept.
+func (h DNSHandler) maxUDPSize() uint16 {
+	size := h.Config.GetDnsConf().GetMaxUdpSize()
+	switch {
+	case size < dns.MinMsgSize:
+		return dns.MinMsgSize
+	case size > dns.MaxMsgSize:
+		return dns.MaxMsgSize
+	}
+	return uint16(size)
+}
+
+// transferAllowed reports whether the client of resp is in the list of hosts
+// allowed to receive zone transfers (and full ANY responses). DoH clients
+// never are, as the address we see for them is usually that of a proxy.
+func (h DNSHandler) transferAllowed(resp dns.ResponseWriter) bool {
+	if viaDoH(resp) {
+		return false
+	}
+	for _, prefix := range h.Config.GetDnsConf().GetAxfrTo() {
+		if strings.HasPrefix(resp.RemoteAddr().String(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// answerANY builds the answer section for a query of type ANY, following
+// RFC 8482. Hosts allowed to AXFR get every record for the name as long as
+// they ask over TCP, since that cannot be used for amplification. Everyone
+// else gets a single RRset, or a synthesized HINFO record if the name has
+// nothing we know how to format. records are all the records for the name,
+// sorted by type.
+func (h DNSHandler) answerANY(resp dns.ResponseWriter, q dns.Question,
+	records []*pb.DNSRecord) []dns.RR {
+	records = withoutType(records, dns.TypeSOA)
+
+	if _, ok := resp.RemoteAddr().(*net.TCPAddr); ok && h.transferAllowed(resp) {
+		log.Tracef("Full ANY response for %s to %s", q.Name, resp.RemoteAddr())
+		return h.autoRRFormatter(records)
+	}
+
+	// pick the first RRset we can actually format
+	for i, record := range records {
+		if _, ok := recordToFmt[uint16(record.GetType())]; !ok {
+			continue
+		}
+		j := i
+		for j < len(records) && records[j].GetType() == record.GetType() {
+			j++
+		}
+		return h.autoRRFormatter(records[i:j])
+	}
+
+	return []dns.RR{&dns.HINFO{
+		Hdr: dns.RR_Header{
+			Name:   q.Name,
+			Rrtype: dns.TypeHINFO,
+			Class:  dns.ClassINET,
+			Ttl:    anyHINFOTTL,
+		},
+		Cpu: "RFC8482",
+		Os:  "",
+	}}
+}
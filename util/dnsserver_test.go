@@ -1,20 +1,20 @@
 package util
 
 import (
-	"database/sql"
+	"bytes"
+	"encoding/base64"
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
 	"testing"
 	"time"
 
-	"github.com/DATA-DOG/go-txdb"
 	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 )
 
 var (
@@ -58,6 +58,20 @@ var (
 				Ttl: 90, Class: dns.ClassINET, Rrtype: dns.TypeAAAA, Rdlength: 16},
 				AAAA: net.ParseIP("2606:700:e:550::1")}},
 		},
+		{
+			Name:   "test.valid.zone.",
+			Qtype:  dns.TypeANY,
+			Qclass: dns.ClassINET,
+		}: {MsgHdr: dns.MsgHdr{Response: true, Opcode: 0,
+			Authoritative: true, Truncated: false, RecursionDesired: true,
+			RecursionAvailable: false, Zero: false, AuthenticatedData: false,
+			CheckingDisabled: false, Rcode: dns.RcodeSuccess},
+			Question: []dns.Question{{Name: "test.valid.zone.",
+				Qtype: dns.TypeANY, Qclass: dns.ClassINET}},
+			Answer: []dns.RR{&dns.A{Hdr: dns.RR_Header{Name: "test.valid.zone.", Ttl: 50, Class: dns.ClassINET,
+				Rrtype: dns.TypeA, Rdlength: 4},
+				A: net.IP{1, 2, 3, 4}}},
+		},
 	}
 	testDNSRecords = []*pb.DNSRecord{
 		{
@@ -103,35 +117,14 @@ func TestReadConf(t *testing.T) {
 func TestHandleDNS(t *testing.T) {
 	TestReadConf(t)
 
-	// initialize db with sqlmock
-	txdb.Register("txdb", "pg",
-		fmt.Sprintf(
-			"postgres://%s:%s@%s/%s?sslmode=disable",
-			testCfg.DBConf.GetUsername(), testCfg.DBConf.GetPassword(),
-			testCfg.DBConf.GetHostname(), testCfg.DBConf.GetDatabaseName(),
-		))
-
-	tdb, err := sql.Open("txdb", "handleDNS")
-	if err != nil {
-		t.Fatalf(
-			"an error '%s' was not expected when opening a stub database connection", err)
-	}
-	db, err := gorm.Open(postgres.New(postgres.Config{
-		Conn: tdb,
-	}))
-	if err != nil {
-		t.Fatalf(
-			"an error '%s' was not expected when opening a stub database connection", err)
-	}
-
-	// migrate the schema
-	db.AutoMigrate(&pb.DNSRecord{})
+	// keep the records in memory, so no database server is needed
+	store := NewMemoryStore()
 
 	// insert test data
-	insertTestData(db, t)
+	insertTestData(store, t)
 
 	// start a DNS server
-	h := DNSHandler{testCfg, db}
+	h := DNSHandler{Config: testCfg, Store: store}
 	dnsMux := dns.NewServeMux()
 	dnsMux.HandleFunc(".", h.HandleDNS)
 	srv := &dns.Server{Addr: testCfg.DnsConf.GetListenPort(), Net: "udp",
@@ -172,12 +165,427 @@ func TestHandleDNS(t *testing.T) {
 
 }
 
-func insertTestData(db *gorm.DB, t *testing.T) {
+func insertTestData(store RecordStore, t *testing.T) {
 	for _, record := range testDNSRecords {
-		out := db.Create(record)
+		err := store.Create(record)
 		fmt.Printf("added record: %+v\n", record)
-		if out.Error != nil {
-			t.Fatalf("error inserting test data: %v", out.Error)
+		if err != nil {
+			t.Fatalf("error inserting test data: %v", err)
+		}
+	}
+}
+
+// testResponseWriter is a dns.ResponseWriter that records the message written
+// to it instead of sending it anywhere.
+type testResponseWriter struct {
+	dns.ResponseWriter
+	remote net.Addr
+	msg    *dns.Msg
+}
+
+func (w *testResponseWriter) RemoteAddr() net.Addr { return w.remote }
+func (w *testResponseWriter) LocalAddr() net.Addr  { return w.remote }
+
+func (w *testResponseWriter) WriteMsg(m *dns.Msg) error {
+	w.msg = m
+	return nil
+}
+
+func TestWriteMsgEDNS(t *testing.T) {
+	h := DNSHandler{Config: &pb.ServerConfig{DnsConf: &pb.DNSConfig{MaxUdpSize: 1232}}}
+	udp := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 53}
+	tcp := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 53}
+
+	// build a reply far too large for a single datagram
+	bigReply := func(req *dns.Msg) *dns.Msg {
+		m := new(dns.Msg)
+		m.SetReply(req)
+		for i := 0; i < 100; i++ {
+			m.Answer = append(m.Answer, &dns.TXT{
+				Hdr: dns.RR_Header{Name: "big.valid.zone.", Rrtype: dns.TypeTXT,
+					Class: dns.ClassINET, Ttl: 60},
+				Txt: []string{fmt.Sprintf("record number %d with some padding", i)},
+			})
+		}
+		return m
+	}
+
+	for _, tc := range []struct {
+		name      string
+		remote    net.Addr
+		bufsize   uint16
+		maxSize   int
+		truncated bool
+	}{
+		{"plain UDP", udp, 0, dns.MinMsgSize, true},
+		{"EDNS0 UDP", udp, 4096, 1232, true},
+		{"small EDNS0 UDP", udp, 700, 700, true},
+		{"TCP", tcp, 4096, dns.MaxMsgSize, false},
+	} {
+		req := new(dns.Msg)
+		req.SetQuestion("big.valid.zone.", dns.TypeTXT)
+		if tc.bufsize != 0 {
+			req.SetEdns0(tc.bufsize, false)
+		}
+
+		w := &testResponseWriter{remote: tc.remote}
+		h.writeMsg(w, req, bigReply(req))
+
+		if w.msg.Truncated != tc.truncated {
+			t.Errorf("%s: truncated = %v, want %v", tc.name, w.msg.Truncated, tc.truncated)
+		}
+		if w.msg.Len() > tc.maxSize {
+			t.Errorf("%s: reply is %d bytes, want at most %d", tc.name, w.msg.Len(), tc.maxSize)
+		}
+		if (tc.bufsize != 0) != (w.msg.IsEdns0() != nil) {
+			t.Errorf("%s: OPT record not echoed correctly", tc.name)
+		}
+	}
+
+	// unknown EDNS versions get BADVERS
+	req := new(dns.Msg)
+	req.SetQuestion("test.valid.zone.", dns.TypeA)
+	req.SetEdns0(4096, false)
+	req.IsEdns0().SetVersion(1)
+	w := &testResponseWriter{remote: udp}
+	h.HandleDNS(w, req)
+	if w.msg.Rcode != dns.RcodeBadVers {
+		t.Errorf("rcode = %s, want BADVERS", dns.RcodeToString[w.msg.Rcode])
+	}
+	if _, err := w.msg.Pack(); err != nil {
+		t.Errorf("error packing BADVERS reply: %v", err)
+	}
+}
+
+func TestServeDoH(t *testing.T) {
+	h := DNSHandler{Config: &pb.ServerConfig{DnsConf: &pb.DNSConfig{
+		RootZones: []string{"valid.zone."}}}}
+
+	req := new(dns.Msg)
+	req.SetQuestion("test.invalid.zone.", dns.TypeA)
+	req.Id = 0
+	buf, err := req.Pack()
+	if err != nil {
+		t.Fatalf("error packing request: %v", err)
+	}
+
+	for _, httpReq := range []*http.Request{
+		httptest.NewRequest(http.MethodGet,
+			DoHPath+"?dns="+base64.RawURLEncoding.EncodeToString(buf), nil),
+		func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, DoHPath, bytes.NewReader(buf))
+			r.Header.Set("Content-Type", dohContentType)
+			return r
+		}(),
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeDoH(rec, httpReq)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200", httpReq.Method, rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != dohContentType {
+			t.Errorf("%s: Content-Type = %q", httpReq.Method, ct)
+		}
+		if cc := rec.Header().Get("Cache-Control"); cc != "max-age=0" {
+			t.Errorf("%s: Cache-Control = %q", httpReq.Method, cc)
+		}
+
+		resp := new(dns.Msg)
+		if err := resp.Unpack(rec.Body.Bytes()); err != nil {
+			t.Fatalf("%s: error unpacking response: %v", httpReq.Method, err)
+		}
+		if resp.Rcode != dns.RcodeNameError {
+			t.Errorf("%s: rcode = %s, want NXDOMAIN", httpReq.Method,
+				dns.RcodeToString[resp.Rcode])
+		}
+	}
+
+	// anything else isn't DoH
+	rec := httptest.NewRecorder()
+	h.ServeDoH(rec, httptest.NewRequest(http.MethodPut, DoHPath, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT: status = %d, want 405", rec.Code)
+	}
+}
+
+func TestDoHTransfers(t *testing.T) {
+	// a proxy on loopback is what DoH clients usually come through
+	h := DNSHandler{Config: &pb.ServerConfig{DnsConf: &pb.DNSConfig{
+		AxfrTo: []string{"127.0.0.1"},
+	}}}
+	req := httptest.NewRequest(http.MethodGet, DoHPath, nil)
+	req.RemoteAddr = "127.0.0.1:12345"
+	if h.transferAllowed(&dohResponseWriter{req: req}) {
+		t.Errorf("transfers allowed over DoH from %s", req.RemoteAddr)
+	}
+	if !h.transferAllowed(&testResponseWriter{remote: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 12345}}) {
+		t.Errorf("transfers not allowed over TCP from 127.0.0.1")
+	}
+}
+
+func TestDoHFromLoopback(t *testing.T) {
+	// a proxy on loopback is what DoH clients usually come through
+	h := DNSHandler{Store: NewMemoryStore(), Config: &pb.ServerConfig{DnsConf: &pb.DNSConfig{
+		RootZones:        []string{"valid.zone."},
+		AxfrTo:           []string{"127.0.0.1"},
+		InternalPrefixes: []string{"127.0.0.0/8"},
+	}}}
+	for _, record := range []*pb.DNSRecord{
+		{Name: "test", Type: uint32(dns.TypeA), Value: "1.2.3.4", Zone: "valid.zone.", Ttl: 50},
+		{Name: "secret", Type: uint32(dns.TypeA), Value: "10.0.0.1", Zone: "valid.zone.",
+			Ttl: 50, View: ViewInternal},
+	} {
+		if err := h.Store.Create(record); err != nil {
+			t.Fatalf("error inserting test data: %v", err)
+		}
+	}
+
+	query := func(name string, qtype uint16) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion(name, qtype)
+		buf, err := req.Pack()
+		if err != nil {
+			t.Fatalf("error packing request: %v", err)
+		}
+		httpReq := httptest.NewRequest(http.MethodGet,
+			DoHPath+"?dns="+base64.RawURLEncoding.EncodeToString(buf), nil)
+		httpReq.RemoteAddr = "127.0.0.1:12345"
+		rec := httptest.NewRecorder()
+		h.ServeDoH(rec, httpReq)
+		resp := new(dns.Msg)
+		if err := resp.Unpack(rec.Body.Bytes()); err != nil {
+			t.Fatalf("%s: error unpacking response: %v", name, err)
+		}
+		return resp
+	}
+
+	if resp := query("secret.valid.zone.", dns.TypeA); resp.Rcode != dns.RcodeNameError {
+		t.Errorf("internal record: rcode = %s, want NXDOMAIN from the external view",
+			dns.RcodeToString[resp.Rcode])
+	}
+	if resp := query("test.valid.zone.", dns.TypeAXFR); resp.Rcode != dns.RcodeRefused {
+		t.Errorf("AXFR: rcode = %s, want REFUSED", dns.RcodeToString[resp.Rcode])
+	}
+}
+
+func TestClientView(t *testing.T) {
+	h := DNSHandler{Config: &pb.ServerConfig{DnsConf: &pb.DNSConfig{
+		InternalPrefixes:  []string{"10.0.0.0/8", "fc00::/7"},
+		TrustedForwarders: []string{"192.0.2.53/32"},
+	}}}
+
+	withECS := func(ip net.IP) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion("test.valid.zone.", dns.TypeA)
+		req.SetEdns0(1232, false)
+		req.IsEdns0().Option = append(req.IsEdns0().Option, &dns.EDNS0_SUBNET{
+			Code: dns.EDNS0SUBNET, Family: 1, SourceNetmask: 24, Address: ip})
+		return req
+	}
+
+	for _, tc := range []struct {
+		name   string
+		remote net.IP
+		req    *dns.Msg
+		view   string
+	}{
+		{"internal v4", net.ParseIP("10.1.2.3"), new(dns.Msg), ViewInternal},
+		{"internal v6", net.ParseIP("fd00::1"), new(dns.Msg), ViewInternal},
+		{"external", net.ParseIP("198.51.100.1"), new(dns.Msg), ViewExternal},
+		{"trusted ECS", net.ParseIP("192.0.2.53"), withECS(net.ParseIP("10.9.9.0")), ViewInternal},
+		{"untrusted ECS", net.ParseIP("198.51.100.1"), withECS(net.ParseIP("10.9.9.0")), ViewExternal},
+	} {
+		w := &testResponseWriter{remote: &net.UDPAddr{IP: tc.remote, Port: 53}}
+		if view := h.clientView(w, tc.req); view != tc.view {
+			t.Errorf("%s: view = %s, want %s", tc.name, view, tc.view)
+		}
+	}
+
+	// DoH from a proxy on loopback is anyone
+	h.Config.DnsConf.InternalPrefixes = append(h.Config.DnsConf.InternalPrefixes, "127.0.0.0/8")
+	req := httptest.NewRequest(http.MethodGet, DoHPath, nil)
+	req.RemoteAddr = "127.0.0.1:12345"
+	if view := h.clientView(&dohResponseWriter{req: req}, new(dns.Msg)); view != ViewExternal {
+		t.Errorf("DoH from loopback: view = %s, want %s", view, ViewExternal)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(&pb.RRLConfig{
+		ResponsesPerSecond: 5,
+		Window:             2,
+		Slip:               2,
+		Ipv4PrefixLength:   24,
+		Ipv6PrefixLength:   56,
+	})
+	now := time.Unix(1700000000, 0)
+	l.now = func() time.Time { return now }
+
+	answer := new(dns.Msg)
+	answer.Answer = []dns.RR{&dns.A{}}
+	client := net.ParseIP("198.51.100.1")
+	neighbour := net.ParseIP("198.51.100.2")
+
+	// the first second's worth is allowed, then every other response is
+	// slipped and the rest dropped
+	for i := 0; i < 5; i++ {
+		if a := l.check(client, answer); a != rrlAllow {
+			t.Fatalf("response %d: action = %d, want allow", i, a)
+		}
+	}
+	if a := l.check(neighbour, answer); a != rrlDrop {
+		t.Errorf("same /24: action = %d, want drop", a)
+	}
+	if a := l.check(client, answer); a != rrlSlip {
+		t.Errorf("limited: action = %d, want slip", a)
+	}
+
+	// other response classes and other networks have their own buckets
+	nxdomain := new(dns.Msg)
+	nxdomain.Rcode = dns.RcodeNameError
+	if a := l.check(client, nxdomain); a != rrlAllow {
+		t.Errorf("NXDOMAIN: action = %d, want allow", a)
+	}
+	if a := l.check(net.ParseIP("203.0.113.1"), answer); a != rrlAllow {
+		t.Errorf("other network: action = %d, want allow", a)
+	}
+
+	// once the window has passed the client is forgiven
+	now = now.Add(3 * time.Second)
+	if a := l.check(client, answer); a != rrlAllow {
+		t.Errorf("after window: action = %d, want allow", a)
+	}
+
+	// a nil RateLimiter allows everything
+	if a := NewRateLimiter(&pb.RRLConfig{}).check(client, answer); a != rrlAllow {
+		t.Errorf("disabled: action = %d, want allow", a)
+	}
+}
+
+func TestDNSCookies(t *testing.T) {
+	h := DNSHandler{
+		Config: &pb.ServerConfig{DnsConf: &pb.DNSConfig{
+			RootZones: []string{"valid.zone."}, RequireServerCookie: true}},
+		CookieSecret: []byte("0123456789abcdef"),
+	}
+	remote := &net.UDPAddr{IP: net.ParseIP("198.51.100.1"), Port: 53}
+
+	query := func(cookie string) *dns.Msg {
+		req := new(dns.Msg)
+		req.SetQuestion("test.invalid.zone.", dns.TypeA)
+		req.SetEdns0(1232, false)
+		req.IsEdns0().Option = append(req.IsEdns0().Option,
+			&dns.EDNS0_COOKIE{Code: dns.EDNS0COOKIE, Cookie: cookie})
+		w := &testResponseWriter{remote: remote}
+		h.HandleDNS(w, req)
+		return w.msg
+	}
+	responseCookie := func(m *dns.Msg) string {
+		for _, o := range m.IsEdns0().Option {
+			if c, ok := o.(*dns.EDNS0_COOKIE); ok {
+				return c.Cookie
+			}
+		}
+		t.Fatalf("no cookie in response")
+		return ""
+	}
+
+	// a client cookie alone isn't enough when server cookies are required
+	resp := query("0102030405060708")
+	if resp.Rcode != dns.RcodeBadCookie {
+		t.Fatalf("rcode = %s, want BADCOOKIE", dns.RcodeToString[resp.Rcode])
+	}
+	cookie := responseCookie(resp)
+	if len(cookie) != 2*(clientCookieLen+serverCookieLen) || cookie[:16] != "0102030405060708" {
+		t.Fatalf("bad cookie in response: %s", cookie)
+	}
+
+	// coming back with the server cookie gets an answer
+	if resp := query(cookie); resp.Rcode != dns.RcodeNameError {
+		t.Errorf("rcode = %s, want NXDOMAIN", dns.RcodeToString[resp.Rcode])
+	}
+
+	// but a server cookie for someone else does not
+	if resp := query("0807060504030201" + cookie[16:]); resp.Rcode != dns.RcodeBadCookie {
+		t.Errorf("rcode = %s, want BADCOOKIE", dns.RcodeToString[resp.Rcode])
+	}
+
+	// and a cookie of the wrong length is a FORMERR
+	if resp := query("01020304"); resp.Rcode != dns.RcodeFormatError {
+		t.Errorf("rcode = %s, want FORMERR", dns.RcodeToString[resp.Rcode])
+	}
+}
+
+func TestHandleChaos(t *testing.T) {
+	h := DNSHandler{Config: &pb.ServerConfig{DnsConf: &pb.DNSConfig{
+		RootZones: []string{"valid.zone."}, Identity: "ns1", HideVersion: true}}}
+
+	for name, want := range map[string]struct {
+		rcode int
+		txt   string
+	}{
+		"hostname.bind.": {dns.RcodeSuccess, "ns1"},
+		"ID.Server.":     {dns.RcodeSuccess, "ns1"},
+		"version.bind.":  {dns.RcodeRefused, ""},
+		"authors.bind.":  {dns.RcodeRefused, ""},
+	} {
+		req := new(dns.Msg)
+		req.SetQuestion(name, dns.TypeTXT)
+		req.Question[0].Qclass = dns.ClassCHAOS
+		w := &testResponseWriter{remote: &net.UDPAddr{IP: net.ParseIP("127.0.0.1")}}
+		h.HandleDNS(w, req)
+
+		if w.msg.Rcode != want.rcode {
+			t.Errorf("%s: rcode = %s, want %s", name, dns.RcodeToString[w.msg.Rcode],
+				dns.RcodeToString[want.rcode])
+		}
+		if want.txt == "" {
+			continue
+		}
+		if len(w.msg.Answer) != 1 || w.msg.Answer[0].(*dns.TXT).Txt[0] != want.txt {
+			t.Errorf("%s: answer = %v, want %s", name, w.msg.Answer, want.txt)
+		}
+	}
+
+	// NSID is only sent to clients that ask for it, and defaults to identity
+	for _, askNSID := range []bool{false, true} {
+		want := map[bool]string{true: "6e7331"}[askNSID]
+		if nsid := queryNSID(h, askNSID); nsid != want {
+			t.Errorf("asked for NSID %v: got %q, want %q", askNSID, nsid, want)
+		}
+	}
+
+	// hiding the identity hides the NSID standing in for it, but not one which
+	// was set
+	h.Config.DnsConf.HideIdentity = true
+	if nsid := queryNSID(h, true); nsid != "" {
+		t.Errorf("hidden identity: got NSID %q, want none", nsid)
+	}
+	h.Config.DnsConf.Nsid = "anycast-1"
+	if nsid := queryNSID(h, true); nsid != "616e79636173742d31" {
+		t.Errorf("hidden identity with NSID set: got NSID %q, want 616e79636173742d31", nsid)
+	}
+}
+
+// queryNSID sends h a query, asking for NSID if askNSID is set, and returns
+// the NSID in the response.
+func queryNSID(h DNSHandler, askNSID bool) string {
+	req := new(dns.Msg)
+	req.SetQuestion("test.invalid.zone.", dns.TypeA)
+	req.SetEdns0(1232, false)
+	if askNSID {
+		req.IsEdns0().Option = append(req.IsEdns0().Option,
+			&dns.EDNS0_NSID{Code: dns.EDNS0NSID})
+	}
+	w := &testResponseWriter{remote: &net.UDPAddr{IP: net.ParseIP("127.0.0.1")}}
+	h.HandleDNS(w, req)
+
+	var nsid string
+	for _, o := range w.msg.IsEdns0().Option {
+		if n, ok := o.(*dns.EDNS0_NSID); ok {
+			nsid = n.Nsid
 		}
 	}
+	return nsid
 }
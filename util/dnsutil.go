@@ -2,12 +2,16 @@ package util
 
 import (
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
 
 	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
 	"github.com/gidoBOSSftw5731/log"
 	"github.com/miekg/dns"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 var (
@@ -30,68 +34,141 @@ var (
 		dns.TypeMX:    f}
 )
 
-func ParseDNSRecord(req *http.Request) (*pb.DNSRecord, error) {
-	dnsRecord := &pb.DNSRecord{}
+// FieldErrors lists what is wrong with each invalid field of a request.
+type FieldErrors []*pb.FieldError
 
-	// parse the POST form
-	err := req.ParseForm()
-	if err != nil {
-		return nil, err
+func (e FieldErrors) Error() string {
+	var fields []string
+	for _, field := range e {
+		fields = append(fields, field.GetField()+": "+field.GetMessage())
 	}
+	return "invalid fields: " + strings.Join(fields, ", ")
+}
 
-	// set the fields of the DNSRecord using a map of the fields and pointers to their locations
-	// in the struct
-	// all of these are mandatory
-	for field, val := range map[string]*string{
-		"user": &dnsRecord.User,
-		"name": &dnsRecord.Name,
-		"zone": &dnsRecord.Zone,
-	} {
-		*val = req.FormValue(field)
-		if *val == "" {
-			return nil, fmt.Errorf("missing field %s", field)
+// add notes field is invalid, unless it already has been.
+func (e *FieldErrors) add(field, message string) {
+	for _, fieldErr := range *e {
+		if fieldErr.GetField() == field {
+			return
 		}
 	}
+	*e = append(*e, &pb.FieldError{Field: field, Message: message})
+}
 
-	// do the same as above for non-string fields
-	for field, val := range map[string]*uint32{
-		"ttl":  &dnsRecord.Ttl,
-		"type": &dnsRecord.Type,
-	} {
-		i, err := strconv.Atoi(req.FormValue(field))
+// MaxRecordSize is the most the body of a request holding a record may take
+// up, whether it is JSON or a form.
+const MaxRecordSize = 64 << 10
+
+// ParseDNSRecord reads a record from a request, either as JSON in the body, in
+// the form of drs.proto, or from form fields. The id field is only read from
+// JSON, and the server decides the revision and whether the record is
+// disabled. If any fields are invalid the error is a FieldErrors, and if the
+// body is larger than MaxRecordSize it is an *http.MaxBytesError.
+func ParseDNSRecord(req *http.Request) (*pb.DNSRecord, error) {
+	dnsRecord := &pb.DNSRecord{}
+	var errs FieldErrors
+
+	req.Body = http.MaxBytesReader(nil, req.Body, MaxRecordSize)
+	if IsJSON(req) {
+		body, err := io.ReadAll(req.Body)
 		if err != nil {
 			return nil, err
 		}
-		*val = uint32(i)
-		if *val == 0 {
-			return nil, fmt.Errorf("missing field %s", field)
+		if err := protojson.Unmarshal(body, dnsRecord); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		dnsRecord.Revision, dnsRecord.Disabled = 0, false
+	} else {
+		// parse the POST form
+		err := req.ParseForm()
+		if err != nil {
+			return nil, err
+		}
+
+		// set the fields of the DNSRecord using a map of the fields and
+		// pointers to their locations in the struct
+		for field, val := range map[string]*string{
+			"name":     &dnsRecord.Name,
+			"zone":     &dnsRecord.Zone,
+			"value":    &dnsRecord.Value,
+			"priority": &dnsRecord.Priority,
+			"view":     &dnsRecord.View,
+			// the owner is optional, as only admins may give records to
+			// someone else
+			"user": &dnsRecord.User,
+		} {
+			*val = req.FormValue(field)
 		}
-	}
 
-	// set the optional fields based on the type
-	requiredFields := map[string]*string{}
+		// do the same as above for non-string fields
+		for field, val := range map[string]*uint32{
+			"ttl":  &dnsRecord.Ttl,
+			"type": &dnsRecord.Type,
+		} {
+			if req.FormValue(field) == "" {
+				continue
+			}
+			i, err := strconv.ParseUint(req.FormValue(field), 10, 32)
+			if err != nil {
+				errs.add(field, "must be a number")
+			}
+			*val = uint32(i)
+		}
+	}
 
-	if _, ok := requireValue[uint16(dnsRecord.GetType())]; ok {
-		requiredFields["value"] = &dnsRecord.Value
+	// whatever is wrong with the fields which were read comes first
+	if err := ValidateDNSRecord(dnsRecord); err != nil {
+		for _, fieldErr := range err.(FieldErrors) {
+			errs.add(fieldErr.GetField(), fieldErr.GetMessage())
+		}
 	}
-	if _, ok := requirePriority[uint16(dnsRecord.GetType())]; ok {
-		requiredFields["priority"] = &dnsRecord.Priority
+	if len(errs) != 0 {
+		return nil, errs
 	}
+	return dnsRecord, nil
+}
 
-	for field, val := range requiredFields {
-		*val = req.FormValue(field)
-		if *val == "" {
-			return nil, fmt.Errorf("missing field %s", field)
+// ValidateDNSRecord checks record has the fields its type needs, and returns
+// a FieldErrors listing every one which doesn't.
+func ValidateDNSRecord(record *pb.DNSRecord) error {
+	var errs FieldErrors
+	_, needsValue := requireValue[uint16(record.GetType())]
+	_, needsPriority := requirePriority[uint16(record.GetType())]
+	for _, required := range []struct {
+		field   string
+		missing bool
+	}{
+		{"name", record.GetName() == ""},
+		{"zone", record.GetZone() == ""},
+		{"ttl", record.GetTtl() == 0},
+		{"type", record.GetType() == 0},
+		{"value", needsValue && record.GetValue() == ""},
+		{"priority", needsPriority && record.GetPriority() == ""},
+	} {
+		if required.missing {
+			errs.add(required.field, "missing")
 		}
 	}
 
-	if _, ok := requireTrailingPeriodInValue[uint16(dnsRecord.GetType())]; ok {
-		if dnsRecord.GetValue()[len(dnsRecord.GetValue())-1] != '.' {
-			return nil, fmt.Errorf("value field must end with a period")
+	if _, ok := requireTrailingPeriodInValue[uint16(record.GetType())]; ok {
+		if !strings.HasSuffix(record.GetValue(), ".") {
+			errs.add("value", "must end with a period")
 		}
 	}
+	if _, ok := validViews[record.GetView()]; !ok {
+		errs.add("view", fmt.Sprintf("must be %q, %q or empty", ViewInternal, ViewExternal))
+	}
 
-	return dnsRecord, nil
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// IsJSON reports whether the body of req is JSON.
+func IsJSON(req *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return mediaType == "application/json"
 }
 
 func processFullName(record *pb.DNSRecord) string {
@@ -110,7 +187,10 @@ func (h DNSHandler) autoRRFormatter(records []*pb.DNSRecord) []dns.RR {
 	// This one loop works for the *vast majority* of records,
 	// where all the handlers are somewhere else.
 	for _, record := range records {
-		ret = append(ret, h.singleAutoRRFormatter(record))
+		// skip types we have no formatter for rather than sending a nil RR
+		if rr := h.singleAutoRRFormatter(record); rr != nil {
+			ret = append(ret, rr)
+		}
 	}
 	return ret
 }
@@ -122,3 +202,25 @@ func (h DNSHandler) singleAutoRRFormatter(record *pb.DNSRecord) dns.RR {
 	}
 	return nil
 }
+
+// ofType returns the records in records of type rrtype.
+func ofType(records []*pb.DNSRecord, rrtype uint16) []*pb.DNSRecord {
+	var ret []*pb.DNSRecord
+	for _, record := range records {
+		if uint16(record.GetType()) == rrtype {
+			ret = append(ret, record)
+		}
+	}
+	return ret
+}
+
+// withoutType returns the records in records not of type rrtype.
+func withoutType(records []*pb.DNSRecord, rrtype uint16) []*pb.DNSRecord {
+	var ret []*pb.DNSRecord
+	for _, record := range records {
+		if uint16(record.GetType()) != rrtype {
+			ret = append(ret, record)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,76 @@
+package util
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestParseDNSRecord(t *testing.T) {
+	req := httptest.NewRequest("POST", "/addDNSRecord", strings.NewReader(
+		`{"name": "a", "zone": "valid.zone.", "type": 1, "value": "192.0.2.1", "ttl": 60, "revision": 7}`))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	record, err := ParseDNSRecord(req)
+	if err != nil {
+		t.Fatalf("error parsing JSON: %v", err)
+	}
+	// the server decides revisions
+	if record.GetName() != "a" || record.GetType() != uint32(dns.TypeA) || record.GetRevision() != 0 {
+		t.Errorf("record from JSON = %v", record)
+	}
+
+	form := url.Values{"name": {"a"}, "zone": {"valid.zone."}, "type": {"1"},
+		"value": {"192.0.2.1"}, "ttl": {"60"}, "view": {ViewInternal}}
+	req = httptest.NewRequest("POST", "/addDNSRecord", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	record, err = ParseDNSRecord(req)
+	if err != nil {
+		t.Fatalf("error parsing form: %v", err)
+	}
+	if record.GetTtl() != 60 || record.GetView() != ViewInternal {
+		t.Errorf("record from form = %v", record)
+	}
+
+	// every invalid field is listed, once
+	form = url.Values{"zone": {"valid.zone."}, "type": {"5"}, "value": {"b.valid.zone"},
+		"ttl": {"sixty"}, "view": {"outside"}}
+	req = httptest.NewRequest("POST", "/addDNSRecord", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	_, err = ParseDNSRecord(req)
+	fieldErrs, ok := err.(FieldErrors)
+	if !ok {
+		t.Fatalf("error = %v, want FieldErrors", err)
+	}
+	got := map[string]string{}
+	for _, fieldErr := range fieldErrs {
+		got[fieldErr.GetField()] = fieldErr.GetMessage()
+	}
+	want := map[string]string{"ttl": "must be a number", "name": "missing",
+		"value": "must end with a period", "view": ""}
+	if len(got) != len(want) {
+		t.Errorf("invalid fields = %v, want %v", got, want)
+	}
+	for field, message := range want {
+		if _, ok := got[field]; !ok || (message != "" && got[field] != message) {
+			t.Errorf("%s = %q, want %q", field, got[field], message)
+		}
+	}
+
+	// bodies are only read up to MaxRecordSize, as JSON or a form
+	for contentType, body := range map[string]string{
+		"application/json":                  `{"name": "` + strings.Repeat("a", MaxRecordSize) + `"}`,
+		"application/x-www-form-urlencoded": "name=" + strings.Repeat("a", MaxRecordSize),
+	} {
+		req = httptest.NewRequest("POST", "/addDNSRecord", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		var tooLarge *http.MaxBytesError
+		if _, err := ParseDNSRecord(req); !errors.As(err, &tooLarge) {
+			t.Errorf("%s: error = %v, want *http.MaxBytesError", contentType, err)
+		}
+	}
+}
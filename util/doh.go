@@ -0,0 +1,145 @@
+package util
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/gidoBOSSftw5731/log"
+	"github.com/miekg/dns"
+)
+
+const (
+	// DoHPath is the path DNS over HTTPS queries are served on, as suggested by
+	// RFC 8484.
+	DoHPath = "/dns-query"
+
+	dohContentType = "application/dns-message"
+)
+
+// ServeDoH answers RFC 8484 DNS over HTTPS queries, both GET and POST, by
+// handing the decoded message to HandleDNS.
+func (h DNSHandler) ServeDoH(resp http.ResponseWriter, req *http.Request) {
+	var buf []byte
+	var err error
+
+	switch req.Method {
+	case http.MethodGet:
+		buf, err = base64.RawURLEncoding.DecodeString(req.URL.Query().Get("dns"))
+	case http.MethodPost:
+		if req.Header.Get("Content-Type") != dohContentType {
+			resp.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		buf, err = io.ReadAll(io.LimitReader(req.Body, dns.MaxMsgSize))
+	default:
+		resp.Header().Set("Allow", "GET, POST")
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		log.Errorln("Error reading DoH request:", err)
+		resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	msg := new(dns.Msg)
+	if err := msg.Unpack(buf); err != nil {
+		log.Errorln("Error unpacking DoH request:", err)
+		resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	w := &dohResponseWriter{req: req}
+	h.HandleDNS(w, msg)
+	if w.msg == nil {
+		// HandleDNS didn't think the query deserved an answer
+		resp.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	out, err := w.msg.Pack()
+	if err != nil {
+		log.Errorln("Error packing DoH response:", err)
+		resp.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	resp.Header().Set("Content-Type", dohContentType)
+	resp.Header().Set("Cache-Control", fmt.Sprintf("max-age=%d", dohMaxAge(w.msg)))
+	resp.Write(out)
+}
+
+// dohMaxAge returns the freshness lifetime of a DoH response, which per
+// RFC 8484 section 5.1 must not exceed the smallest TTL in the message.
+func dohMaxAge(m *dns.Msg) uint32 {
+	var ttl uint32
+	found := false
+	for _, section := range [][]dns.RR{m.Answer, m.Ns, m.Extra} {
+		for _, rr := range section {
+			if rr.Header().Rrtype == dns.TypeOPT {
+				continue
+			}
+			if !found || rr.Header().Ttl < ttl {
+				ttl = rr.Header().Ttl
+				found = true
+			}
+		}
+	}
+	return ttl
+}
+
+// dohResponseWriter adapts an HTTP request to a dns.ResponseWriter so that
+// HandleDNS can answer it. The reply is kept rather than sent, so the caller
+// can write it out as the HTTP response.
+type dohResponseWriter struct {
+	req *http.Request
+	msg *dns.Msg
+}
+
+// viaDoH reports whether resp answers a DNS over HTTPS query. Those come in
+// through the API listener, which usually sits behind a proxy on loopback, so
+// their address says nothing about who is asking.
+func viaDoH(resp dns.ResponseWriter) bool {
+	_, ok := resp.(*dohResponseWriter)
+	return ok
+}
+
+// LocalAddr returns the address the HTTP server accepted the request on.
+func (w *dohResponseWriter) LocalAddr() net.Addr {
+	if addr, ok := w.req.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		return addr
+	}
+	return &net.TCPAddr{}
+}
+
+// RemoteAddr returns the address of the HTTP client. DoH always runs over a
+// stream, so this is a TCP address and replies are never truncated.
+func (w *dohResponseWriter) RemoteAddr() net.Addr {
+	addr, err := net.ResolveTCPAddr("tcp", w.req.RemoteAddr)
+	if err != nil {
+		return &net.TCPAddr{}
+	}
+	return addr
+}
+
+func (w *dohResponseWriter) WriteMsg(m *dns.Msg) error {
+	w.msg = m
+	return nil
+}
+
+func (w *dohResponseWriter) Write(buf []byte) (int, error) {
+	m := new(dns.Msg)
+	if err := m.Unpack(buf); err != nil {
+		return 0, err
+	}
+	w.msg = m
+	return len(buf), nil
+}
+
+func (w *dohResponseWriter) Close() error        { return nil }
+func (w *dohResponseWriter) TsigStatus() error   { return nil }
+func (w *dohResponseWriter) TsigTimersOnly(bool) {}
+func (w *dohResponseWriter) Hijack()             {}
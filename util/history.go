@@ -0,0 +1,201 @@
+package util
+
+import (
+	"sort"
+	"time"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// ZonePoint is a point in the history of a zone, either a serial or a unix
+// time. The zero ZonePoint is now.
+type ZonePoint struct {
+	Serial uint64
+	Time   int64
+}
+
+// includes reports whether version was made at or before p.
+func (p ZonePoint) includes(version *pb.RecordVersion) bool {
+	return (p.Serial == 0 || version.GetSerial() <= p.Serial) &&
+		(p.Time == 0 || version.GetTime() <= p.Time)
+}
+
+// ZoneAt replays versions, oldest first, into the records they leave behind,
+// sorted like RecordStore.List. The serial returned is that of the last
+// version.
+func ZoneAt(versions []*pb.RecordVersion) ([]*pb.DNSRecord, uint64) {
+	byID := map[uint64]*pb.RecordVersion{}
+	var serial uint64
+	for _, version := range versions {
+		byID[version.GetRecordId()] = version
+		serial = version.GetSerial()
+	}
+
+	var records []*pb.DNSRecord
+	for _, version := range byID {
+		if !version.GetDeleted() {
+			records = append(records, versionRecord(version))
+		}
+	}
+	sortRecords(records)
+	return records, serial
+}
+
+// DiffZone returns the changes which turn the records in before into those in
+// after, matching records up by ID. Deletions come first, then updates, then
+// new records, so they can be applied in order without clashing.
+func DiffZone(before, after []*pb.DNSRecord) []*pb.RecordChange {
+	old := map[uint64]*pb.DNSRecord{}
+	for _, record := range before {
+		old[record.GetId()] = record
+	}
+
+	var deleted, updated, created []*pb.RecordChange
+	for _, record := range after {
+		prev, ok := old[record.GetId()]
+		delete(old, record.GetId())
+		switch {
+		case !ok:
+			created = append(created, &pb.RecordChange{After: record})
+		case !sameRecord(prev, record):
+			updated = append(updated, &pb.RecordChange{Before: prev, After: record})
+		}
+	}
+	for _, record := range before {
+		if _, ok := old[record.GetId()]; ok {
+			deleted = append(deleted, &pb.RecordChange{Before: record})
+		}
+	}
+	return append(append(deleted, updated...), created...)
+}
+
+// rollbackChanges returns the changes which put the records in current back
+// to how they were at at, given the whole history of their zone. Whether
+// records are disabled isn't rolled back, as that is up to the directory:
+// records keep how they are now, and those of disabledOwners are always
+// disabled, so that rolling back never brings back someone who has left.
+func rollbackChanges(current []*pb.DNSRecord, history []*pb.RecordVersion, at ZonePoint,
+	disabledOwners map[string]*interface{}) []*pb.RecordChange {
+	var past []*pb.RecordVersion
+	latest := map[uint64]uint64{}
+	for _, version := range history {
+		if at.includes(version) {
+			past = append(past, version)
+		}
+		latest[version.GetRecordId()] = version.GetRevision()
+	}
+
+	target, _ := ZoneAt(past)
+	disabled := map[uint64]bool{}
+	for _, record := range current {
+		disabled[record.GetId()] = record.GetDisabled()
+	}
+	for _, record := range target {
+		_, ownerDisabled := disabledOwners[record.GetUser()]
+		record.Disabled = disabled[record.GetId()] || ownerDisabled
+	}
+	changes := DiffZone(current, target)
+	// records brought back carry on from their last revision, so their
+	// ETags are never reused
+	for _, change := range changes {
+		if change.GetBefore() == nil {
+			change.After.Revision = latest[change.GetAfter().GetId()]
+		}
+	}
+	return changes
+}
+
+// sameRecord reports whether a and b are the same but for how their zones are
+// written, and their revisions.
+func sameRecord(a, b *pb.DNSRecord) bool {
+	va, vb := recordVersion(a, false), recordVersion(b, false)
+	va.Revision, vb.Revision = 0, 0
+	return proto.Equal(va, vb)
+}
+
+// newVersions returns the versions recording changes, made in one go. bump is
+// called once for each zone changed, and returns its new serial.
+func newVersions(changes []*pb.RecordChange, bump func(zone string) (uint64, error)) ([]*pb.RecordVersion, error) {
+	now := time.Now().Unix()
+	serials := map[string]uint64{}
+	var versions []*pb.RecordVersion
+	add := func(record *pb.DNSRecord, deleted bool) error {
+		zone := zoneKey(record.GetZone())
+		if _, ok := serials[zone]; !ok {
+			serial, err := bump(zone)
+			if err != nil {
+				return err
+			}
+			serials[zone] = serial
+		}
+		version := recordVersion(record, deleted)
+		version.Serial, version.Time = serials[zone], now
+		versions = append(versions, version)
+		return nil
+	}
+
+	for _, change := range changes {
+		before, after := change.GetBefore(), change.GetAfter()
+		// moving a record to another zone deletes it from the old one
+		if before != nil && (after == nil || zoneKey(before.GetZone()) != zoneKey(after.GetZone())) {
+			if err := add(before, true); err != nil {
+				return nil, err
+			}
+		}
+		if after != nil {
+			if err := add(after, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return versions, nil
+}
+
+// recordVersion returns a version holding the fields of record.
+func recordVersion(record *pb.DNSRecord, deleted bool) *pb.RecordVersion {
+	return &pb.RecordVersion{
+		Zone:     zoneKey(record.GetZone()),
+		RecordId: record.GetId(),
+		Deleted:  deleted,
+		Name:     record.GetName(),
+		Type:     record.GetType(),
+		Value:    record.GetValue(),
+		Ttl:      record.GetTtl(),
+		User:     record.GetUser(),
+		Priority: record.GetPriority(),
+		View:     record.GetView(),
+		Disabled: record.GetDisabled(),
+		Revision: record.GetRevision(),
+	}
+}
+
+// versionRecord returns the record a version holds.
+func versionRecord(version *pb.RecordVersion) *pb.DNSRecord {
+	return &pb.DNSRecord{
+		Id:       version.GetRecordId(),
+		Zone:     version.GetZone(),
+		Name:     version.GetName(),
+		Type:     version.GetType(),
+		Value:    version.GetValue(),
+		Ttl:      version.GetTtl(),
+		User:     version.GetUser(),
+		Priority: version.GetPriority(),
+		View:     version.GetView(),
+		Disabled: version.GetDisabled(),
+		Revision: version.GetRevision(),
+	}
+}
+
+// sortRecords sorts records by name, type and ID, like RecordStore.List.
+func sortRecords(records []*pb.DNSRecord) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].GetName() != records[j].GetName() {
+			return records[i].GetName() < records[j].GetName()
+		}
+		if records[i].GetType() != records[j].GetType() {
+			return records[i].GetType() < records[j].GetType()
+		}
+		return records[i].GetId() < records[j].GetId()
+	})
+}
@@ -0,0 +1,224 @@
+package util
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/miekg/dns"
+)
+
+// zoneValues returns the values of records, in order.
+func zoneValues(records []*pb.DNSRecord) []string {
+	var values []string
+	for _, record := range records {
+		values = append(values, record.GetValue())
+	}
+	return values
+}
+
+func TestZoneHistory(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		checkZone := func(at ZonePoint, want ...string) {
+			t.Helper()
+			versions, err := store.ZoneHistory("Valid.Zone", at)
+			if err != nil {
+				t.Fatalf("%s: error reading history: %v", backend, err)
+			}
+			records, _ := ZoneAt(versions)
+			if got := zoneValues(records); strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("%s: zone at %+v = %v, want %v", backend, at, got, want)
+			}
+		}
+		checkSerial := func(want uint64) {
+			t.Helper()
+			if serial, err := store.ZoneSerial("valid.zone."); err != nil || serial != want {
+				t.Errorf("%s: serial = %d, %v, want %d", backend, serial, err, want)
+			}
+		}
+
+		checkSerial(0)
+		a := &pb.DNSRecord{Name: "a", Zone: "valid.zone.", User: "alice",
+			Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 60}
+		b := &pb.DNSRecord{Name: "b", Zone: "valid.zone.", User: "bob",
+			Type: uint32(dns.TypeA), Value: "192.0.2.2", Ttl: 60}
+		for _, record := range []*pb.DNSRecord{a, b} {
+			if err := store.Create(record); err != nil {
+				t.Fatalf("%s: error creating record: %v", backend, err)
+			}
+		}
+		checkSerial(2)
+
+		renumbered := &pb.DNSRecord{Id: a.GetId(), Name: "a", Zone: "valid.zone.", User: "alice",
+			Type: uint32(dns.TypeA), Value: "192.0.2.3", Ttl: 60}
+		if err := store.Update(renumbered, a.GetRevision()); err != nil {
+			t.Fatalf("%s: error updating record: %v", backend, err)
+		}
+		if err := store.Delete(b.GetId()); err != nil {
+			t.Fatalf("%s: error deleting record: %v", backend, err)
+		}
+		checkSerial(4)
+
+		checkZone(ZonePoint{Serial: 1}, "192.0.2.1")
+		checkZone(ZonePoint{Serial: 2}, "192.0.2.1", "192.0.2.2")
+		checkZone(ZonePoint{Serial: 3}, "192.0.2.3", "192.0.2.2")
+		checkZone(ZonePoint{}, "192.0.2.3")
+
+		// what has changed since a serial is only what came after it
+		since, err := store.ZoneHistorySince("Valid.Zone", 2)
+		if err != nil {
+			t.Fatalf("%s: error reading history since serial 2: %v", backend, err)
+		}
+		if len(since) != 2 || since[0].GetSerial() != 3 || since[1].GetSerial() != 4 || !since[1].GetDeleted() {
+			t.Errorf("%s: history since serial 2 = %v, want serials 3 and 4", backend, since)
+		}
+
+		// disabling all of an owner's records is one change
+		if _, err := store.SetOwnerDisabled("alice", true); err != nil {
+			t.Fatalf("%s: error disabling records: %v", backend, err)
+		}
+		checkSerial(5)
+
+		changes, serial, err := store.RollbackZone("valid.zone", ZonePoint{Serial: 2}, 0)
+		if err != nil {
+			t.Fatalf("%s: error rolling back: %v", backend, err)
+		}
+		if len(changes) != 2 || serial != 6 {
+			t.Errorf("%s: rollback made %d changes at serial %d, want 2 at 6", backend, len(changes), serial)
+		}
+		checkZone(ZonePoint{}, "192.0.2.1", "192.0.2.2")
+		records, err := store.List("valid.zone.", "")
+		if err != nil || len(records) != 2 {
+			t.Fatalf("%s: records after rollback = %v, %v", backend, records, err)
+		}
+		// but alice's stays disabled
+		if !records[0].GetDisabled() {
+			t.Errorf("%s: rollback enabled %v", backend, records[0])
+		}
+		// deleted records come back as they were
+		if records[1].GetId() != b.GetId() || records[1].GetUser() != "bob" {
+			t.Errorf("%s: restored record = %v, want %v", backend, records[1], b)
+		}
+		if found, _ := store.Lookup("valid.zone.", "b", ""); len(found) != 1 {
+			t.Errorf("%s: restored record not looked up: %v", backend, found)
+		}
+
+		// rolling back to where the zone already is changes nothing
+		if changes, serial, _ := store.RollbackZone("valid.zone", ZonePoint{Serial: 6}, 0); len(changes) != 0 || serial != 6 {
+			t.Errorf("%s: empty rollback made %d changes at serial %d", backend, len(changes), serial)
+		}
+	}
+}
+
+func TestRollbackKeepsOwnersDisabled(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		record := func(name, user string) *pb.DNSRecord {
+			return &pb.DNSRecord{Name: name, Zone: "valid.zone.", User: user,
+				Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 60}
+		}
+		kept, deleted, other := record("kept", "alice"), record("deleted", "alice"), record("other", "bob")
+		for _, r := range []*pb.DNSRecord{kept, deleted, other} {
+			if err := store.Create(r); err != nil {
+				t.Fatalf("%s: error creating record: %v", backend, err)
+			}
+		}
+		if err := store.Delete(deleted.GetId()); err != nil {
+			t.Fatalf("%s: error deleting record: %v", backend, err)
+		}
+		// alice leaves the directory
+		if _, err := store.SetOwnerDisabled("alice", true); err != nil {
+			t.Fatalf("%s: error disabling records: %v", backend, err)
+		}
+
+		checkDisabled := func(when string, want map[string]bool) {
+			t.Helper()
+			records, err := store.List("valid.zone.", "")
+			if err != nil || len(records) != len(want) {
+				t.Fatalf("%s: %s: records = %v, %v", backend, when, records, err)
+			}
+			for _, r := range records {
+				if r.GetDisabled() != want[r.GetName()] {
+					t.Errorf("%s: %s: %s disabled = %t, want %t", backend, when,
+						r.GetName(), r.GetDisabled(), want[r.GetName()])
+				}
+			}
+		}
+
+		// rolling back to before she left brings back her deleted record,
+		// disabled like the rest of hers
+		if _, _, err := store.RollbackZone("valid.zone.", ZonePoint{Serial: 3}, 0); err != nil {
+			t.Fatalf("%s: error rolling back: %v", backend, err)
+		}
+		checkDisabled("after rollback", map[string]bool{"kept": true, "deleted": true, "other": false})
+
+		// once she is back, rolling back to while she was gone doesn't
+		// disable her again
+		if _, err := store.SetOwnerDisabled("alice", false); err != nil {
+			t.Fatalf("%s: error enabling records: %v", backend, err)
+		}
+		if _, _, err := store.RollbackZone("valid.zone.", ZonePoint{Serial: 5}, 0); err != nil {
+			t.Fatalf("%s: error rolling back: %v", backend, err)
+		}
+		checkDisabled("after she is back", map[string]bool{"kept": false, "other": false})
+	}
+}
+
+func TestDiffZone(t *testing.T) {
+	before := []*pb.DNSRecord{
+		{Id: 1, Name: "a", Zone: "Valid.Zone", Value: "192.0.2.1"},
+		{Id: 2, Name: "b", Zone: "valid.zone.", Value: "192.0.2.2"},
+		{Id: 3, Name: "c", Zone: "valid.zone.", Value: "192.0.2.3"},
+	}
+	after := []*pb.DNSRecord{
+		// only the zone is written differently, which isn't a change
+		{Id: 1, Name: "a", Zone: "valid.zone.", Value: "192.0.2.1"},
+		{Id: 4, Name: "d", Zone: "valid.zone.", Value: "192.0.2.4"},
+		{Id: 3, Name: "c", Zone: "valid.zone.", Value: "192.0.2.5"},
+	}
+
+	changes := DiffZone(before, after)
+	if len(changes) != 3 {
+		t.Fatalf("got %d changes, want 3: %v", len(changes), changes)
+	}
+	if changes[0].GetBefore().GetId() != 2 || changes[0].GetAfter() != nil {
+		t.Errorf("first change = %v, want deleting 2", changes[0])
+	}
+	if changes[1].GetBefore().GetId() != 3 || changes[1].GetAfter().GetValue() != "192.0.2.5" {
+		t.Errorf("second change = %v, want updating 3", changes[1])
+	}
+	if changes[2].GetBefore() != nil || changes[2].GetAfter().GetId() != 4 {
+		t.Errorf("third change = %v, want creating 4", changes[2])
+	}
+}
+
+func TestMigrateRecordVersions(t *testing.T) {
+	db, err := OpenDB(&pb.ServerConfig{
+		StorageBackend: StorageSQLite,
+		DBConf:         &pb.DatabaseConfig{SqlitePath: filepath.Join(t.TempDir(), "drs.db")},
+	})
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	if err := MigrateTo(db, 7); err != nil {
+		t.Fatalf("error migrating: %v", err)
+	}
+	err = db.Exec(`INSERT INTO dns_records (name, type, value, ttl, zone, "user")
+		VALUES ('a', 1, '192.0.2.1', 60, 'Valid.Zone', 'alice')`).Error
+	if err != nil {
+		t.Fatalf("error adding record: %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("error migrating: %v", err)
+	}
+
+	// records from before versions existed are the first version
+	store := &gormStore{db: db}
+	versions, err := store.ZoneHistory("valid.zone.", ZonePoint{})
+	if err != nil || len(versions) != 1 || versions[0].GetSerial() != 1 || versions[0].GetUser() != "alice" {
+		t.Errorf("versions = %v, %v", versions, err)
+	}
+	if serial, err := store.ZoneSerial("valid.zone"); err != nil || serial != 1 {
+		t.Errorf("serial = %d, %v, want 1", serial, err)
+	}
+}
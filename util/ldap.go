@@ -0,0 +1,309 @@
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/gidoBOSSftw5731/log"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// The roles a user can have in the LDAP directory.
+const (
+	roleNone = iota
+	roleMember
+	roleAdmin
+)
+
+// How long Apply remembers what the directory said about a user. Within
+// ldapCacheFresh it doesn't ask again, and up to ldapCacheStale it uses what
+// it remembers if the directory can't be reached.
+const (
+	ldapCacheFresh = time.Minute
+	ldapCacheStale = time.Hour
+)
+
+// LDAPDirectory looks users up in LDAP. It authenticates API requests using
+// HTTP basic auth, decides who is an admin from group membership, and
+// disables the records of users who have left.
+type LDAPDirectory struct {
+	Config *pb.LDAPConfig
+
+	mu sync.Mutex
+	// cache holds the entries Apply last found for users, nil for users who
+	// weren't found
+	cache map[string]ldapCacheEntry
+}
+
+type ldapCacheEntry struct {
+	entry *ldap.Entry
+	found time.Time
+}
+
+// connect dials the directory and binds as the search account.
+func (d *LDAPDirectory) connect() (*ldap.Conn, error) {
+	tlsConfig, err := d.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := ldap.DialURL(d.Config.GetUrl(), ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, err
+	}
+	if d.Config.GetStartTls() {
+		err = conn.StartTLS(tlsConfig)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if d.Config.GetBindDn() != "" {
+		err = conn.Bind(d.Config.GetBindDn(), d.Config.GetBindPassword())
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("error binding to LDAP as %s: %w", d.Config.GetBindDn(), err)
+		}
+	}
+	return conn, nil
+}
+
+// tlsConfig returns how the certificate of the directory is checked, for
+// both ldaps:// and StartTLS.
+func (d *LDAPDirectory) tlsConfig() (*tls.Config, error) {
+	u, err := url.Parse(d.Config.GetUrl())
+	if err != nil {
+		return nil, err
+	}
+	conf := &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	if d.Config.GetCaFile() == "" {
+		return conf, nil
+	}
+
+	certs, err := os.ReadFile(d.Config.GetCaFile())
+	if err != nil {
+		return nil, err
+	}
+	conf.RootCAs, err = x509.SystemCertPool()
+	if err != nil {
+		conf.RootCAs = x509.NewCertPool()
+	}
+	if !conf.RootCAs.AppendCertsFromPEM(certs) {
+		return nil, fmt.Errorf("no certificates in %s", d.Config.GetCaFile())
+	}
+	return conf, nil
+}
+
+// findUser returns the entry of user, or nil if there isn't one.
+func (d *LDAPDirectory) findUser(conn *ldap.Conn, user string) (*ldap.Entry, error) {
+	result, err := conn.Search(ldap.NewSearchRequest(
+		d.Config.GetUserBaseDn(), ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, 0, false,
+		fmt.Sprintf(d.Config.GetUserFilter(), ldap.EscapeFilter(user)),
+		[]string{"dn", d.Config.GetGroupAttr()}, nil,
+	))
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch len(result.Entries) {
+	case 0:
+		return nil, nil
+	case 1:
+		return result.Entries[0], nil
+	}
+	return nil, fmt.Errorf("more than one LDAP entry for user %s", user)
+}
+
+// role works out the role of a user from the groups in their entry.
+func (d *LDAPDirectory) role(entry *ldap.Entry) int {
+	if entry == nil {
+		return roleNone
+	}
+	member := d.Config.GetMemberGroupDn() == ""
+	for _, group := range entry.GetAttributeValues(d.Config.GetGroupAttr()) {
+		if strings.EqualFold(group, d.Config.GetAdminGroupDn()) {
+			return roleAdmin
+		}
+		if strings.EqualFold(group, d.Config.GetMemberGroupDn()) {
+			member = true
+		}
+	}
+	if member {
+		return roleMember
+	}
+	return roleNone
+}
+
+// Authenticate checks the username and password of HTTP basic auth by
+// binding to the directory as the user.
+func (d *LDAPDirectory) Authenticate(req *http.Request) (*Identity, error) {
+	user, password, ok := req.BasicAuth()
+	if !ok {
+		return nil, nil
+	}
+	// an empty password would be an unauthenticated bind, which succeeds
+	if user == "" || password == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	conn, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entry, err := d.findUser(conn, user)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrUnauthenticated
+	}
+	if err := conn.Bind(entry.DN, password); err != nil {
+		log.Errorf("LDAP login for %s failed: %s", user, err)
+		return nil, ErrUnauthenticated
+	}
+
+	return d.identity(user, entry)
+}
+
+// Apply sets whether id is an admin from the directory, for users who were
+// authenticated some other way. It returns ErrForbidden for users who aren't
+// allowed in at all. Users are only looked up once a minute, and while the
+// directory is down those looked up in the last hour are let in as they were
+// then. Nobody else is, as there is no telling who they are.
+func (d *LDAPDirectory) Apply(id *Identity) error {
+	entry, err := d.lookup(id.User)
+	if err != nil {
+		return err
+	}
+	ldapID, err := d.identity(id.User, entry)
+	if err != nil {
+		return err
+	}
+	id.Admin = id.Admin || ldapID.Admin
+	id.Groups = ldapID.Groups
+	return nil
+}
+
+// lookup returns the entry of user, or nil if there isn't one, from the cache
+// if it is fresh or the directory can't be searched.
+func (d *LDAPDirectory) lookup(user string) (*ldap.Entry, error) {
+	d.mu.Lock()
+	cached, ok := d.cache[user]
+	d.mu.Unlock()
+	age := time.Since(cached.found)
+	if ok && age < ldapCacheFresh {
+		return cached.entry, nil
+	}
+
+	entry, err := d.search(user)
+	if err != nil {
+		if ok && age < ldapCacheStale {
+			log.Errorf("Error looking up %s in LDAP, using what it said %v ago: %v",
+				user, age.Round(time.Second), err)
+			return cached.entry, nil
+		}
+		return nil, err
+	}
+
+	d.mu.Lock()
+	if d.cache == nil {
+		d.cache = map[string]ldapCacheEntry{}
+	}
+	d.cache[user] = ldapCacheEntry{entry: entry, found: time.Now()}
+	d.mu.Unlock()
+	return entry, nil
+}
+
+// search connects to the directory to find the entry of user, or nil if
+// there isn't one.
+func (d *LDAPDirectory) search(user string) (*ldap.Entry, error) {
+	conn, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return d.findUser(conn, user)
+}
+
+// identity returns the Identity of user, whose entry is entry.
+func (d *LDAPDirectory) identity(user string, entry *ldap.Entry) (*Identity, error) {
+	role := d.role(entry)
+	if role == roleNone {
+		return nil, ErrForbidden
+	}
+	return &Identity{
+		User:   user,
+		Admin:  role == roleAdmin,
+		Groups: entry.GetAttributeValues(d.Config.GetGroupAttr()),
+	}, nil
+}
+
+// SyncOwners disables the records of every owner who isn't in the directory
+// any more, and enables them again for those who are back. Nothing is
+// disabled if the directory can't be searched. Owners with service tokens
+// aren't in the directory, as Auth.CheckServiceUser makes sure, so are left
+// alone.
+func (d *LDAPDirectory) SyncOwners(store RecordStore) error {
+	owners, err := store.Owners()
+	if err != nil {
+		return err
+	}
+	tokens, err := store.Tokens("")
+	if err != nil {
+		return err
+	}
+	services := map[string]*interface{}{}
+	for _, token := range tokens {
+		if token.GetService() && token.GetRevokedAt() == 0 {
+			services[token.GetUser()] = f
+		}
+	}
+
+	conn, err := d.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, owner := range owners {
+		if _, ok := services[owner]; ok || owner == "" {
+			continue
+		}
+		entry, err := d.findUser(conn, owner)
+		if err != nil {
+			return err
+		}
+		changed, err := store.SetOwnerDisabled(owner, entry == nil)
+		if err != nil {
+			return err
+		}
+		if changed > 0 {
+			log.Infof("Set %d records of %s to disabled=%t", changed, owner, entry == nil)
+		}
+	}
+	return nil
+}
+
+// Sync runs SyncOwners every sync interval until the program exits.
+func (d *LDAPDirectory) Sync(store RecordStore) {
+	interval := time.Duration(d.Config.GetSyncInterval()) * time.Second
+	for {
+		if err := d.SyncOwners(store); err != nil {
+			log.Errorln("Error syncing record owners with LDAP:", err)
+		}
+		time.Sleep(interval)
+	}
+}
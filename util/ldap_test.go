@@ -0,0 +1,371 @@
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/jimlambrt/gldap"
+	"github.com/miekg/dns"
+)
+
+const (
+	testLDAPBindDN   = "cn=drs,dc=example,dc=org"
+	testLDAPUsers    = "ou=people,dc=example,dc=org"
+	testLDAPAdmins   = "cn=rtp,ou=groups,dc=example,dc=org"
+	testLDAPMembers  = "cn=member,ou=groups,dc=example,dc=org"
+	testLDAPPassword = "password"
+)
+
+// testDirectory is an LDAP server holding users, all with testLDAPPassword,
+// and the groups they are in.
+type testDirectory struct {
+	mu    sync.Mutex
+	users map[string][]string
+	url   string
+	// down makes every bind fail, as if the directory couldn't be reached
+	down bool
+	// tls is the server side of StartTLS, which is refused if it is nil
+	tls *tls.Config
+}
+
+func newTestDirectory(t *testing.T, users map[string][]string) *testDirectory {
+	d := &testDirectory{users: users}
+
+	mux, err := gldap.NewMux()
+	if err != nil {
+		t.Fatalf("error making LDAP mux: %v", err)
+	}
+	mux.Bind(d.bind)
+	mux.Search(d.search)
+	mux.ExtendedOperation(d.startTLS, gldap.ExtendedOperationStartTLS)
+	server, err := gldap.NewServer()
+	if err != nil {
+		t.Fatalf("error making LDAP server: %v", err)
+	}
+	server.Router(mux)
+
+	// find a free port
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error finding a port: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	go server.Run(addr)
+	t.Cleanup(func() { server.Stop() })
+	for !server.Ready() {
+		time.Sleep(time.Millisecond)
+	}
+	d.url = "ldap://" + addr
+	return d
+}
+
+func (d *testDirectory) bind(w *gldap.ResponseWriter, r *gldap.Request) {
+	resp := r.NewBindResponse(gldap.WithResponseCode(gldap.ResultInvalidCredentials))
+	defer w.Write(resp)
+	m, err := r.GetSimpleBindMessage()
+	if err != nil || string(m.Password) != testLDAPPassword {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.down {
+		resp.SetResultCode(gldap.ResultUnavailable)
+		return
+	}
+	uid := strings.TrimSuffix(strings.TrimPrefix(m.UserName, "uid="), ","+testLDAPUsers)
+	if _, ok := d.users[uid]; ok || m.UserName == testLDAPBindDN {
+		resp.SetResultCode(gldap.ResultSuccess)
+	}
+}
+
+func (d *testDirectory) startTLS(w *gldap.ResponseWriter, r *gldap.Request) {
+	d.mu.Lock()
+	config := d.tls
+	d.mu.Unlock()
+	resp := r.NewExtendedResponse(gldap.WithResponseCode(gldap.ResultSuccess))
+	resp.SetResponseName(gldap.ExtendedOperationStartTLS)
+	if config == nil {
+		resp.SetResultCode(gldap.ResultUnwillingToPerform)
+		w.Write(resp)
+		return
+	}
+	w.Write(resp)
+	r.StartTLS(config)
+}
+
+// search only understands (uid=<user>) filters, which is all we send.
+func (d *testDirectory) search(w *gldap.ResponseWriter, r *gldap.Request) {
+	resp := r.NewSearchDoneResponse(gldap.WithResponseCode(gldap.ResultSuccess))
+	defer w.Write(resp)
+	m, err := r.GetSearchMessage()
+	if err != nil {
+		resp.SetResultCode(gldap.ResultOperationsError)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	uid := strings.TrimSuffix(strings.TrimPrefix(m.Filter, "(uid="), ")")
+	if groups, ok := d.users[uid]; ok {
+		w.Write(r.NewSearchResponseEntry(fmt.Sprintf("uid=%s,%s", uid, testLDAPUsers),
+			gldap.WithAttributes(map[string][]string{"memberOf": groups})))
+	}
+}
+
+func (d *testDirectory) config() *pb.LDAPConfig {
+	return &pb.LDAPConfig{
+		Url:           d.url,
+		BindDn:        testLDAPBindDN,
+		BindPassword:  testLDAPPassword,
+		UserBaseDn:    testLDAPUsers,
+		UserFilter:    "(uid=%s)",
+		GroupAttr:     "memberOf",
+		AdminGroupDn:  testLDAPAdmins,
+		MemberGroupDn: testLDAPMembers,
+	}
+}
+
+func TestLDAPAuthentication(t *testing.T) {
+	directory := newTestDirectory(t, map[string][]string{
+		"admin":    {testLDAPMembers, testLDAPAdmins},
+		"member":   {testLDAPMembers},
+		"outsider": {"cn=other,ou=groups,dc=example,dc=org"},
+	})
+	auth, err := NewAuth(&pb.ServerConfig{
+		LdapConf: directory.config(),
+		AuthConf: &pb.AuthConfig{
+			ProxyUserHeader: "X-Forwarded-User",
+			TrustedProxies:  []string{"192.0.2.0/24"},
+		},
+	}, NewMemoryStore())
+	if err != nil {
+		t.Fatalf("error setting up auth: %v", err)
+	}
+
+	for _, tc := range []struct {
+		user, password string
+		proxied        bool
+		admin          bool
+		err            error
+	}{
+		{"admin", testLDAPPassword, false, true, nil},
+		{"member", testLDAPPassword, false, false, nil},
+		{"member", "wrong", false, false, ErrUnauthenticated},
+		{"member", "", false, false, ErrUnauthenticated},
+		{"nobody", testLDAPPassword, false, false, ErrUnauthenticated},
+		{"outsider", testLDAPPassword, false, false, ErrForbidden},
+		// roles also apply to users authenticated by other means
+		{"admin", "", true, true, nil},
+		{"outsider", "", true, false, ErrForbidden},
+		{"nobody", "", true, false, ErrForbidden},
+	} {
+		req := httptest.NewRequest("GET", "/v1/listDNSRecords", nil)
+		if tc.proxied {
+			req.Header.Set("X-Forwarded-User", tc.user)
+		} else {
+			req.SetBasicAuth(tc.user, tc.password)
+		}
+
+		id, err := auth.Authenticate(req)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s (proxied %t): err = %v, want %v", tc.user, tc.proxied, err, tc.err)
+			continue
+		}
+		if err == nil && (id.User != tc.user || id.Admin != tc.admin) {
+			t.Errorf("%s (proxied %t): identity = %+v, want admin %t", tc.user, tc.proxied, id, tc.admin)
+		}
+	}
+}
+
+func TestLDAPSyncOwners(t *testing.T) {
+	directory := newTestDirectory(t, map[string][]string{
+		"stays": {testLDAPMembers},
+		"left":  {testLDAPMembers},
+	})
+	ldap := &LDAPDirectory{Config: directory.config()}
+	store := NewMemoryStore()
+	for _, user := range []string{"stays", "left", "service"} {
+		err := store.Create(&pb.DNSRecord{Name: user, Zone: "valid.zone.", User: user,
+			Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 60})
+		if err != nil {
+			t.Fatalf("error creating record: %v", err)
+		}
+	}
+	if err := store.CreateToken(&pb.APIToken{User: "service", Service: true}); err != nil {
+		t.Fatalf("error creating token: %v", err)
+	}
+
+	visible := func(name string) bool {
+		records, _ := store.Lookup("valid.zone.", name, "")
+		return len(records) != 0
+	}
+
+	if err := ldap.SyncOwners(store); err != nil {
+		t.Fatalf("error syncing: %v", err)
+	}
+	for _, name := range []string{"stays", "left", "service"} {
+		if !visible(name) {
+			t.Errorf("records of %s disabled while still in the directory", name)
+		}
+	}
+
+	directory.mu.Lock()
+	delete(directory.users, "left")
+	directory.mu.Unlock()
+	if err := ldap.SyncOwners(store); err != nil {
+		t.Fatalf("error syncing: %v", err)
+	}
+	if visible("left") {
+		t.Errorf("records of a user who left weren't disabled")
+	}
+	if !visible("stays") || !visible("service") {
+		t.Errorf("records of other users were disabled")
+	}
+
+	// coming back enables them again
+	directory.mu.Lock()
+	directory.users["left"] = []string{testLDAPMembers}
+	directory.mu.Unlock()
+	if err := ldap.SyncOwners(store); err != nil {
+		t.Fatalf("error syncing: %v", err)
+	}
+	if !visible("left") {
+		t.Errorf("records of a user who came back weren't enabled")
+	}
+}
+
+func TestLDAPServiceUser(t *testing.T) {
+	directory := newTestDirectory(t, map[string][]string{"alice": {testLDAPMembers}})
+	auth := &Auth{Directory: &LDAPDirectory{Config: directory.config()}}
+
+	// a directory account with a service token would keep it, and its
+	// records, after leaving
+	var errs FieldErrors
+	if err := auth.CheckServiceUser("alice"); !errors.As(err, &errs) || errs[0].GetField() != "user" {
+		t.Errorf("service user in the directory: err = %v, want a field error", err)
+	}
+	if err := auth.CheckServiceUser("backup"); err != nil {
+		t.Errorf("service user outside the directory: err = %v", err)
+	}
+	if err := (&Auth{}).CheckServiceUser("alice"); err != nil {
+		t.Errorf("service user without a directory: err = %v", err)
+	}
+
+	directory.mu.Lock()
+	directory.down = true
+	directory.mu.Unlock()
+	if err := auth.CheckServiceUser("backup"); err == nil {
+		t.Errorf("service user while the directory is down: no error")
+	}
+}
+
+func TestLDAPStartTLS(t *testing.T) {
+	directory := newTestDirectory(t, map[string][]string{"member": {testLDAPMembers}})
+	cert, caFile := newTestCertificate(t)
+	directory.tls = &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	conf := directory.config()
+	conf.StartTls = true
+	req := httptest.NewRequest("GET", "/v1/listDNSRecords", nil)
+	req.SetBasicAuth("member", testLDAPPassword)
+
+	// a certificate we don't trust is refused
+	if _, err := (&LDAPDirectory{Config: conf}).Authenticate(req); err == nil {
+		t.Errorf("authenticated over StartTLS with an untrusted certificate")
+	}
+
+	conf.CaFile = caFile
+	id, err := (&LDAPDirectory{Config: conf}).Authenticate(req)
+	if err != nil || id.User != "member" {
+		t.Errorf("authenticating over StartTLS = %+v, %v", id, err)
+	}
+}
+
+// newTestCertificate returns a self signed certificate for 127.0.0.1, and the
+// path of a file holding it in PEM.
+func newTestCertificate(t *testing.T) (tls.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("error making certificate: %v", err)
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	err = os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	if err != nil {
+		t.Fatalf("error writing certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, caFile
+}
+
+func TestLDAPApplyCache(t *testing.T) {
+	directory := newTestDirectory(t, map[string][]string{"admin": {testLDAPAdmins}})
+	ldap := &LDAPDirectory{Config: directory.config()}
+	apply := func(user string) (*Identity, error) {
+		id := &Identity{User: user}
+		return id, ldap.Apply(id)
+	}
+
+	if id, err := apply("admin"); err != nil || !id.Admin {
+		t.Fatalf("applying admin = %+v, %v", id, err)
+	}
+
+	// while the directory is down, users it was asked about recently keep
+	// what it said, and nobody else gets in
+	directory.mu.Lock()
+	directory.down = true
+	directory.mu.Unlock()
+	ldap.mu.Lock()
+	entry := ldap.cache["admin"]
+	entry.found = entry.found.Add(-2 * ldapCacheFresh)
+	ldap.cache["admin"] = entry
+	ldap.mu.Unlock()
+	if id, err := apply("admin"); err != nil || !id.Admin {
+		t.Errorf("applying admin while the directory is down = %+v, %v", id, err)
+	}
+	if _, err := apply("stranger"); err == nil {
+		t.Errorf("applying someone never looked up while the directory is down didn't fail")
+	}
+
+	// but not for ever
+	ldap.mu.Lock()
+	entry.found = entry.found.Add(-ldapCacheStale)
+	ldap.cache["admin"] = entry
+	ldap.mu.Unlock()
+	if _, err := apply("admin"); err == nil {
+		t.Errorf("applying admin with a stale cache while the directory is down didn't fail")
+	}
+}
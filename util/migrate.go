@@ -0,0 +1,499 @@
+package util
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gidoBOSSftw5731/log"
+	"gorm.io/gorm"
+)
+
+// migration is a single versioned change to the database schema. Each one is
+// run in its own transaction, along with recording that it has been applied.
+type migration struct {
+	version uint32
+	name    string
+	up      func(tx *gorm.DB) error
+	down    func(tx *gorm.DB) error
+}
+
+// migrations are all the changes to the schema, in order. Never change one
+// that has been released, add a new one instead. Migrations must not use the
+// pb structs directly, since those always describe the latest schema.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "create dns_records",
+		// AutoMigrate also brings databases from before migrations existed
+		// up to this point
+		up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&dnsRecordV1{})
+		},
+		down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&dnsRecordV1{})
+		},
+	},
+	{
+		version: 2,
+		name:    "record change notifications",
+		up: func(tx *gorm.DB) error {
+			return execPostgres(tx, recordChangeTrigger)
+		},
+		down: func(tx *gorm.DB) error {
+			return execPostgres(tx, []string{
+				`DROP TRIGGER IF EXISTS drs_record_changes ON dns_records`,
+				`DROP TRIGGER IF EXISTS drs_record_truncate ON dns_records`,
+				`DROP FUNCTION IF EXISTS drs_notify_record_change()`,
+			})
+		},
+	},
+	{
+		version: 3,
+		name:    "record ids, uniqueness and indexes",
+		up: func(tx *gorm.DB) error {
+			// SQLite can't add a primary key to a table, so it gets a new one
+			var stmts []string
+			switch tx.Dialector.Name() {
+			case "postgres":
+				stmts = []string{
+					`ALTER TABLE dns_records ADD COLUMN id BIGSERIAL PRIMARY KEY`,
+				}
+			default:
+				stmts = rebuildRecordsTable(
+					`id INTEGER PRIMARY KEY AUTOINCREMENT, `+dnsRecordV1Columns,
+					dnsRecordV1Names)
+			}
+			if err := execAll(tx, stmts); err != nil {
+				return err
+			}
+			// keep the oldest of any duplicates, so the index can be made,
+			// and write every zone the way records are written from now on,
+			// so the index sees "zone" and "zone." as the same
+			if err := dropDuplicateRecords(tx); err != nil {
+				return err
+			}
+			return execAll(tx, []string{
+				`UPDATE dns_records SET zone = ` + normalizedZone +
+					` WHERE zone <> ` + normalizedZone,
+				// this also serves every lookup by zone, or zone and name, as
+				// those are its leading columns
+				`CREATE UNIQUE INDEX dns_records_unique
+				ON dns_records (LOWER(zone), LOWER(name), type, value)`,
+			})
+		},
+		down: func(tx *gorm.DB) error {
+			stmts := []string{`DROP INDEX dns_records_unique`}
+			switch tx.Dialector.Name() {
+			case "postgres":
+				stmts = append(stmts, `ALTER TABLE dns_records DROP COLUMN id`)
+			default:
+				stmts = append(stmts, rebuildRecordsTable(dnsRecordV1Columns,
+					dnsRecordV1Names)...)
+			}
+			return execAll(tx, stmts)
+		},
+	},
+	{
+		version: 4,
+		name:    "create zone_delegations",
+		up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&zoneDelegationV1{})
+		},
+		down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&zoneDelegationV1{})
+		},
+	},
+	{
+		version: 5,
+		name:    "create api_tokens",
+		up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&apiTokenV1{})
+		},
+		down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&apiTokenV1{})
+		},
+	},
+	{
+		version: 6,
+		name:    "disabled records",
+		up: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE dns_records
+				ADD COLUMN disabled boolean NOT NULL DEFAULT false`).Error
+		},
+		down: func(tx *gorm.DB) error {
+			return tx.Exec(`ALTER TABLE dns_records DROP COLUMN disabled`).Error
+		},
+	},
+	{
+		version: 7,
+		name:    "create audit_entries",
+		up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&auditEntryV1{}); err != nil {
+				return err
+			}
+			// nobody, not even us, gets to rewrite history
+			if tx.Dialector.Name() == "postgres" {
+				return execAll(tx, []string{
+					`CREATE OR REPLACE FUNCTION drs_audit_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_entries is append-only';
+END;
+$$ LANGUAGE plpgsql`,
+					`CREATE TRIGGER drs_audit_append_only BEFORE UPDATE OR DELETE
+					ON audit_entries FOR EACH ROW EXECUTE FUNCTION drs_audit_append_only()`,
+				})
+			}
+			return execAll(tx, []string{
+				`CREATE TRIGGER drs_audit_no_update BEFORE UPDATE ON audit_entries
+				BEGIN SELECT RAISE(ABORT, 'audit_entries is append-only'); END`,
+				`CREATE TRIGGER drs_audit_no_delete BEFORE DELETE ON audit_entries
+				BEGIN SELECT RAISE(ABORT, 'audit_entries is append-only'); END`,
+			})
+		},
+		down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&auditEntryV1{}); err != nil {
+				return err
+			}
+			return execPostgres(tx, []string{
+				`DROP FUNCTION IF EXISTS drs_audit_append_only()`,
+			})
+		},
+	},
+	{
+		version: 8,
+		name:    "create record_versions and zone_serials",
+		up: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&recordVersionV1{}, &zoneSerialV1{}); err != nil {
+				return err
+			}
+			// existing records become the first version of their zones
+			zone := `CASE WHEN zone LIKE '%.' THEN LOWER(zone) ELSE LOWER(zone) || '.' END`
+			err := tx.Exec(`INSERT INTO record_versions (zone, serial, time, record_id,
+				deleted, name, type, value, ttl, "user", priority, view, disabled)
+				SELECT `+zone+`, 1, ?, id, false, name, type, value, ttl, "user",
+				priority, view, disabled FROM dns_records`, time.Now().Unix()).Error
+			if err != nil {
+				return err
+			}
+			return tx.Exec(`INSERT INTO zone_serials (zone, serial)
+				SELECT DISTINCT ` + zone + `, 1 FROM dns_records`).Error
+		},
+		down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&recordVersionV1{}, &zoneSerialV1{})
+		},
+	},
+	{
+		version: 9,
+		name:    "record revisions",
+		up: func(tx *gorm.DB) error {
+			return execAll(tx, []string{
+				`ALTER TABLE dns_records ADD COLUMN revision bigint NOT NULL DEFAULT 1`,
+				`ALTER TABLE record_versions ADD COLUMN revision bigint NOT NULL DEFAULT 1`,
+			})
+		},
+		down: func(tx *gorm.DB) error {
+			return execAll(tx, []string{
+				`ALTER TABLE dns_records DROP COLUMN revision`,
+				`ALTER TABLE record_versions DROP COLUMN revision`,
+			})
+		},
+	},
+}
+
+// normalizedZone is the zone of a row lower cased and with the trailing dot,
+// as zoneKey would make it.
+const normalizedZone = `CASE WHEN zone LIKE '%.' THEN LOWER(zone) ELSE LOWER(zone) || '.' END`
+
+// dropDuplicateRecords deletes every record which is the same as an older one
+// once its zone is normalized, logging each so whoever runs the migration
+// knows what went.
+func dropDuplicateRecords(tx *gorm.DB) error {
+	duplicates := `FROM dns_records WHERE id NOT IN (SELECT MIN(id)
+		FROM dns_records GROUP BY ` + normalizedZone + `, LOWER(name), type, value)`
+
+	var removed []struct {
+		Id    uint64
+		Zone  string
+		Name  string
+		Type  uint32
+		Value string
+	}
+	err := tx.Raw(`SELECT id, zone, name, type, value ` + duplicates).Scan(&removed).Error
+	if err != nil {
+		return err
+	}
+	for _, r := range removed {
+		log.Infof("Removing duplicate record %d: %s in %s, type %d, value %q",
+			r.Id, r.Name, r.Zone, r.Type, r.Value)
+	}
+	if len(removed) != 0 {
+		log.Infof("Removed %d duplicate records", len(removed))
+	}
+	return tx.Exec(`DELETE ` + duplicates).Error
+}
+
+// the columns of the dns_records table as made by version 1, so tables can
+// be rebuilt on databases which can't alter them
+const (
+	dnsRecordV1Columns = `name text, type integer, value text, ttl integer,
+		zone text, "user" text, priority text, view text`
+	dnsRecordV1Names = `name, type, value, ttl, zone, "user", priority, view`
+)
+
+// rebuildRecordsTable returns the statements to replace the dns_records
+// table with one with the given column definitions, copying over the named
+// columns.
+func rebuildRecordsTable(columns, names string) []string {
+	return []string{
+		`CREATE TABLE dns_records_new (` + columns + `)`,
+		`INSERT INTO dns_records_new (` + names + `) SELECT ` + names + ` FROM dns_records`,
+		`DROP TABLE dns_records`,
+		`ALTER TABLE dns_records_new RENAME TO dns_records`,
+	}
+}
+
+// dnsRecordV1 is the dns_records table as it was first made.
+type dnsRecordV1 struct {
+	Name     string
+	Type     uint32
+	Value    string
+	Ttl      uint32
+	Zone     string
+	User     string
+	Priority string
+	View     string
+}
+
+func (dnsRecordV1) TableName() string { return "dns_records" }
+
+// schemaVersionV1 is the schema_versions table, which records the migrations
+// applied, as it was first made. Unlike the others it is made outside of any
+// migration, so it must never change.
+type schemaVersionV1 struct {
+	Version uint32
+	Name    string
+	// AppliedAt is a unix timestamp.
+	AppliedAt int64
+}
+
+func (schemaVersionV1) TableName() string { return "schema_versions" }
+
+// zoneDelegationV1 is the zone_delegations table as it was first made. Zones
+// are stored lower cased, so the unique index needn't be.
+type zoneDelegationV1 struct {
+	Id   uint64 `gorm:"primaryKey"`
+	Zone string `gorm:"uniqueIndex:zone_delegations_unique"`
+	User string `gorm:"uniqueIndex:zone_delegations_unique"`
+}
+
+func (zoneDelegationV1) TableName() string { return "zone_delegations" }
+
+// apiTokenV1 is the api_tokens table as it was first made.
+type apiTokenV1 struct {
+	Id          uint64 `gorm:"primaryKey"`
+	User        string `gorm:"index"`
+	Service     bool
+	Description string
+	Hash        string `gorm:"uniqueIndex"`
+	Write       bool
+	Patterns    string
+	CreatedAt   int64
+	ExpiresAt   int64
+	RevokedAt   int64
+}
+
+func (apiTokenV1) TableName() string { return "api_tokens" }
+
+// auditEntryV1 is the audit_entries table as it was first made.
+type auditEntryV1 struct {
+	Id       uint64 `gorm:"primaryKey"`
+	Time     int64  `gorm:"index"`
+	Actor    string `gorm:"index"`
+	SourceIp string
+	Action   string
+	Zone     string `gorm:"index:audit_entries_zone_name"`
+	Name     string `gorm:"index:audit_entries_zone_name"`
+	Before   string
+	After    string
+}
+
+func (auditEntryV1) TableName() string { return "audit_entries" }
+
+// recordVersionV1 is the record_versions table as it was first made.
+type recordVersionV1 struct {
+	Id       uint64 `gorm:"primaryKey"`
+	Zone     string `gorm:"index:record_versions_zone_serial"`
+	Serial   uint64 `gorm:"index:record_versions_zone_serial"`
+	Time     int64
+	RecordId uint64
+	Deleted  bool
+	Name     string
+	Type     uint32
+	Value    string
+	Ttl      uint32
+	User     string
+	Priority string
+	View     string
+	Disabled bool
+}
+
+func (recordVersionV1) TableName() string { return "record_versions" }
+
+// zoneSerialV1 is the zone_serials table as it was first made. Zones are
+// stored lower cased, with a trailing dot.
+type zoneSerialV1 struct {
+	Zone   string `gorm:"primaryKey"`
+	Serial uint64
+}
+
+func (zoneSerialV1) TableName() string { return "zone_serials" }
+
+// recordChangeTrigger makes Postgres announce every change to a DNS record on
+// recordChangeChannel. TRUNCATE can't say which zone changed, so it sends an
+// empty payload meaning "everything".
+var recordChangeTrigger = []string{
+	`CREATE OR REPLACE FUNCTION drs_notify_record_change() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'TRUNCATE' THEN
+		PERFORM pg_notify('` + recordChangeChannel + `', '');
+		RETURN NULL;
+	END IF;
+	IF TG_OP IN ('UPDATE', 'DELETE') THEN
+		PERFORM pg_notify('` + recordChangeChannel + `', OLD.zone);
+	END IF;
+	IF TG_OP IN ('INSERT', 'UPDATE') THEN
+		PERFORM pg_notify('` + recordChangeChannel + `', NEW.zone);
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS drs_record_changes ON dns_records`,
+	`CREATE TRIGGER drs_record_changes AFTER INSERT OR UPDATE OR DELETE
+	ON dns_records FOR EACH ROW EXECUTE FUNCTION drs_notify_record_change()`,
+	`DROP TRIGGER IF EXISTS drs_record_truncate ON dns_records`,
+	`CREATE TRIGGER drs_record_truncate AFTER TRUNCATE
+	ON dns_records FOR EACH STATEMENT EXECUTE FUNCTION drs_notify_record_change()`,
+}
+
+// LatestSchemaVersion is the schema version this binary expects.
+func LatestSchemaVersion() uint32 {
+	return migrations[len(migrations)-1].version
+}
+
+// SchemaVersion returns the version the database schema is at, 0 meaning no
+// migrations have been run.
+func SchemaVersion(db *gorm.DB) (uint32, error) {
+	err := db.AutoMigrate(&schemaVersionV1{})
+	if err != nil {
+		return 0, err
+	}
+
+	var version uint32
+	err = db.Model(&schemaVersionV1{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// Migrate runs every migration the database hasn't had yet. It refuses to do
+// anything if the database is newer than this binary, since we'd have no idea
+// what the schema looks like.
+func Migrate(db *gorm.DB) error {
+	return MigrateTo(db, LatestSchemaVersion())
+}
+
+// MigrateTo runs migrations up or down until the database schema is at
+// version target.
+func MigrateTo(db *gorm.DB, target uint32) error {
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > LatestSchemaVersion() {
+		return fmt.Errorf("database schema version %d is newer than this binary supports (%d)",
+			current, LatestSchemaVersion())
+	}
+	if target > LatestSchemaVersion() {
+		return fmt.Errorf("there is no schema version %d", target)
+	}
+
+	// up
+	for _, m := range migrations {
+		if m.version <= current || m.version > target {
+			continue
+		}
+		log.Infof("Migrating database up to version %d (%s)", m.version, m.name)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaVersionV1{
+				Version:   m.version,
+				Name:      m.name,
+				AppliedAt: time.Now().Unix(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("error migrating to version %d: %w", m.version, err)
+		}
+	}
+
+	// down
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.version > current || m.version <= target {
+			continue
+		}
+		log.Infof("Migrating database down from version %d (%s)", m.version, m.name)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.down(tx); err != nil {
+				return err
+			}
+			return tx.Where("version = ?", m.version).Delete(&schemaVersionV1{}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("error migrating down from version %d: %w", m.version, err)
+		}
+	}
+
+	return nil
+}
+
+// MigrationStatus describes every migration, and whether it has been applied
+// to the database.
+func MigrationStatus(db *gorm.DB) ([]string, error) {
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []string
+	for _, m := range migrations {
+		state := "pending"
+		if m.version <= current {
+			state = "applied"
+		}
+		ret = append(ret, fmt.Sprintf("%4d %-8s %s", m.version, state, m.name))
+	}
+	if current > LatestSchemaVersion() {
+		ret = append(ret, fmt.Sprintf("database is at version %d, which is newer than this binary", current))
+	}
+	return ret, nil
+}
+
+// execPostgres runs stmts, but only on Postgres. Other databases don't have
+// what they need.
+func execPostgres(tx *gorm.DB, stmts []string) error {
+	if tx.Dialector.Name() != "postgres" {
+		return nil
+	}
+	return execAll(tx, stmts)
+}
+
+// execAll runs each of stmts in turn, stopping at the first error.
+func execAll(tx *gorm.DB, stmts []string) error {
+	for _, stmt := range stmts {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package util
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+func TestMigrate(t *testing.T) {
+	db, err := OpenDB(&pb.ServerConfig{
+		StorageBackend: StorageSQLite,
+		DBConf:         &pb.DatabaseConfig{SqlitePath: filepath.Join(t.TempDir(), "drs.db")},
+	})
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+
+	checkVersion := func(want uint32) {
+		t.Helper()
+		version, err := SchemaVersion(db)
+		if err != nil {
+			t.Fatalf("error getting schema version: %v", err)
+		}
+		if version != want {
+			t.Fatalf("schema version = %d, want %d", version, want)
+		}
+	}
+
+	checkVersion(0)
+	if err := Migrate(db); err != nil {
+		t.Fatalf("error migrating: %v", err)
+	}
+	checkVersion(LatestSchemaVersion())
+	if !db.Migrator().HasTable("dns_records") {
+		t.Fatalf("dns_records table not created")
+	}
+	// what has been applied is kept the same way whatever the protos say
+	columns, err := db.Migrator().ColumnTypes("schema_versions")
+	if err != nil {
+		t.Fatalf("error reading schema_versions: %v", err)
+	}
+	var names []string
+	for _, column := range columns {
+		names = append(names, column.Name())
+	}
+	if strings.Join(names, " ") != "version name applied_at" {
+		t.Errorf("schema_versions columns = %v, want [version name applied_at]", names)
+	}
+
+	// running it again does nothing
+	if err := Migrate(db); err != nil {
+		t.Fatalf("error migrating again: %v", err)
+	}
+	checkVersion(LatestSchemaVersion())
+
+	// all the way down and back up again
+	if err := MigrateTo(db, 0); err != nil {
+		t.Fatalf("error migrating down: %v", err)
+	}
+	checkVersion(0)
+	if db.Migrator().HasTable("dns_records") {
+		t.Fatalf("dns_records table not dropped")
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("error migrating back up: %v", err)
+	}
+	checkVersion(LatestSchemaVersion())
+
+	// zones written differently are normalized, keeping the oldest of those
+	// which turn out to be the same
+	if err := MigrateTo(db, 2); err != nil {
+		t.Fatalf("error migrating down to version 2: %v", err)
+	}
+	for _, zone := range []string{"Valid.Zone", "valid.zone.", "other.zone"} {
+		err := db.Exec(`INSERT INTO dns_records (name, type, value, ttl, zone)
+			VALUES ('host', 1, '192.0.2.1', 60, ?)`, zone).Error
+		if err != nil {
+			t.Fatalf("error adding record in %s: %v", zone, err)
+		}
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("error migrating up from version 2: %v", err)
+	}
+	var zones []string
+	db.Raw(`SELECT zone FROM dns_records ORDER BY id`).Scan(&zones)
+	if strings.Join(zones, " ") != "valid.zone. other.zone." {
+		t.Errorf("zones after normalizing = %v, want [valid.zone. other.zone.]", zones)
+	}
+
+	// a database from the future is left alone
+	db.Create(&schemaVersionV1{Version: LatestSchemaVersion() + 1, Name: "from the future"})
+	if err := Migrate(db); err == nil {
+		t.Fatalf("migrating a newer database didn't fail")
+	}
+}
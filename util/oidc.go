@@ -0,0 +1,63 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/gidoBOSSftw5731/log"
+)
+
+// OIDCAuthenticator authenticates requests carrying an ID token from our
+// OpenID Connect provider as a bearer token. The signature is checked against
+// the keys the provider publishes, along with the issuer, audience and
+// expiry.
+type OIDCAuthenticator struct {
+	verifier *oidc.IDTokenVerifier
+	// userClaim is the claim holding the name of the user
+	userClaim string
+}
+
+// NewOIDCAuthenticator discovers the provider at the issuer in conf, so it
+// fails if the provider can't be reached.
+func NewOIDCAuthenticator(ctx context.Context, conf *pb.AuthConfig) (*OIDCAuthenticator, error) {
+	provider, err := oidc.NewProvider(ctx, conf.GetOidcIssuer())
+	if err != nil {
+		return nil, fmt.Errorf("error discovering OIDC provider: %w", err)
+	}
+	userClaim := conf.GetOidcUserClaim()
+	if userClaim == "" {
+		userClaim = "sub"
+	}
+	return &OIDCAuthenticator{
+		verifier:  provider.Verifier(&oidc.Config{ClientID: conf.GetOidcAudience()}),
+		userClaim: userClaim,
+	}, nil
+}
+
+func (a *OIDCAuthenticator) Authenticate(req *http.Request) (*Identity, error) {
+	token, ok := bearerToken(req)
+	// our own API tokens are left to the TokenAuthenticator
+	if !ok || strings.HasPrefix(token, tokenPrefix) {
+		return nil, nil
+	}
+
+	idToken, err := a.verifier.Verify(req.Context(), token)
+	if err != nil {
+		log.Errorln("Invalid OIDC token:", err)
+		return nil, ErrUnauthenticated
+	}
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	user, _ := claims[a.userClaim].(string)
+	if user == "" {
+		log.Errorf("OIDC token for %s has no %s claim", idToken.Subject, a.userClaim)
+		return nil, ErrUnauthenticated
+	}
+	return &Identity{User: user}, nil
+}
@@ -0,0 +1,143 @@
+package util
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/go-jose/go-jose/v3"
+)
+
+// stubIssuer is an OpenID Connect provider which publishes a single key, and
+// can sign tokens with it.
+type stubIssuer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	s := &stubIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(resp http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(resp).Encode(map[string]interface{}{
+			"issuer":                                s.URL,
+			"jwks_uri":                              s.URL + "/keys",
+			"authorization_endpoint":                s.URL + "/auth",
+			"token_endpoint":                        s.URL + "/token",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(resp http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(resp).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"},
+		}})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// sign returns a token with claims signed by key.
+func (s *stubIssuer) sign(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithHeader("kid", "test"))
+	if err != nil {
+		t.Fatalf("error making signer: %v", err)
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("error marshalling claims: %v", err)
+	}
+	sig, err := signer.Sign(payload)
+	if err != nil {
+		t.Fatalf("error signing token: %v", err)
+	}
+	token, err := sig.CompactSerialize()
+	if err != nil {
+		t.Fatalf("error serializing token: %v", err)
+	}
+	return token
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	issuer := newStubIssuer(t)
+	authenticator, err := NewOIDCAuthenticator(context.Background(), &pb.AuthConfig{
+		OidcIssuer:    issuer.URL,
+		OidcAudience:  "drs",
+		OidcUserClaim: "preferred_username",
+	})
+	if err != nil {
+		t.Fatalf("error setting up OIDC: %v", err)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+	claims := func(change func(map[string]interface{})) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":                issuer.URL,
+			"aud":                "drs",
+			"sub":                "1234",
+			"preferred_username": "alice",
+			"iat":                time.Now().Unix(),
+			"exp":                time.Now().Add(time.Hour).Unix(),
+		}
+		if change != nil {
+			change(c)
+		}
+		return c
+	}
+
+	for _, tc := range []struct {
+		desc  string
+		token string
+		user  string
+		err   error
+	}{
+		{"valid", issuer.sign(t, issuer.key, claims(nil)), "alice", nil},
+		{"wrong key", issuer.sign(t, otherKey, claims(nil)), "", ErrUnauthenticated},
+		{"wrong issuer", issuer.sign(t, issuer.key, claims(func(c map[string]interface{}) {
+			c["iss"] = "https://example.com"
+		})), "", ErrUnauthenticated},
+		{"wrong audience", issuer.sign(t, issuer.key, claims(func(c map[string]interface{}) {
+			c["aud"] = "someone-else"
+		})), "", ErrUnauthenticated},
+		{"expired", issuer.sign(t, issuer.key, claims(func(c map[string]interface{}) {
+			c["exp"] = time.Now().Add(-time.Hour).Unix()
+		})), "", ErrUnauthenticated},
+		{"no user claim", issuer.sign(t, issuer.key, claims(func(c map[string]interface{}) {
+			delete(c, "preferred_username")
+		})), "", ErrUnauthenticated},
+	} {
+		req := httptest.NewRequest("GET", "/v1/listDNSRecords", nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		id, err := authenticator.Authenticate(req)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: err = %v, want %v", tc.desc, err, tc.err)
+			continue
+		}
+		if err == nil && id.User != tc.user {
+			t.Errorf("%s: user = %s, want %s", tc.desc, id.User, tc.user)
+		}
+	}
+
+	// API tokens aren't for us
+	req := httptest.NewRequest("GET", "/v1/listDNSRecords", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenPrefix+"abc")
+	if id, err := authenticator.Authenticate(req); id != nil || err != nil {
+		t.Errorf("API token: got %v, %v, want nothing", id, err)
+	}
+}
@@ -0,0 +1,265 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+)
+
+// QuotaError is returned when changing records would take their owner over
+// their quota.
+type QuotaError struct {
+	// What is the kind of thing there would be too many of
+	What  string
+	Limit uint32
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("quota exceeded: at most %d %s allowed", e.Limit, e.What)
+}
+
+// Quotas limits how many devices, records and wildcards users can have.
+type Quotas struct {
+	Config *pb.QuotaConfig
+	Store  RecordStore
+	// Directory, if set, is where the groups of owners other than the
+	// caller are looked up.
+	Directory *LDAPDirectory
+}
+
+// Limit returns the quota of id.
+func (q *Quotas) Limit(id *Identity) *pb.Quota {
+	if quota, ok := q.Config.GetUsers()[id.User]; ok {
+		return quota
+	}
+
+	var groupQuota *pb.Quota
+	for _, group := range id.Groups {
+		quota, ok := q.Config.GetGroups()[group]
+		if !ok {
+			continue
+		}
+		if groupQuota == nil {
+			groupQuota = &pb.Quota{MaxDevices: quota.GetMaxDevices(),
+				MaxRecords: quota.GetMaxRecords(), MaxWildcards: quota.GetMaxWildcards()}
+			continue
+		}
+		groupQuota.MaxDevices = moreGenerous(groupQuota.MaxDevices, quota.GetMaxDevices())
+		groupQuota.MaxRecords = moreGenerous(groupQuota.MaxRecords, quota.GetMaxRecords())
+		groupQuota.MaxWildcards = moreGenerous(groupQuota.MaxWildcards, quota.GetMaxWildcards())
+	}
+	if groupQuota != nil {
+		return groupQuota
+	}
+	if q.Config.GetDefaultQuota() != nil {
+		return q.Config.GetDefaultQuota()
+	}
+	return &pb.Quota{}
+}
+
+// moreGenerous returns the larger of two limits, where 0 is no limit.
+func moreGenerous(a, b uint32) uint32 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Usage returns how much of their quota id has used. Admins have no limit.
+func (q *Quotas) Usage(id *Identity) (*pb.QuotaUsage, error) {
+	records, err := q.Store.OwnedRecords(id.User)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := usageOf(records)
+	usage.User, usage.Limit = id.User, q.Limit(id)
+	if id.Admin {
+		usage.Limit = &pb.Quota{}
+	}
+	return usage, nil
+}
+
+// CheckChanges returns a *QuotaError if changes, which have just been made
+// through tx by id, took any owner of the records changed over their quota.
+// Delegates can change records they don't own, so each owner is held to
+// their own quota rather than id's. It looks at every record an owner has
+// once all of the changes are made, so records deleted, replaced or renamed
+// count as well as those added. Owners who were already over their quota,
+// because it was lowered, can still have changes made which don't add to
+// what they are over by. Admins have no quota, and changes made by them
+// aren't checked.
+//
+// It must be called in the same Transaction as the changes were made in, so
+// nobody else can change the owners' records before they are committed.
+func (q *Quotas) CheckChanges(tx RecordStore, id *Identity, changes []*pb.RecordChange) error {
+	if id.Admin {
+		return nil
+	}
+
+	// only owners of the records as they are now can have gained any
+	var owners []string
+	seen := map[string]*interface{}{}
+	for _, change := range changes {
+		if after := change.GetAfter(); after != nil {
+			if _, ok := seen[after.GetUser()]; !ok {
+				seen[after.GetUser()] = f
+				owners = append(owners, after.GetUser())
+			}
+		}
+	}
+
+	for _, user := range owners {
+		owner, err := q.owner(id, user)
+		if err != nil {
+			return err
+		}
+		if owner.Admin {
+			continue
+		}
+		if err := q.checkOwner(tx, owner, changes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// owner returns the Identity of user, whose records id changed, for working
+// out their quota. Their groups come from the directory, if there is one.
+// Owners the directory doesn't let in, such as those who have left, get the
+// quota of a user in no groups.
+func (q *Quotas) owner(id *Identity, user string) (*Identity, error) {
+	if user == id.User {
+		return id, nil
+	}
+	owner := &Identity{User: user}
+	if q.Directory == nil {
+		return owner, nil
+	}
+	err := q.Directory.Apply(owner)
+	if errors.Is(err, ErrForbidden) {
+		return owner, nil
+	}
+	return owner, err
+}
+
+// checkOwner returns a *QuotaError if changes took owner over their quota.
+func (q *Quotas) checkOwner(tx RecordStore, owner *Identity, changes []*pb.RecordChange) error {
+	records, err := tx.OwnedRecords(owner.User)
+	if err != nil {
+		return err
+	}
+
+	// undoing the changes gives what owner had before them
+	before := map[uint64]*pb.DNSRecord{}
+	for _, record := range records {
+		before[record.GetId()] = record
+	}
+	for _, change := range changes {
+		delete(before, change.GetAfter().GetId())
+	}
+	for _, change := range changes {
+		if old := change.GetBefore(); old != nil && old.GetUser() == owner.User {
+			before[old.GetId()] = old
+		}
+	}
+	var beforeRecords []*pb.DNSRecord
+	for _, record := range before {
+		beforeRecords = append(beforeRecords, record)
+	}
+
+	limit := q.Limit(owner)
+	was, is := usageOf(beforeRecords), usageOf(records)
+	for _, count := range []struct {
+		what           string
+		limit, was, is uint32
+	}{
+		{"records", limit.GetMaxRecords(), was.GetRecords(), is.GetRecords()},
+		{"devices", limit.GetMaxDevices(), was.GetDevices(), is.GetDevices()},
+		{"wildcards", limit.GetMaxWildcards(), was.GetWildcards(), is.GetWildcards()},
+	} {
+		if count.limit != 0 && count.is > count.limit && count.is > count.was {
+			return &QuotaError{What: count.what, Limit: count.limit}
+		}
+	}
+	return nil
+}
+
+// usageOf counts the devices, records and wildcards in records.
+func usageOf(records []*pb.DNSRecord) *pb.QuotaUsage {
+	usage := &pb.QuotaUsage{}
+	devices := map[string]*interface{}{}
+	for _, record := range records {
+		usage.Records++
+		devices[deviceKey(record)] = f
+		if isWildcard(record) {
+			usage.Wildcards++
+		}
+	}
+	usage.Devices = uint32(len(devices))
+	return usage
+}
+
+// deviceKey returns the device a record belongs to, which is its name.
+func deviceKey(record *pb.DNSRecord) string {
+	return strings.ToLower(processFullName(record))
+}
+
+// Devices groups records into the devices they belong to, in the order each
+// device first shows up.
+func Devices(records []*pb.DNSRecord) []*pb.Device {
+	var devices []*pb.Device
+	byKey := map[string]*pb.Device{}
+	for _, record := range records {
+		device, ok := byKey[deviceKey(record)]
+		if !ok {
+			device = &pb.Device{Zone: record.GetZone(), Name: record.GetName()}
+			byKey[deviceKey(record)] = device
+			devices = append(devices, device)
+		}
+		device.Records = append(device.Records, record)
+	}
+	return devices
+}
+
+func isWildcard(record *pb.DNSRecord) bool {
+	return strings.HasPrefix(record.GetName(), "*")
+}
+
+// ParseQuotas parses quotas in the form
+// "name:devices/records/wildcards;name:devices/records/wildcards", where
+// name is a user or the DN of a group.
+func ParseQuotas(s string) (map[string]*pb.Quota, error) {
+	quotas := map[string]*pb.Quota{}
+	for _, entry := range strings.Split(s, ";") {
+		i := strings.LastIndex(entry, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid quota %q", entry)
+		}
+		limits := strings.Split(entry[i+1:], "/")
+		if len(limits) != 3 {
+			return nil, fmt.Errorf("invalid quota %q", entry)
+		}
+
+		var parsed [3]uint32
+		for j, limit := range limits {
+			n, err := strconv.ParseUint(limit, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quota %q: %w", entry, err)
+			}
+			parsed[j] = uint32(n)
+		}
+		quotas[strings.TrimSpace(entry[:i])] = &pb.Quota{
+			MaxDevices:   parsed[0],
+			MaxRecords:   parsed[1],
+			MaxWildcards: parsed[2],
+		}
+	}
+	return quotas, nil
+}
@@ -0,0 +1,229 @@
+package util
+
+import (
+	"errors"
+	"testing"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/miekg/dns"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestQuotaLimit(t *testing.T) {
+	quotas := &Quotas{Config: &pb.QuotaConfig{
+		DefaultQuota: &pb.Quota{MaxDevices: 1, MaxRecords: 2, MaxWildcards: 0},
+		Users:        map[string]*pb.Quota{"alice": {MaxDevices: 5}},
+		Groups: map[string]*pb.Quota{
+			"cn=a": {MaxDevices: 3, MaxRecords: 10, MaxWildcards: 1},
+			"cn=b": {MaxDevices: 4, MaxRecords: 0, MaxWildcards: 1},
+		},
+	}}
+
+	for _, tc := range []struct {
+		id   *Identity
+		want *pb.Quota
+	}{
+		{&Identity{User: "bob"}, &pb.Quota{MaxDevices: 1, MaxRecords: 2}},
+		{&Identity{User: "alice", Groups: []string{"cn=a"}}, &pb.Quota{MaxDevices: 5}},
+		{&Identity{User: "bob", Groups: []string{"cn=a"}}, &pb.Quota{MaxDevices: 3, MaxRecords: 10, MaxWildcards: 1}},
+		{&Identity{User: "bob", Groups: []string{"cn=a", "cn=b", "cn=c"}}, &pb.Quota{MaxDevices: 4, MaxWildcards: 1}},
+	} {
+		got := quotas.Limit(tc.id)
+		if got.GetMaxDevices() != tc.want.GetMaxDevices() || got.GetMaxRecords() != tc.want.GetMaxRecords() ||
+			got.GetMaxWildcards() != tc.want.GetMaxWildcards() {
+			t.Errorf("limit of %+v = %v, want %v", tc.id, got, tc.want)
+		}
+	}
+}
+
+// applyWithQuota makes changes as id in a transaction, checking id's quota
+// like the API does.
+func applyWithQuota(store RecordStore, quotas *Quotas, id *Identity, changes ...*pb.RecordChange) error {
+	return store.Transaction(func(tx RecordStore) error {
+		if err := tx.Apply(changes); err != nil {
+			return err
+		}
+		return quotas.CheckChanges(tx, id, changes)
+	})
+}
+
+func TestQuotaCheck(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		quotas := &Quotas{Store: store, Config: &pb.QuotaConfig{
+			DefaultQuota: &pb.Quota{MaxDevices: 2, MaxRecords: 3, MaxWildcards: 1},
+		}}
+		alice := &Identity{User: "alice"}
+		record := func(name, value string) *pb.DNSRecord {
+			return &pb.DNSRecord{Name: name, Zone: "valid.zone.", User: "alice",
+				Type: uint32(dns.TypeA), Value: value, Ttl: 60}
+		}
+
+		for _, tc := range []struct {
+			record *pb.DNSRecord
+			what   string
+		}{
+			{record("one", "192.0.2.1"), ""},
+			{record("*", "192.0.2.1"), ""},
+			// a second wildcard, or a third device, is too many
+			{record("*.sub", "192.0.2.1"), "devices"},
+			{record("three", "192.0.2.1"), "devices"},
+			{record("one", "192.0.2.2"), ""},
+			{record("one", "192.0.2.3"), "records"},
+		} {
+			err := applyWithQuota(store, quotas, alice, &pb.RecordChange{After: tc.record})
+			var quotaErr *QuotaError
+			switch {
+			case tc.what == "" && err != nil:
+				t.Errorf("%s: %s: unexpected error %v", backend, tc.record.GetName(), err)
+			case tc.what != "" && (!errors.As(err, &quotaErr) || quotaErr.What != tc.what):
+				t.Errorf("%s: %s: err = %v, want too many %s", backend, tc.record.GetName(), err, tc.what)
+			}
+		}
+
+		usage, err := quotas.Usage(alice)
+		if err != nil {
+			t.Fatalf("%s: error getting usage: %v", backend, err)
+		}
+		if usage.GetDevices() != 2 || usage.GetRecords() != 3 || usage.GetWildcards() != 1 {
+			t.Errorf("%s: usage = %v", backend, usage)
+		}
+
+		// admins have no quota
+		err = applyWithQuota(store, quotas, &Identity{User: "alice", Admin: true},
+			&pb.RecordChange{After: record("four", "192.0.2.1")})
+		if err != nil {
+			t.Errorf("%s: admin over quota: %v", backend, err)
+		}
+	}
+}
+
+func TestQuotaCheckChanges(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		quotas := &Quotas{Store: store, Config: &pb.QuotaConfig{
+			DefaultQuota: &pb.Quota{MaxDevices: 2, MaxRecords: 3, MaxWildcards: 1},
+		}}
+		alice := &Identity{User: "alice"}
+		record := func(name, value string) *pb.DNSRecord {
+			return &pb.DNSRecord{Name: name, Zone: "valid.zone.", User: "alice",
+				Type: uint32(dns.TypeA), Value: value, Ttl: 60}
+		}
+		host := record("host", "192.0.2.1")
+		for _, r := range []*pb.DNSRecord{host, record("host", "192.0.2.2"), record("*", "192.0.2.3")} {
+			if err := store.Create(r); err != nil {
+				t.Fatalf("%s: error creating record: %v", backend, err)
+			}
+		}
+		var quotaErr *QuotaError
+
+		// deleting a record makes room for another, in either order
+		renumbered := record("host", "192.0.2.4")
+		err := applyWithQuota(store, quotas, alice, &pb.RecordChange{After: renumbered},
+			&pb.RecordChange{Before: host})
+		if err != nil {
+			t.Fatalf("%s: renumbering: err = %v", backend, err)
+		}
+
+		// but renaming a record can't get round the limit on devices, nor on
+		// wildcards
+		for _, tc := range []struct{ name, what string }{
+			{"other", "devices"},
+			{"*", "wildcards"},
+		} {
+			renamed := proto.Clone(renumbered).(*pb.DNSRecord)
+			renamed.Name = tc.name
+			err = applyWithQuota(store, quotas, alice, &pb.RecordChange{Before: renumbered, After: renamed})
+			if !errors.As(err, &quotaErr) || quotaErr.What != tc.what {
+				t.Errorf("%s: renaming to %s: err = %v, want too many %s", backend, tc.name, err, tc.what)
+			}
+		}
+		if got, _ := store.Get(renumbered.GetId()); got.GetName() != "host" {
+			t.Errorf("%s: record renamed to %s despite the quota", backend, got.GetName())
+		}
+
+		// users over a quota which was lowered can still change what they have
+		quotas.Config.DefaultQuota.MaxRecords = 1
+		longer := proto.Clone(renumbered).(*pb.DNSRecord)
+		longer.Ttl = 3600
+		err = applyWithQuota(store, quotas, alice, &pb.RecordChange{Before: renumbered, After: longer})
+		if err != nil {
+			t.Errorf("%s: changing the TTL while over quota: err = %v", backend, err)
+		}
+	}
+}
+
+func TestQuotaCheckDelegate(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		quotas := &Quotas{Store: store, Config: &pb.QuotaConfig{
+			Users: map[string]*pb.Quota{
+				"alice": {MaxDevices: 3, MaxRecords: 3, MaxWildcards: 1},
+			},
+		}}
+		// bob has no quota, but is changing alice's records for her
+		bob := &Identity{User: "bob"}
+		record := func(name, value string) *pb.DNSRecord {
+			return &pb.DNSRecord{Name: name, Zone: "valid.zone.", User: "alice",
+				Type: uint32(dns.TypeA), Value: value, Ttl: 60}
+		}
+		host := record("host", "192.0.2.1")
+		for _, r := range []*pb.DNSRecord{host, record("*", "192.0.2.2")} {
+			if err := store.Create(r); err != nil {
+				t.Fatalf("%s: error creating record: %v", backend, err)
+			}
+		}
+		var quotaErr *QuotaError
+
+		err := applyWithQuota(store, quotas, bob, &pb.RecordChange{After: record("host", "192.0.2.3")})
+		if err != nil {
+			t.Fatalf("%s: adding a record within alice's quota: err = %v", backend, err)
+		}
+
+		err = applyWithQuota(store, quotas, bob, &pb.RecordChange{After: record("other", "192.0.2.4")})
+		if !errors.As(err, &quotaErr) || quotaErr.What != "records" {
+			t.Errorf("%s: adding a fourth record for alice: err = %v, want too many records", backend, err)
+		}
+
+		wildcard := proto.Clone(host).(*pb.DNSRecord)
+		wildcard.Name = "*.sub"
+		err = applyWithQuota(store, quotas, bob, &pb.RecordChange{Before: host, After: wildcard})
+		if !errors.As(err, &quotaErr) || quotaErr.What != "wildcards" {
+			t.Errorf("%s: making alice a second wildcard: err = %v, want too many wildcards", backend, err)
+		}
+	}
+}
+
+func TestDevices(t *testing.T) {
+	records := []*pb.DNSRecord{
+		{Name: "host", Zone: "valid.zone.", Type: uint32(dns.TypeA)},
+		{Name: "other", Zone: "valid.zone.", Type: uint32(dns.TypeA)},
+		// the same device, however it is written
+		{Name: "HOST", Zone: "Valid.Zone.", Type: uint32(dns.TypeAAAA)},
+	}
+	devices := Devices(records)
+	if len(devices) != 2 {
+		t.Fatalf("got %d devices, want 2: %v", len(devices), devices)
+	}
+	if devices[0].GetName() != "host" || len(devices[0].GetRecords()) != 2 {
+		t.Errorf("first device = %v, want host with 2 records", devices[0])
+	}
+	if devices[1].GetName() != "other" || len(devices[1].GetRecords()) != 1 {
+		t.Errorf("second device = %v, want other with 1 record", devices[1])
+	}
+}
+
+func TestParseQuotas(t *testing.T) {
+	quotas, err := ParseQuotas("alice:1/2/3;cn=rtp,ou=groups,dc=example,dc=org:0/10/0")
+	if err != nil {
+		t.Fatalf("error parsing quotas: %v", err)
+	}
+	if q := quotas["alice"]; q.GetMaxDevices() != 1 || q.GetMaxRecords() != 2 || q.GetMaxWildcards() != 3 {
+		t.Errorf("quota of alice = %v", q)
+	}
+	if q := quotas["cn=rtp,ou=groups,dc=example,dc=org"]; q.GetMaxRecords() != 10 {
+		t.Errorf("quota of group = %v", q)
+	}
+	for _, invalid := range []string{"alice", "alice:1/2", "alice:1/2/x"} {
+		if _, err := ParseQuotas(invalid); err == nil {
+			t.Errorf("%q parsed without error", invalid)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package util
+
+import (
+	"expvar"
+	"net"
+	"sync"
+	"time"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/gidoBOSSftw5731/log"
+	"github.com/miekg/dns"
+)
+
+// rrlClass is the kind of response being rate limited. Each client prefix
+// gets a separate bucket for each class.
+type rrlClass int
+
+const (
+	rrlResponses rrlClass = iota
+	rrlNoData
+	rrlNXDomain
+	rrlErrors
+)
+
+var rrlClassNames = map[rrlClass]string{
+	rrlResponses: "responses",
+	rrlNoData:    "nodata",
+	rrlNXDomain:  "nxdomains",
+	rrlErrors:    "errors",
+}
+
+// rrlAction is what should be done with a response after rate limiting.
+type rrlAction int
+
+const (
+	rrlAllow rrlAction = iota
+	rrlDrop
+	rrlSlip
+)
+
+var (
+	// RRLStats counts what rate limiting has done, and is published with
+	// expvar so it shows up on /debug/vars of the debug listener.
+	RRLStats = expvar.NewMap("rrl")
+)
+
+type rrlKey struct {
+	prefix string
+	class  rrlClass
+}
+
+type rrlBucket struct {
+	// tokens is the remaining credit of the bucket, which goes negative
+	// while the client is being limited.
+	tokens  float64
+	last    time.Time
+	limited bool
+	// slips counts limited responses, to work out which ones to slip
+	slips uint32
+}
+
+// RateLimiter implements BIND style response rate limiting (RRL). Every
+// client prefix has a token bucket per response class, and responses beyond
+// the configured rate are dropped or slipped to stop us being used as a
+// reflector.
+type RateLimiter struct {
+	conf *pb.RRLConfig
+
+	mu        sync.Mutex
+	buckets   map[rrlKey]*rrlBucket
+	lastSweep time.Time
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewRateLimiter returns a RateLimiter for conf, or nil if rate limiting is
+// turned off. A nil RateLimiter allows everything.
+func NewRateLimiter(conf *pb.RRLConfig) *RateLimiter {
+	if conf.GetResponsesPerSecond() == 0 {
+		return nil
+	}
+	return &RateLimiter{
+		conf:    conf,
+		buckets: map[rrlKey]*rrlBucket{},
+		now:     time.Now,
+	}
+}
+
+// check decides whether the response m to the client at ip may be sent.
+func (l *RateLimiter) check(ip net.IP, m *dns.Msg) rrlAction {
+	if l == nil || ip == nil || prefixesContain(l.conf.GetExemptPrefixes(), ip) {
+		return rrlAllow
+	}
+	RRLStats.Add("checked", 1)
+
+	class := rrlClassify(m)
+	rate := float64(l.rate(class))
+	key := rrlKey{prefix: l.prefix(ip), class: class}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &rrlBucket{tokens: rate, last: now}
+		l.buckets[key] = b
+	}
+
+	// refill the bucket, but never beyond one second's worth of responses
+	b.tokens += now.Sub(b.last).Seconds() * rate
+	if b.tokens > rate {
+		b.tokens = rate
+	}
+	b.last = now
+
+	b.tokens--
+	// don't let the debt grow beyond the window, so a client that stops
+	// sending is forgiven after at most that long
+	if debt := -rate * float64(l.window()); b.tokens < debt {
+		b.tokens = debt
+	}
+
+	if b.tokens >= 0 {
+		if b.limited {
+			log.Infof("No longer rate limiting %s for %s", rrlClassNames[class], key.prefix)
+			b.limited = false
+		}
+		return rrlAllow
+	}
+
+	if !b.limited {
+		log.Infof("Rate limiting %s for %s", rrlClassNames[class], key.prefix)
+		b.limited = true
+	}
+	RRLStats.Add("limited", 1)
+	RRLStats.Add("limited_"+rrlClassNames[class], 1)
+
+	b.slips++
+	if slip := l.conf.GetSlip(); slip != 0 && b.slips%slip == 0 {
+		RRLStats.Add("slipped", 1)
+		return rrlSlip
+	}
+	RRLStats.Add("dropped", 1)
+	return rrlDrop
+}
+
+// sweep forgets buckets which haven't been used for a whole window, since by
+// then they'd be full again anyway.
+func (l *RateLimiter) sweep(now time.Time) {
+	window := time.Duration(l.window()) * time.Second
+	if now.Sub(l.lastSweep) < window {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > window {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rate returns the number of responses per second allowed for class.
+func (l *RateLimiter) rate(class rrlClass) uint32 {
+	var rate uint32
+	switch class {
+	case rrlNoData:
+		rate = l.conf.GetNodataPerSecond()
+	case rrlNXDomain:
+		rate = l.conf.GetNxdomainsPerSecond()
+	case rrlErrors:
+		rate = l.conf.GetErrorsPerSecond()
+	}
+	if rate == 0 {
+		rate = l.conf.GetResponsesPerSecond()
+	}
+	return rate
+}
+
+func (l *RateLimiter) window() uint32 {
+	if l.conf.GetWindow() == 0 {
+		return 1
+	}
+	return l.conf.GetWindow()
+}
+
+// prefix returns the network ip is in, so that a client can't get around the
+// limits by using many addresses from the same network.
+func (l *RateLimiter) prefix(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(int(l.conf.GetIpv4PrefixLength()), 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(int(l.conf.GetIpv6PrefixLength()), 128)).String()
+}
+
+// rrlClassify works out which kind of response m is.
+func rrlClassify(m *dns.Msg) rrlClass {
+	switch {
+	case m.Rcode == dns.RcodeNameError:
+		return rrlNXDomain
+	case m.Rcode != dns.RcodeSuccess:
+		return rrlErrors
+	case len(m.Answer) == 0:
+		return rrlNoData
+	}
+	return rrlResponses
+}
@@ -0,0 +1,1185 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/miekg/dns"
+	"github.com/uptrace/bun/driver/pgdriver"
+	"google.golang.org/protobuf/proto"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// pgUniqueViolation is the SQLSTATE Postgres returns for duplicate keys.
+const pgUniqueViolation = "23505"
+
+// The storage backends which can be set in ServerConfig.
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+)
+
+// RecordStore is where DNS records are kept. It is used both to answer DNS
+// queries and by the API to change records.
+type RecordStore interface {
+	// Lookup returns the enabled records for name in zone which are visible
+	// from view, sorted by type. An empty view returns the records of every
+	// view.
+	Lookup(zone, name, view string) ([]*pb.DNSRecord, error)
+	// Create adds a new record, and sets its ID.
+	Create(record *pb.DNSRecord) error
+	// Get returns the record with the given ID.
+	Get(id uint64) (*pb.DNSRecord, error)
+	// List returns the records in zone, or only those for name if it is set.
+	List(zone, name string) ([]*pb.DNSRecord, error)
+	// Update replaces the record with the same ID as record, as long as it
+	// is still at revision, otherwise it fails with ErrConflict.
+	Update(record *pb.DNSRecord, revision uint64) error
+	// Delete removes the record with the given ID.
+	Delete(id uint64) error
+	// Apply makes changes to records all at once, or none of them if any
+	// fails. New records have their IDs set. Records are only changed or
+	// deleted if they are still at the revision of the change's before,
+	// otherwise it fails with ErrConflict. The serial of each zone changed
+	// goes up by one. Changes to the records of an owner are made one at a
+	// time, so within a Transaction what is read about them after Apply stays
+	// true until it is committed.
+	Apply(changes []*pb.RecordChange) error
+	// Transaction calls fn with a RecordStore which makes every change fn
+	// makes through it at once, or none of them if fn returns an error,
+	// which Transaction returns.
+	Transaction(fn func(tx RecordStore) error) error
+
+	// Delegate lets a user change every record in a zone. Delegating a zone
+	// to someone who already has it does nothing.
+	Delegate(zone, user string) error
+	// Undelegate takes a zone away from a user again.
+	Undelegate(zone, user string) error
+	// Delegations returns who zone has been delegated to, or every delegation
+	// if zone is empty.
+	Delegations(zone string) ([]*pb.ZoneDelegation, error)
+	// IsDelegated reports whether zone has been delegated to user.
+	IsDelegated(zone, user string) (bool, error)
+
+	// CreateToken stores a new API token, and sets its ID.
+	CreateToken(token *pb.APIToken) error
+	// TokenByHash returns the API token with the given hash.
+	TokenByHash(hash string) (*pb.APIToken, error)
+	// Tokens returns the API tokens of user, or everyone's if user is empty.
+	Tokens(user string) ([]*pb.APIToken, error)
+	// RevokeToken marks the API token with the given ID as revoked at the
+	// unix time at.
+	RevokeToken(id uint64, at int64) error
+
+	// Owners returns every user who owns a record.
+	Owners() ([]string, error)
+	// OwnedRecords returns every record owned by user, in any zone.
+	OwnedRecords(user string) ([]*pb.DNSRecord, error)
+	// SetOwnerDisabled disables or enables every record owned by user, and
+	// returns how many records changed.
+	SetOwnerDisabled(user string, disabled bool) (int64, error)
+
+	// AppendAudit adds an entry to the audit log, and sets its ID.
+	AppendAudit(entry *pb.AuditEntry) error
+	// AuditLog returns the entries of the audit log matching query, oldest
+	// first.
+	AuditLog(query AuditQuery) ([]*pb.AuditEntry, error)
+
+	// ZoneSerial returns the serial of zone, which goes up by one with every
+	// change to its records. Zones which have never changed are at 0.
+	ZoneSerial(zone string) (uint64, error)
+	// ZoneHistory returns the versions of the records in zone made up to at,
+	// oldest first.
+	ZoneHistory(zone string, at ZonePoint) ([]*pb.RecordVersion, error)
+	// ZoneHistorySince returns the versions of the records in zone made after
+	// serial, oldest first.
+	ZoneHistorySince(zone string, serial uint64) ([]*pb.RecordVersion, error)
+	// RollbackZone puts the records in zone back to how they were at at, as a
+	// single change, and returns what it changed and the new serial. If
+	// ifSerial is set the zone must still be at that serial, otherwise it
+	// fails with ErrConflict.
+	RollbackZone(zone string, at ZonePoint, ifSerial uint64) ([]*pb.RecordChange, uint64, error)
+}
+
+// AuditQuery picks entries out of the audit log. Empty fields match
+// everything.
+type AuditQuery struct {
+	// User is who made the change
+	User string
+	Zone string
+	Name string
+	// Since and Until are unix timestamps, and include changes made at them
+	Since int64
+	Until int64
+	// Limit is the most entries to return, after skipping Offset of them
+	Limit  int
+	Offset int
+}
+
+var (
+	// ErrRecordNotFound is returned when there is no record with an ID.
+	ErrRecordNotFound = errors.New("record not found")
+	// ErrDuplicateRecord is returned when a record with the same zone, name,
+	// type and value already exists.
+	ErrDuplicateRecord = errors.New("record already exists")
+	// ErrTokenNotFound is returned when there is no API token with an ID or
+	// hash.
+	ErrTokenNotFound = errors.New("token not found")
+	// ErrConflict is returned when a record or zone has changed since it was
+	// read, so changing it could undo someone else's changes.
+	ErrConflict = errors.New("changed since it was read")
+)
+
+// OpenStore opens the storage backend set in conf. Postgres is answered from
+// a ZoneCache, which is kept up to date in the background.
+func OpenStore(conf *pb.ServerConfig) (RecordStore, error) {
+	switch conf.GetStorageBackend() {
+	case StoragePostgres, "":
+		db, err := ConfDB(conf)
+		if err != nil {
+			return nil, err
+		}
+		cache := NewZoneCache(db)
+		if err := cache.Load(); err != nil {
+			return nil, err
+		}
+		go cache.Listen(conf.GetDBConf())
+		return cache, nil
+
+	case StorageSQLite:
+		db, err := ConfDB(conf)
+		if err != nil {
+			return nil, err
+		}
+		return &gormStore{db: db}, nil
+
+	case StorageMemory:
+		return NewMemoryStore(), nil
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", conf.GetStorageBackend())
+}
+
+// gormStore keeps records in an SQL database through gorm.
+type gormStore struct {
+	db *gorm.DB
+}
+
+func (s *gormStore) Lookup(zone, name, view string) ([]*pb.DNSRecord, error) {
+	query := s.db.Where("LOWER(zone) = ? AND LOWER(name) = LOWER(?)", zoneKey(zone), name).
+		Where("disabled = ?", false)
+	if view != "" {
+		query = query.Where(map[string]interface{}{"view": []string{"", view}})
+	}
+
+	var records []*pb.DNSRecord
+	err := query.Order("type").Find(&records).Error
+	return records, err
+}
+
+func (s *gormStore) Create(record *pb.DNSRecord) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return (&gormStore{db: tx}).apply([]*pb.RecordChange{{After: record}})
+	})
+}
+
+func (s *gormStore) Get(id uint64) (*pb.DNSRecord, error) {
+	record := &pb.DNSRecord{}
+	err := s.db.Where("id = ?", id).Take(record).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return record, nil
+}
+
+func (s *gormStore) List(zone, name string) ([]*pb.DNSRecord, error) {
+	query := s.db.Where("LOWER(zone) = ?", zoneKey(zone))
+	if name != "" {
+		query = query.Where("LOWER(name) = LOWER(?)", name)
+	}
+
+	var records []*pb.DNSRecord
+	err := query.Order("name, type, id").Find(&records).Error
+	return records, err
+}
+
+func (s *gormStore) Update(record *pb.DNSRecord, revision uint64) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		store := &gormStore{db: tx}
+		old, err := store.Get(record.GetId())
+		if err != nil {
+			return err
+		}
+		if old.GetRevision() != revision {
+			return ErrConflict
+		}
+		return store.apply([]*pb.RecordChange{{Before: old, After: record}})
+	})
+}
+
+func (s *gormStore) Delete(id uint64) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		store := &gormStore{db: tx}
+		old, err := store.Get(id)
+		if err != nil {
+			return err
+		}
+		return store.apply([]*pb.RecordChange{{Before: old}})
+	})
+}
+
+func (s *gormStore) Apply(changes []*pb.RecordChange) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return (&gormStore{db: tx}).apply(changes)
+	})
+}
+
+func (s *gormStore) Transaction(fn func(tx RecordStore) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+func (s *gormStore) Delegate(zone, user string) error {
+	return s.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&pb.ZoneDelegation{Zone: zoneKey(zone), User: user}).Error
+}
+
+func (s *gormStore) Undelegate(zone, user string) error {
+	return s.db.Where(&pb.ZoneDelegation{Zone: zoneKey(zone), User: user}).
+		Delete(&pb.ZoneDelegation{}).Error
+}
+
+func (s *gormStore) Delegations(zone string) ([]*pb.ZoneDelegation, error) {
+	query := s.db
+	if zone != "" {
+		query = query.Where(&pb.ZoneDelegation{Zone: zoneKey(zone)})
+	}
+
+	var delegations []*pb.ZoneDelegation
+	err := query.Order("zone, id").Find(&delegations).Error
+	return delegations, err
+}
+
+func (s *gormStore) IsDelegated(zone, user string) (bool, error) {
+	var count int64
+	err := s.db.Model(&pb.ZoneDelegation{}).
+		Where(&pb.ZoneDelegation{Zone: zoneKey(zone), User: user}).Count(&count).Error
+	return count > 0, err
+}
+
+func (s *gormStore) CreateToken(token *pb.APIToken) error {
+	return s.db.Create(token).Error
+}
+
+func (s *gormStore) TokenByHash(hash string) (*pb.APIToken, error) {
+	token := &pb.APIToken{}
+	err := s.db.Where(&pb.APIToken{Hash: hash}).Take(token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *gormStore) Tokens(user string) ([]*pb.APIToken, error) {
+	query := s.db
+	if user != "" {
+		query = query.Where(&pb.APIToken{User: user})
+	}
+
+	var tokens []*pb.APIToken
+	err := query.Order("id").Find(&tokens).Error
+	return tokens, err
+}
+
+func (s *gormStore) RevokeToken(id uint64, at int64) error {
+	result := s.db.Model(&pb.APIToken{}).Where("id = ?", id).Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTokenNotFound
+	}
+	return nil
+}
+
+func (s *gormStore) Owners() ([]string, error) {
+	var owners []string
+	// user has to be quoted, which Order won't do for plain strings
+	err := s.db.Model(&pb.DNSRecord{}).Distinct().
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "user"}}).
+		Pluck("user", &owners).Error
+	return owners, err
+}
+
+func (s *gormStore) OwnedRecords(user string) ([]*pb.DNSRecord, error) {
+	var records []*pb.DNSRecord
+	err := s.db.Where(map[string]interface{}{"user": user}).Order("id").Find(&records).Error
+	return records, err
+}
+
+func (s *gormStore) SetOwnerDisabled(user string, disabled bool) (int64, error) {
+	var changes []*pb.RecordChange
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var records []*pb.DNSRecord
+		err := tx.Where(map[string]interface{}{"user": user}).
+			Where("disabled <> ?", disabled).Order("id").Find(&records).Error
+		if err != nil {
+			return err
+		}
+		changes = withDisabled(records, disabled)
+		return (&gormStore{db: tx}).apply(changes)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(changes)), nil
+}
+
+func (s *gormStore) AppendAudit(entry *pb.AuditEntry) error {
+	return s.db.Create(entry).Error
+}
+
+func (s *gormStore) AuditLog(query AuditQuery) ([]*pb.AuditEntry, error) {
+	db := s.db.Where(&pb.AuditEntry{
+		Actor: query.User,
+		Zone:  auditZone(query.Zone),
+		Name:  strings.ToLower(query.Name),
+	})
+	if query.Since != 0 {
+		db = db.Where("time >= ?", query.Since)
+	}
+	if query.Until != 0 {
+		db = db.Where("time <= ?", query.Until)
+	}
+	if query.Limit != 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	var entries []*pb.AuditEntry
+	err := db.Offset(query.Offset).Order("id").Find(&entries).Error
+	return entries, err
+}
+
+func (s *gormStore) ZoneSerial(zone string) (uint64, error) {
+	serial := &pb.ZoneSerial{}
+	err := s.db.Where(&pb.ZoneSerial{Zone: zoneKey(zone)}).Take(serial).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return serial.GetSerial(), err
+}
+
+func (s *gormStore) ZoneHistory(zone string, at ZonePoint) ([]*pb.RecordVersion, error) {
+	query := s.db.Where(&pb.RecordVersion{Zone: zoneKey(zone)})
+	if at.Serial != 0 {
+		query = query.Where("serial <= ?", at.Serial)
+	}
+	if at.Time != 0 {
+		query = query.Where("time <= ?", at.Time)
+	}
+
+	var versions []*pb.RecordVersion
+	err := query.Order("id").Find(&versions).Error
+	return versions, err
+}
+
+func (s *gormStore) ZoneHistorySince(zone string, serial uint64) ([]*pb.RecordVersion, error) {
+	var versions []*pb.RecordVersion
+	err := s.db.Where(&pb.RecordVersion{Zone: zoneKey(zone)}).Where("serial > ?", serial).
+		Order("id").Find(&versions).Error
+	return versions, err
+}
+
+func (s *gormStore) RollbackZone(zone string, at ZonePoint, ifSerial uint64) ([]*pb.RecordChange, uint64, error) {
+	var changes []*pb.RecordChange
+	var serial uint64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		store := &gormStore{db: tx}
+		if ifSerial != 0 {
+			current, err := store.ZoneSerial(zone)
+			if err != nil {
+				return err
+			}
+			if current != ifSerial {
+				return ErrConflict
+			}
+		}
+		history, err := store.ZoneHistory(zone, ZonePoint{})
+		if err != nil {
+			return err
+		}
+		var current []*pb.DNSRecord
+		err = tx.Where("LOWER(zone) IN ?", zoneSpellings(zone)).Order("id").Find(&current).Error
+		if err != nil {
+			return err
+		}
+
+		var disabledOwners []string
+		err = tx.Model(&pb.DNSRecord{}).Where("disabled = ?", true).Distinct().
+			Pluck("user", &disabledOwners).Error
+		if err != nil {
+			return err
+		}
+
+		changes = rollbackChanges(current, history, at, ownerSet(disabledOwners))
+		if err := store.apply(changes); err != nil {
+			return err
+		}
+		serial, err = store.ZoneSerial(zone)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return changes, serial, nil
+}
+
+// apply makes changes to records, and adds them to the history of their
+// zones. Zones are written normalized. s.db should be a transaction, so it all happens or none of it does.
+func (s *gormStore) apply(changes []*pb.RecordChange) (err error) {
+	if err := s.lockOwners(changes); err != nil {
+		return err
+	}
+	restore := saveNumbering(changes)
+	defer func() {
+		if err != nil {
+			restore()
+		}
+	}()
+	for _, change := range changes {
+		before, after := change.GetBefore(), change.GetAfter()
+		setRevision(before, after)
+		normalizeZone(after)
+
+		var result *gorm.DB
+		switch {
+		case before == nil:
+			result = s.db.Create(after)
+		case after == nil:
+			result = s.db.Where("id = ? AND revision = ?", before.GetId(), before.GetRevision()).
+				Delete(&pb.DNSRecord{})
+		default:
+			result = s.db.Model(&pb.DNSRecord{}).
+				Where("id = ? AND revision = ?", after.GetId(), before.GetRevision()).
+				Select("*").Updates(after)
+		}
+		if result.Error != nil {
+			return translateError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			// either it's gone, or it's at another revision
+			if _, err := s.Get(before.GetId()); err != nil {
+				return err
+			}
+			return ErrConflict
+		}
+	}
+
+	versions, err := newVersions(changes, func(zone string) (uint64, error) {
+		// row locking on the upsert keeps concurrent changes from sharing
+		// a serial
+		err := s.db.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "zone"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"serial": gorm.Expr("zone_serials.serial + 1"),
+			}),
+		}).Create(&pb.ZoneSerial{Zone: zone, Serial: 1}).Error
+		if err != nil {
+			return 0, err
+		}
+		return s.ZoneSerial(zone)
+	})
+	if err != nil || len(versions) == 0 {
+		return err
+	}
+	return s.db.Create(versions).Error
+}
+
+// lockOwners waits for any other transaction changing records of the owners
+// of changes to finish, and keeps them waiting until this one does. Only
+// Postgres needs to, as SQLite lets one transaction write at a time anyway.
+func (s *gormStore) lockOwners(changes []*pb.RecordChange) error {
+	if s.db.Dialector.Name() != "postgres" {
+		return nil
+	}
+	seen := map[string]*interface{}{}
+	var owners []string
+	for _, change := range changes {
+		for _, record := range []*pb.DNSRecord{change.GetBefore(), change.GetAfter()} {
+			if _, ok := seen[record.GetUser()]; record != nil && !ok {
+				seen[record.GetUser()] = f
+				owners = append(owners, record.GetUser())
+			}
+		}
+	}
+	// always in the same order, so transactions can't wait on each other
+	sort.Strings(owners)
+	for _, owner := range owners {
+		err := s.db.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "drs owner "+owner).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// translateError turns database errors into the errors RecordStore promises.
+func translateError(err error) error {
+	var pgErr pgdriver.Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrRecordNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey),
+		errors.As(err, &pgErr) && pgErr.Field('C') == pgUniqueViolation:
+		return ErrDuplicateRecord
+	}
+	return err
+}
+
+// memoryStore keeps records in memory only, and so loses them on exit. It is
+// meant for tests and trying things out.
+type memoryStore struct {
+	recordIndex
+	// byID holds the same records as the index, by ID
+	byID   map[uint64]*pb.DNSRecord
+	lastID uint64
+	// delegations maps zone keys to the users they are delegated to
+	delegations map[string][]string
+	tokens      []*pb.APIToken
+	audit       []*pb.AuditEntry
+	versions    []*pb.RecordVersion
+	// serials maps zone keys to their serials
+	serials map[string]uint64
+}
+
+// NewMemoryStore returns an empty RecordStore which lives in memory.
+func NewMemoryStore() RecordStore {
+	return &memoryStore{
+		recordIndex: newRecordIndex(),
+		byID:        map[uint64]*pb.DNSRecord{},
+		delegations: map[string][]string{},
+		serials:     map[string]uint64{},
+	}
+}
+
+func (s *memoryStore) Lookup(zone, name, view string) ([]*pb.DNSRecord, error) {
+	return s.lookup(zone, name, view), nil
+}
+
+func (s *memoryStore) Create(record *pb.DNSRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.apply([]*pb.RecordChange{{After: record}})
+}
+
+func (s *memoryStore) Get(id uint64) (*pb.DNSRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.byID[id]
+	if !ok {
+		return nil, ErrRecordNotFound
+	}
+	return record, nil
+}
+
+func (s *memoryStore) List(zone, name string) ([]*pb.DNSRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var records []*pb.DNSRecord
+	for n, named := range s.zones[zoneKey(zone)] {
+		if name == "" || n == strings.ToLower(name) {
+			records = append(records, named...)
+		}
+	}
+	sortRecords(records)
+	return records, nil
+}
+
+func (s *memoryStore) Update(record *pb.DNSRecord, revision uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.byID[record.GetId()]
+	if !ok {
+		return ErrRecordNotFound
+	}
+	if old.GetRevision() != revision {
+		return ErrConflict
+	}
+	return s.apply([]*pb.RecordChange{{Before: old, After: record}})
+}
+
+func (s *memoryStore) Delete(id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.byID[id]
+	if !ok {
+		return ErrRecordNotFound
+	}
+	return s.apply([]*pb.RecordChange{{Before: record}})
+}
+
+func (s *memoryStore) Apply(changes []*pb.RecordChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.apply(changes)
+}
+
+// Transaction lets fn change a copy of the store, which replaces it if fn
+// succeeds. Nothing else can use the store until then.
+func (s *memoryStore) Transaction(fn func(tx RecordStore) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := s.copy()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	s.zones, s.byID, s.lastID = tx.zones, tx.byID, tx.lastID
+	s.delegations, s.tokens, s.audit = tx.delegations, tx.tokens, tx.audit
+	s.versions, s.serials = tx.versions, tx.serials
+	return nil
+}
+
+// copy returns a memoryStore holding what s does, which can be changed
+// without changing s. Records and tokens are shared, so they must be
+// replaced rather than changed. s.mu must be held.
+func (s *memoryStore) copy() *memoryStore {
+	c := &memoryStore{
+		recordIndex: newRecordIndex(),
+		byID:        make(map[uint64]*pb.DNSRecord, len(s.byID)),
+		lastID:      s.lastID,
+		delegations: make(map[string][]string, len(s.delegations)),
+		// appending to these mustn't write into what s has
+		tokens:   s.tokens[:len(s.tokens):len(s.tokens)],
+		audit:    s.audit[:len(s.audit):len(s.audit)],
+		versions: s.versions[:len(s.versions):len(s.versions)],
+		serials:  make(map[string]uint64, len(s.serials)),
+	}
+	for zone, names := range s.zones {
+		c.zones[zone] = make(map[string][]*pb.DNSRecord, len(names))
+		for name, records := range names {
+			c.zones[zone][name] = append([]*pb.DNSRecord(nil), records...)
+		}
+	}
+	for id, record := range s.byID {
+		c.byID[id] = record
+	}
+	for zone, users := range s.delegations {
+		c.delegations[zone] = append([]string(nil), users...)
+	}
+	for zone, serial := range s.serials {
+		c.serials[zone] = serial
+	}
+	return c
+}
+
+func (s *memoryStore) Delegate(zone, user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	zone = zoneKey(zone)
+	for _, u := range s.delegations[zone] {
+		if u == user {
+			return nil
+		}
+	}
+	s.delegations[zone] = append(s.delegations[zone], user)
+	return nil
+}
+
+func (s *memoryStore) Undelegate(zone, user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	zone = zoneKey(zone)
+	for i, u := range s.delegations[zone] {
+		if u == user {
+			s.delegations[zone] = append(s.delegations[zone][:i:i], s.delegations[zone][i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) Delegations(zone string) ([]*pb.ZoneDelegation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var delegations []*pb.ZoneDelegation
+	for z, users := range s.delegations {
+		if zone != "" && z != zoneKey(zone) {
+			continue
+		}
+		for _, user := range users {
+			delegations = append(delegations, &pb.ZoneDelegation{Zone: z, User: user})
+		}
+	}
+	sort.SliceStable(delegations, func(i, j int) bool {
+		return delegations[i].GetZone() < delegations[j].GetZone()
+	})
+	return delegations, nil
+}
+
+func (s *memoryStore) IsDelegated(zone, user string) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.delegations[zoneKey(zone)] {
+		if u == user {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *memoryStore) CreateToken(token *pb.APIToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token.Id = uint64(len(s.tokens) + 1)
+	s.tokens = append(s.tokens, token)
+	return nil
+}
+
+func (s *memoryStore) TokenByHash(hash string) (*pb.APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, token := range s.tokens {
+		if token.GetHash() == hash {
+			return token, nil
+		}
+	}
+	return nil, ErrTokenNotFound
+}
+
+func (s *memoryStore) Tokens(user string) ([]*pb.APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tokens []*pb.APIToken
+	for _, token := range s.tokens {
+		if user == "" || token.GetUser() == user {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+func (s *memoryStore) RevokeToken(id uint64, at int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// tokens are never deleted, so IDs are indexes
+	if id == 0 || id > uint64(len(s.tokens)) {
+		return ErrTokenNotFound
+	}
+	// copies made for transactions share tokens
+	revoked := proto.Clone(s.tokens[id-1]).(*pb.APIToken)
+	revoked.RevokedAt = at
+	s.tokens[id-1] = revoked
+	return nil
+}
+
+func (s *memoryStore) Owners() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := map[string]*interface{}{}
+	var owners []string
+	for _, record := range s.byID {
+		if _, ok := seen[record.GetUser()]; !ok {
+			seen[record.GetUser()] = f
+			owners = append(owners, record.GetUser())
+		}
+	}
+	sort.Strings(owners)
+	return owners, nil
+}
+
+func (s *memoryStore) OwnedRecords(user string) ([]*pb.DNSRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var records []*pb.DNSRecord
+	for _, record := range s.byID {
+		if record.GetUser() == user {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].GetId() < records[j].GetId()
+	})
+	return records, nil
+}
+
+func (s *memoryStore) SetOwnerDisabled(user string, disabled bool) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []*pb.DNSRecord
+	for _, record := range s.byID {
+		if record.GetUser() == user && record.GetDisabled() != disabled {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].GetId() < records[j].GetId()
+	})
+	changes := withDisabled(records, disabled)
+	if err := s.apply(changes); err != nil {
+		return 0, err
+	}
+	return int64(len(changes)), nil
+}
+
+func (s *memoryStore) AppendAudit(entry *pb.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.Id = uint64(len(s.audit) + 1)
+	s.audit = append(s.audit, entry)
+	return nil
+}
+
+func (s *memoryStore) AuditLog(query AuditQuery) ([]*pb.AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entries []*pb.AuditEntry
+	skipped := 0
+	for _, entry := range s.audit {
+		switch {
+		case query.User != "" && entry.GetActor() != query.User,
+			query.Zone != "" && entry.GetZone() != auditZone(query.Zone),
+			query.Name != "" && entry.GetName() != strings.ToLower(query.Name),
+			query.Since != 0 && entry.GetTime() < query.Since,
+			query.Until != 0 && entry.GetTime() > query.Until:
+			continue
+		}
+		if skipped < query.Offset {
+			skipped++
+			continue
+		}
+		if query.Limit != 0 && len(entries) == query.Limit {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (s *memoryStore) ZoneSerial(zone string) (uint64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.serials[zoneKey(zone)], nil
+}
+
+func (s *memoryStore) ZoneHistory(zone string, at ZonePoint) ([]*pb.RecordVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.history(zone, at), nil
+}
+
+func (s *memoryStore) ZoneHistorySince(zone string, serial uint64) ([]*pb.RecordVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var versions []*pb.RecordVersion
+	for _, version := range s.versions {
+		if version.GetZone() == zoneKey(zone) && version.GetSerial() > serial {
+			versions = append(versions, version)
+		}
+	}
+	return versions, nil
+}
+
+func (s *memoryStore) RollbackZone(zone string, at ZonePoint, ifSerial uint64) ([]*pb.RecordChange, uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ifSerial != 0 && s.serials[zoneKey(zone)] != ifSerial {
+		return nil, 0, ErrConflict
+	}
+
+	var current []*pb.DNSRecord
+	for _, named := range s.zones[zoneKey(zone)] {
+		current = append(current, named...)
+	}
+	sortRecords(current)
+
+	var disabledOwners []string
+	for _, record := range s.byID {
+		if record.GetDisabled() {
+			disabledOwners = append(disabledOwners, record.GetUser())
+		}
+	}
+
+	changes := rollbackChanges(current, s.history(zone, ZonePoint{}), at, ownerSet(disabledOwners))
+	if err := s.apply(changes); err != nil {
+		return nil, 0, err
+	}
+	return changes, s.serials[zoneKey(zone)], nil
+}
+
+// history returns the versions of the records in zone made up to at. s.mu
+// must be held.
+func (s *memoryStore) history(zone string, at ZonePoint) []*pb.RecordVersion {
+	var versions []*pb.RecordVersion
+	for _, version := range s.versions {
+		if version.GetZone() == zoneKey(zone) && at.includes(version) {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// apply makes changes to records, and adds them to the history of their
+// zones. If any of them fails, those already made are undone. s.mu must be
+// held.
+func (s *memoryStore) apply(changes []*pb.RecordChange) error {
+	// what the IDs changed held before, to put back if a change fails
+	saved := map[uint64]*pb.DNSRecord{}
+	restore, lastID := saveNumbering(changes), s.lastID
+	for _, change := range changes {
+		if err := s.change(change, saved); err != nil {
+			restore()
+			s.lastID = lastID
+			for id, record := range saved {
+				if current, ok := s.byID[id]; ok {
+					delete(s.byID, id)
+					s.remove(current)
+				}
+				if record != nil {
+					s.byID[id] = record
+					s.add(record)
+				}
+			}
+			return err
+		}
+	}
+
+	versions, _ := newVersions(changes, func(zone string) (uint64, error) {
+		s.serials[zone]++
+		return s.serials[zone], nil
+	})
+	for _, version := range versions {
+		version.Id = uint64(len(s.versions) + 1)
+		s.versions = append(s.versions, version)
+	}
+	return nil
+}
+
+// change makes a single change to the records, first saving what was at the
+// ID it changes in saved if it isn't already there. New records keep their
+// ID if they have one, so deleted records can be brought back. s.mu must be
+// held.
+func (s *memoryStore) change(change *pb.RecordChange, saved map[uint64]*pb.DNSRecord) error {
+	before, after := change.GetBefore(), change.GetAfter()
+	if before != nil {
+		old, ok := s.byID[before.GetId()]
+		if !ok {
+			return ErrRecordNotFound
+		}
+		if old.GetRevision() != before.GetRevision() {
+			return ErrConflict
+		}
+	} else if _, ok := s.byID[after.GetId()]; ok {
+		return ErrDuplicateRecord
+	}
+	setRevision(before, after)
+	normalizeZone(after)
+
+	id := before.GetId()
+	if before == nil {
+		if s.duplicate(after) {
+			return ErrDuplicateRecord
+		}
+		if after.GetId() == 0 {
+			s.lastID++
+			after.Id = s.lastID
+		}
+		id = after.GetId()
+	}
+	if _, ok := saved[id]; !ok {
+		saved[id] = s.byID[id]
+	}
+
+	if old, ok := s.byID[id]; ok {
+		delete(s.byID, id)
+		s.remove(old)
+	}
+	if after == nil {
+		return nil
+	}
+	if s.duplicate(after) {
+		return ErrDuplicateRecord
+	}
+	s.byID[id] = after
+	s.add(after)
+	return nil
+}
+
+// duplicate reports whether a record with the same zone, name, type and
+// value as record is already in the store. s.mu must be held.
+func (s *memoryStore) duplicate(record *pb.DNSRecord) bool {
+	for _, r := range s.zones[zoneKey(record.GetZone())][strings.ToLower(record.GetName())] {
+		if r.GetType() == record.GetType() && r.GetValue() == record.GetValue() {
+			return true
+		}
+	}
+	return false
+}
+
+// recordIndex indexes records by zone and name in memory.
+type recordIndex struct {
+	mu sync.RWMutex
+	// zones maps lower cased zones to lower cased names to their records,
+	// which are sorted by type
+	zones map[string]map[string][]*pb.DNSRecord
+}
+
+func newRecordIndex() recordIndex {
+	return recordIndex{zones: map[string]map[string][]*pb.DNSRecord{}}
+}
+
+// lookup returns the enabled records for name in zone which are visible from
+// view, sorted by type. An empty view returns the records of every view.
+func (i *recordIndex) lookup(zone, name, view string) []*pb.DNSRecord {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var ret []*pb.DNSRecord
+	for _, record := range i.zones[zoneKey(zone)][strings.ToLower(name)] {
+		if record.GetDisabled() {
+			continue
+		}
+		if view == "" || record.GetView() == "" || record.GetView() == view {
+			ret = append(ret, record)
+		}
+	}
+	return ret
+}
+
+// add puts record in the index. i.mu must be held.
+func (i *recordIndex) add(record *pb.DNSRecord) {
+	zone := zoneKey(record.GetZone())
+	if i.zones[zone] == nil {
+		i.zones[zone] = map[string][]*pb.DNSRecord{}
+	}
+	indexRecord(i.zones[zone], record)
+}
+
+// remove takes record out of the index. i.mu must be held.
+func (i *recordIndex) remove(record *pb.DNSRecord) {
+	names := i.zones[zoneKey(record.GetZone())]
+	name := strings.ToLower(record.GetName())
+	for j, r := range names[name] {
+		if r == record {
+			names[name] = append(names[name][:j:j], names[name][j+1:]...)
+			break
+		}
+	}
+	if len(names[name]) == 0 {
+		delete(names, name)
+	}
+}
+
+// indexRecord adds record to names, keeping each name's records sorted by
+// type.
+func indexRecord(names map[string][]*pb.DNSRecord, record *pb.DNSRecord) {
+	name := strings.ToLower(record.GetName())
+	names[name] = append(names[name], record)
+	sort.SliceStable(names[name], func(i, j int) bool {
+		return names[name][i].GetType() < names[name][j].GetType()
+	})
+}
+
+// setRevision sets the revision of the record a change leaves behind, which
+// is one more than it was before. Deleted records which are brought back
+// carry on from the revision they had.
+func setRevision(before, after *pb.DNSRecord) {
+	switch {
+	case after == nil:
+	case before != nil:
+		after.Revision = before.GetRevision() + 1
+	default:
+		after.Revision++
+	}
+}
+
+// saveNumbering returns a function which puts the IDs and revisions of the
+// records changes leave behind back as they are now, for when the changes
+// fail after they have been numbered.
+func saveNumbering(changes []*pb.RecordChange) func() {
+	type numbering struct{ id, revision uint64 }
+	saved := make([]numbering, len(changes))
+	for i, change := range changes {
+		saved[i] = numbering{change.GetAfter().GetId(), change.GetAfter().GetRevision()}
+	}
+	return func() {
+		for i, change := range changes {
+			if after := change.GetAfter(); after != nil {
+				after.Id, after.Revision = saved[i].id, saved[i].revision
+			}
+		}
+	}
+}
+
+// normalizeZone writes the zone of record lower cased and with the trailing
+// dot, so records in the same zone are always written the same way and the
+// unique index can tell when they are duplicates.
+func normalizeZone(record *pb.DNSRecord) {
+	if record != nil {
+		record.Zone = zoneKey(record.GetZone())
+	}
+}
+
+// ownerSet returns owners as a set.
+func ownerSet(owners []string) map[string]*interface{} {
+	set := map[string]*interface{}{}
+	for _, owner := range owners {
+		set[owner] = f
+	}
+	return set
+}
+
+// withDisabled returns the changes disabling or enabling records.
+func withDisabled(records []*pb.DNSRecord, disabled bool) []*pb.RecordChange {
+	var changes []*pb.RecordChange
+	for _, record := range records {
+		after := proto.Clone(record).(*pb.DNSRecord)
+		after.Disabled = disabled
+		changes = append(changes, &pb.RecordChange{Before: record, After: after})
+	}
+	return changes
+}
+
+// zoneKey normalizes a zone name for use as a map key.
+func zoneKey(zone string) string {
+	return strings.ToLower(dns.Fqdn(zone))
+}
+
+// zoneSpellings returns the ways zone may be written in the database, lower
+// cased, as records may have it with or without the trailing dot.
+func zoneSpellings(zone string) []string {
+	key := zoneKey(zone)
+	return []string{key, strings.TrimSuffix(key, ".")}
+}
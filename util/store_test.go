@@ -0,0 +1,387 @@
+package util
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/miekg/dns"
+	"google.golang.org/protobuf/proto"
+)
+
+// openTestStores returns a store of every backend that doesn't need a
+// database server.
+func openTestStores(t *testing.T) map[string]RecordStore {
+	stores := map[string]RecordStore{}
+	for _, backend := range []string{StorageMemory, StorageSQLite} {
+		store, err := OpenStore(&pb.ServerConfig{
+			StorageBackend: backend,
+			DBConf: &pb.DatabaseConfig{
+				SqlitePath: filepath.Join(t.TempDir(), "drs.db"),
+			},
+		})
+		if err != nil {
+			t.Fatalf("error opening %s store: %v", backend, err)
+		}
+		stores[backend] = store
+	}
+	return stores
+}
+
+func TestRecordStore(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		for _, record := range []*pb.DNSRecord{
+			{Name: "host", Zone: "valid.zone.", Type: uint32(dns.TypeAAAA), Value: "2001:db8::1", Ttl: 60},
+			{Name: "host", Zone: "valid.zone.", Type: uint32(dns.TypeA), Value: "10.0.0.1", Ttl: 60, View: ViewInternal},
+			{Name: "host", Zone: "valid.zone.", Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 60, View: ViewExternal},
+			{Name: "other", Zone: "valid.zone.", Type: uint32(dns.TypeA), Value: "192.0.2.2", Ttl: 60},
+		} {
+			if err := store.Create(record); err != nil {
+				t.Fatalf("%s: error creating record: %v", backend, err)
+			}
+		}
+
+		for _, tc := range []struct {
+			zone, name, view string
+			values           []string
+		}{
+			{"valid.zone.", "host", ViewInternal, []string{"10.0.0.1", "2001:db8::1"}},
+			{"VALID.zone.", "HOST", ViewExternal, []string{"192.0.2.1", "2001:db8::1"}},
+			{"valid.zone.", "host", "", []string{"10.0.0.1", "192.0.2.1", "2001:db8::1"}},
+			{"valid.zone.", "missing", ViewInternal, nil},
+			{"invalid.zone.", "host", ViewInternal, nil},
+		} {
+			records, err := store.Lookup(tc.zone, tc.name, tc.view)
+			if err != nil {
+				t.Fatalf("%s: error looking up %s: %v", backend, tc.name, err)
+			}
+
+			var values []string
+			for i, record := range records {
+				values = append(values, record.GetValue())
+				if i > 0 && records[i-1].GetType() > record.GetType() {
+					t.Errorf("%s: records not sorted by type", backend)
+				}
+			}
+			// the order within a type isn't defined
+			sort.Strings(values)
+			if strings.Join(values, " ") != strings.Join(tc.values, " ") {
+				t.Errorf("%s: lookup of %s.%s from %q view = %v, want %v", backend,
+					tc.name, tc.zone, tc.view, values, tc.values)
+			}
+		}
+	}
+}
+
+func TestRecordStoreChanges(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		record := &pb.DNSRecord{Name: "host", Zone: "valid.zone.",
+			Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 60}
+		if err := store.Create(record); err != nil {
+			t.Fatalf("%s: error creating record: %v", backend, err)
+		}
+		if record.GetId() == 0 {
+			t.Fatalf("%s: record wasn't given an ID", backend)
+		}
+
+		// the same record again, even in a different case or without the
+		// trailing dot, is a duplicate
+		for _, zone := range []string{"valid.zone.", "Valid.Zone"} {
+			err := store.Create(&pb.DNSRecord{Name: "HOST", Zone: zone,
+				Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 300})
+			if !errors.Is(err, ErrDuplicateRecord) {
+				t.Errorf("%s: creating duplicate in %s: err = %v, want ErrDuplicateRecord",
+					backend, zone, err)
+			}
+		}
+
+		// zones are written normalized
+		other := &pb.DNSRecord{Name: "other", Zone: "Valid.Zone",
+			Type: uint32(dns.TypeA), Value: "192.0.2.3", Ttl: 60}
+		if err := store.Create(other); err != nil {
+			t.Fatalf("%s: error creating record: %v", backend, err)
+		}
+		if got, _ := store.Get(other.GetId()); got.GetZone() != "valid.zone." {
+			t.Errorf("%s: zone of record created in Valid.Zone = %q, want valid.zone.",
+				backend, got.GetZone())
+		}
+		if err := store.Delete(other.GetId()); err != nil {
+			t.Fatalf("%s: error deleting record: %v", backend, err)
+		}
+
+		updated := &pb.DNSRecord{Id: record.GetId(), Name: "host", Zone: "valid.zone.",
+			Type: uint32(dns.TypeA), Value: "192.0.2.2", Ttl: 60}
+		if err := store.Update(updated, record.GetRevision()+1); !errors.Is(err, ErrConflict) {
+			t.Errorf("%s: updating from the wrong revision: err = %v, want %v", backend, err, ErrConflict)
+		}
+		if err := store.Update(updated, record.GetRevision()); err != nil {
+			t.Fatalf("%s: error updating record: %v", backend, err)
+		}
+		got, err := store.Get(record.GetId())
+		if err != nil {
+			t.Fatalf("%s: error getting record: %v", backend, err)
+		}
+		if got.GetValue() != "192.0.2.2" {
+			t.Errorf("%s: value after update = %s", backend, got.GetValue())
+		}
+		records, err := store.List("valid.zone.", "")
+		if err != nil || len(records) != 1 {
+			t.Errorf("%s: list after update = %v, %v", backend, records, err)
+		}
+
+		if err := store.Delete(record.GetId()); err != nil {
+			t.Fatalf("%s: error deleting record: %v", backend, err)
+		}
+		if _, err := store.Get(record.GetId()); !errors.Is(err, ErrRecordNotFound) {
+			t.Errorf("%s: get after delete: err = %v, want ErrRecordNotFound", backend, err)
+		}
+		if err := store.Delete(record.GetId()); !errors.Is(err, ErrRecordNotFound) {
+			t.Errorf("%s: deleting twice: err = %v, want ErrRecordNotFound", backend, err)
+		}
+		if records, _ := store.Lookup("valid.zone.", "host", ""); len(records) != 0 {
+			t.Errorf("%s: lookup after delete = %v", backend, records)
+		}
+	}
+}
+
+func TestDelegations(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		for i := 0; i < 2; i++ {
+			// delegating twice is fine
+			if err := store.Delegate("Valid.Zone", "alice"); err != nil {
+				t.Fatalf("%s: error delegating: %v", backend, err)
+			}
+		}
+		if err := store.Delegate("other.zone.", "bob"); err != nil {
+			t.Fatalf("%s: error delegating: %v", backend, err)
+		}
+
+		if ok, err := store.IsDelegated("valid.zone.", "alice"); err != nil || !ok {
+			t.Errorf("%s: valid.zone. not delegated to alice: %v", backend, err)
+		}
+		if ok, _ := store.IsDelegated("valid.zone.", "bob"); ok {
+			t.Errorf("%s: valid.zone. delegated to bob", backend)
+		}
+		if delegations, _ := store.Delegations("valid.zone."); len(delegations) != 1 {
+			t.Errorf("%s: delegations of valid.zone. = %v", backend, delegations)
+		}
+		if delegations, _ := store.Delegations(""); len(delegations) != 2 {
+			t.Errorf("%s: all delegations = %v", backend, delegations)
+		}
+
+		if err := store.Undelegate("valid.zone.", "alice"); err != nil {
+			t.Fatalf("%s: error undelegating: %v", backend, err)
+		}
+		if ok, _ := store.IsDelegated("valid.zone.", "alice"); ok {
+			t.Errorf("%s: valid.zone. still delegated to alice", backend)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		for _, user := range []string{"alice", "bob"} {
+			if err := store.CreateToken(&pb.APIToken{User: user, Hash: user + "hash"}); err != nil {
+				t.Fatalf("%s: error creating token: %v", backend, err)
+			}
+		}
+
+		token, err := store.TokenByHash("alicehash")
+		if err != nil || token.GetUser() != "alice" || token.GetId() == 0 {
+			t.Fatalf("%s: token by hash = %v, %v", backend, token, err)
+		}
+		if _, err := store.TokenByHash("nohash"); !errors.Is(err, ErrTokenNotFound) {
+			t.Errorf("%s: missing token: err = %v, want ErrTokenNotFound", backend, err)
+		}
+		if tokens, _ := store.Tokens("bob"); len(tokens) != 1 {
+			t.Errorf("%s: tokens of bob = %v", backend, tokens)
+		}
+		if tokens, _ := store.Tokens(""); len(tokens) != 2 {
+			t.Errorf("%s: all tokens = %v", backend, tokens)
+		}
+
+		if err := store.RevokeToken(token.GetId(), 1234); err != nil {
+			t.Fatalf("%s: error revoking token: %v", backend, err)
+		}
+		if token, _ := store.TokenByHash("alicehash"); token.GetRevokedAt() != 1234 {
+			t.Errorf("%s: token not revoked: %v", backend, token)
+		}
+		if err := store.RevokeToken(1000, 1234); !errors.Is(err, ErrTokenNotFound) {
+			t.Errorf("%s: revoking missing token: err = %v, want ErrTokenNotFound", backend, err)
+		}
+	}
+}
+
+func TestDisableOwner(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		for _, user := range []string{"alice", "bob"} {
+			err := store.Create(&pb.DNSRecord{Name: user, Zone: "valid.zone.", User: user,
+				Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 60})
+			if err != nil {
+				t.Fatalf("%s: error creating record: %v", backend, err)
+			}
+		}
+		if owners, err := store.Owners(); err != nil || strings.Join(owners, " ") != "alice bob" {
+			t.Errorf("%s: owners = %v, %v", backend, owners, err)
+		}
+
+		if changed, err := store.SetOwnerDisabled("alice", true); err != nil || changed != 1 {
+			t.Errorf("%s: disabling alice changed %d records, err %v", backend, changed, err)
+		}
+		if changed, _ := store.SetOwnerDisabled("alice", true); changed != 0 {
+			t.Errorf("%s: disabling alice again changed %d records", backend, changed)
+		}
+		if records, _ := store.Lookup("valid.zone.", "alice", ""); len(records) != 0 {
+			t.Errorf("%s: disabled record looked up: %v", backend, records)
+		}
+		if records, _ := store.List("valid.zone.", "alice"); len(records) != 1 || !records[0].GetDisabled() {
+			t.Errorf("%s: disabled record not listed as disabled: %v", backend, records)
+		}
+		if records, _ := store.Lookup("valid.zone.", "bob", ""); len(records) != 1 {
+			t.Errorf("%s: bob's record not looked up: %v", backend, records)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		record := func(zone, name, value string) *pb.DNSRecord {
+			return &pb.DNSRecord{Name: name, Zone: zone, User: "alice",
+				Type: uint32(dns.TypeA), Value: value, Ttl: 60}
+		}
+		old := record("valid.zone.", "host", "192.0.2.1")
+		if err := store.Create(old); err != nil {
+			t.Fatalf("%s: error creating record: %v", backend, err)
+		}
+
+		// renumbering and adding a record elsewhere bumps each zone once
+		added := record("valid.zone.", "host", "192.0.2.2")
+		other := record("other.zone.", "host", "192.0.2.3")
+		err := store.Apply([]*pb.RecordChange{{Before: old}, {After: added}, {After: other}})
+		if err != nil {
+			t.Fatalf("%s: error applying changes: %v", backend, err)
+		}
+		if added.GetId() == 0 || other.GetId() == 0 {
+			t.Errorf("%s: new records weren't given IDs", backend)
+		}
+		for zone, want := range map[string]uint64{"valid.zone.": 2, "other.zone.": 1} {
+			if serial, _ := store.ZoneSerial(zone); serial != want {
+				t.Errorf("%s: serial of %s = %d, want %d", backend, zone, serial, want)
+			}
+		}
+
+		// if anything fails, nothing changes, not even the records passed in
+		failed := record("valid.zone.", "new", "192.0.2.4")
+		err = store.Apply([]*pb.RecordChange{
+			{Before: added},
+			{After: failed},
+			{After: record("other.zone.", "host", "192.0.2.3")},
+		})
+		if !errors.Is(err, ErrDuplicateRecord) {
+			t.Errorf("%s: applying a duplicate: err = %v, want %v", backend, err, ErrDuplicateRecord)
+		}
+		if failed.GetId() != 0 || failed.GetRevision() != 0 {
+			t.Errorf("%s: failed change left its record numbered %d at revision %d",
+				backend, failed.GetId(), failed.GetRevision())
+		}
+		if records, _ := store.List("valid.zone.", ""); len(records) != 1 || records[0].GetValue() != "192.0.2.2" {
+			t.Errorf("%s: records after failed changes = %v", backend, records)
+		}
+		if serial, _ := store.ZoneSerial("valid.zone."); serial != 2 {
+			t.Errorf("%s: failed changes bumped the serial to %d", backend, serial)
+		}
+		// and IDs carry on from where they were
+		next := record("valid.zone.", "next", "192.0.2.5")
+		if err := store.Create(next); err != nil || next.GetId() != other.GetId()+1 {
+			t.Errorf("%s: next record numbered %d, %v, want %d", backend, next.GetId(), err, other.GetId()+1)
+		}
+	}
+}
+
+func TestRevisions(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		record := &pb.DNSRecord{Name: "host", Zone: "valid.zone.", User: "alice",
+			Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 60}
+		if err := store.Create(record); err != nil {
+			t.Fatalf("%s: error creating record: %v", backend, err)
+		}
+		first, err := store.Get(record.GetId())
+		if err != nil || first.GetRevision() != 1 {
+			t.Fatalf("%s: new record = %v, %v, want revision 1", backend, first, err)
+		}
+
+		second := proto.Clone(first).(*pb.DNSRecord)
+		second.Value = "192.0.2.2"
+		if err := store.Apply([]*pb.RecordChange{{Before: first, After: second}}); err != nil {
+			t.Fatalf("%s: error updating record: %v", backend, err)
+		}
+		if got, _ := store.Get(record.GetId()); got.GetRevision() != 2 {
+			t.Errorf("%s: updated record at revision %d, want 2", backend, got.GetRevision())
+		}
+
+		// changes based on an old revision would undo the one above
+		stale := proto.Clone(first).(*pb.DNSRecord)
+		stale.Value = "192.0.2.3"
+		for _, change := range []*pb.RecordChange{{Before: first, After: stale}, {Before: first}} {
+			if err := store.Apply([]*pb.RecordChange{change}); !errors.Is(err, ErrConflict) {
+				t.Errorf("%s: stale change: err = %v, want %v", backend, err, ErrConflict)
+			}
+		}
+		if got, _ := store.Get(record.GetId()); got.GetValue() != "192.0.2.2" {
+			t.Errorf("%s: stale change made: %v", backend, got)
+		}
+
+		if _, _, err := store.RollbackZone("valid.zone.", ZonePoint{Serial: 1}, 1); !errors.Is(err, ErrConflict) {
+			t.Errorf("%s: rollback from an old serial: err = %v, want %v", backend, err, ErrConflict)
+		}
+		if _, _, err := store.RollbackZone("valid.zone.", ZonePoint{Serial: 1}, 2); err != nil {
+			t.Errorf("%s: error rolling back: %v", backend, err)
+		}
+		if got, _ := store.Get(record.GetId()); got.GetValue() != "192.0.2.1" || got.GetRevision() != 3 {
+			t.Errorf("%s: rolled back record = %v, want the first value at revision 3", backend, got)
+		}
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	for backend, store := range openTestStores(t) {
+		record := &pb.DNSRecord{Name: "host", Zone: "valid.zone.", User: "alice",
+			Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 60}
+		entry := &pb.AuditEntry{Time: 100, Actor: "alice", Action: AuditCreateRecord}
+		change := func(tx RecordStore) error {
+			if err := tx.Apply([]*pb.RecordChange{{After: proto.Clone(record).(*pb.DNSRecord)}}); err != nil {
+				return err
+			}
+			return tx.AppendAudit(proto.Clone(entry).(*pb.AuditEntry))
+		}
+
+		// the record isn't made without its audit entry, nor the other way round
+		errFailed := errors.New("failed")
+		err := store.Transaction(func(tx RecordStore) error {
+			if err := change(tx); err != nil {
+				return err
+			}
+			return errFailed
+		})
+		if !errors.Is(err, errFailed) {
+			t.Errorf("%s: failed transaction: err = %v, want %v", backend, err, errFailed)
+		}
+		records, _ := store.List("valid.zone.", "")
+		entries, _ := store.AuditLog(AuditQuery{})
+		if len(records) != 0 || len(entries) != 0 {
+			t.Errorf("%s: failed transaction left records %v and entries %v", backend, records, entries)
+		}
+
+		if err := store.Transaction(change); err != nil {
+			t.Fatalf("%s: error in transaction: %v", backend, err)
+		}
+		records, _ = store.List("valid.zone.", "")
+		entries, _ = store.AuditLog(AuditQuery{})
+		if len(records) != 1 || len(entries) != 1 {
+			t.Errorf("%s: transaction made records %v and entries %v", backend, records, entries)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/miekg/dns"
+)
+
+// tokenPrefix starts every API token, so they can be told apart from other
+// bearer tokens, and spotted if they end up somewhere they shouldn't.
+const tokenPrefix = "drs_"
+
+// NewToken makes a new random API token for info, filling in its hash and
+// creation time. The token returned is the only copy of it.
+func NewToken(info *pb.APIToken) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	token := tokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	info.Hash = hashToken(token)
+	info.CreatedAt = time.Now().Unix()
+	return token, nil
+}
+
+// hashToken returns the hash API tokens are stored as. Tokens are random
+// enough that they don't need a salt or a slow hash.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// TokenAuthenticator authenticates requests carrying an API token as a
+// bearer token.
+type TokenAuthenticator struct {
+	Store RecordStore
+}
+
+func (a TokenAuthenticator) Authenticate(req *http.Request) (*Identity, error) {
+	token, ok := bearerToken(req)
+	if !ok || !strings.HasPrefix(token, tokenPrefix) {
+		return nil, nil
+	}
+
+	info, err := a.Store.TokenByHash(hashToken(token))
+	if errors.Is(err, ErrTokenNotFound) {
+		return nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	if info.GetRevokedAt() != 0 || (info.GetExpiresAt() != 0 && info.GetExpiresAt() <= now) {
+		return nil, ErrUnauthenticated
+	}
+
+	id := &Identity{User: info.GetUser(), ReadOnly: !info.GetWrite(), Service: info.GetService()}
+	if info.GetPatterns() != "" {
+		id.Patterns = strings.Split(info.GetPatterns(), ",")
+	}
+	return id, nil
+}
+
+// bearerToken returns the token in the Authorization header of req, if it has
+// one.
+func bearerToken(req *http.Request) (string, bool) {
+	auth := req.Header.Get("Authorization")
+	if len(auth) < len("Bearer ") || !strings.EqualFold(auth[:len("Bearer ")], "Bearer ") {
+		return "", false
+	}
+	return strings.TrimSpace(auth[len("Bearer "):]), true
+}
+
+// InScope reports whether record is one the scopes of id let it get to.
+func (id *Identity) InScope(record *pb.DNSRecord) bool {
+	if len(id.Patterns) == 0 {
+		return true
+	}
+	name := strings.ToLower(dns.Fqdn(processFullName(record)))
+	for _, pattern := range id.Patterns {
+		ok, err := path.Match(strings.ToLower(dns.Fqdn(strings.TrimSpace(pattern))), name)
+		if err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Unscoped reports whether id can do everything its user can, rather than
+// being limited by the scopes of a token.
+func (id *Identity) Unscoped() bool {
+	return !id.ReadOnly && len(id.Patterns) == 0
+}
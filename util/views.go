@@ -0,0 +1,93 @@
+package util
+
+import (
+	"net"
+
+	"github.com/gidoBOSSftw5731/log"
+	"github.com/miekg/dns"
+)
+
+// The views a DNSRecord can be restricted to. A record with no view is visible
+// from both.
+const (
+	ViewInternal = "internal"
+	ViewExternal = "external"
+)
+
+var (
+	validViews = map[string]*interface{}{
+		"":           f,
+		ViewInternal: f,
+		ViewExternal: f,
+	}
+)
+
+// clientView works out which view a query should be answered from, based on
+// the source address of the client, or the EDNS Client Subnet it forwarded if
+// it is a trusted forwarder. DoH clients are always external, since the
+// address we see for them is usually that of a proxy.
+func (h DNSHandler) clientView(resp dns.ResponseWriter, req *dns.Msg) string {
+	if viaDoH(resp) {
+		return ViewExternal
+	}
+	ip := addrIP(resp.RemoteAddr())
+	if ecs := h.clientSubnet(resp, req); ecs != nil {
+		ip = ecs.Address
+	}
+
+	if prefixesContain(h.Config.GetDnsConf().GetInternalPrefixes(), ip) {
+		return ViewInternal
+	}
+	return ViewExternal
+}
+
+// clientSubnet returns the EDNS Client Subnet option of req, but only if it
+// was sent by one of our trusted forwarders. Anyone else could just claim to
+// be on the inside.
+func (h DNSHandler) clientSubnet(resp dns.ResponseWriter, req *dns.Msg) *dns.EDNS0_SUBNET {
+	opt := req.IsEdns0()
+	if opt == nil {
+		return nil
+	}
+	if !prefixesContain(h.Config.GetDnsConf().GetTrustedForwarders(),
+		addrIP(resp.RemoteAddr())) {
+		return nil
+	}
+
+	for _, o := range opt.Option {
+		if ecs, ok := o.(*dns.EDNS0_SUBNET); ok {
+			return ecs
+		}
+	}
+	return nil
+}
+
+// prefixesContain reports whether ip is in any of prefixes, which are in CIDR
+// notation.
+func prefixesContain(prefixes []string, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, prefix := range prefixes {
+		_, ipnet, err := net.ParseCIDR(prefix)
+		if err != nil {
+			log.Errorf("Invalid prefix %q in config: %s", prefix, err)
+			continue
+		}
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// addrIP returns the IP address of a UDP or TCP address.
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.TCPAddr:
+		return a.IP
+	}
+	return nil
+}
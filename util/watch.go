@@ -0,0 +1,31 @@
+package util
+
+import "sync"
+
+// ChangeFeed wakes up whoever is waiting for records to change. It doesn't
+// say what changed, so those waiting have to look for themselves.
+type ChangeFeed struct {
+	mu      sync.Mutex
+	changed chan struct{}
+}
+
+// NewChangeFeed returns a ChangeFeed nobody is waiting on yet.
+func NewChangeFeed() *ChangeFeed {
+	return &ChangeFeed{changed: make(chan struct{})}
+}
+
+// Changed wakes up everyone waiting.
+func (f *ChangeFeed) Changed() {
+	f.mu.Lock()
+	close(f.changed)
+	f.changed = make(chan struct{})
+	f.mu.Unlock()
+}
+
+// Wait returns a channel which is closed the next time Changed is called.
+// Call it before looking, so changes made while looking aren't missed.
+func (f *ChangeFeed) Wait() <-chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.changed
+}
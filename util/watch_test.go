@@ -0,0 +1,26 @@
+package util
+
+import "testing"
+
+func TestChangeFeed(t *testing.T) {
+	feed := NewChangeFeed()
+	before := feed.Wait()
+	select {
+	case <-before:
+		t.Fatal("woken up before anything changed")
+	default:
+	}
+
+	feed.Changed()
+	select {
+	case <-before:
+	default:
+		t.Error("not woken up by a change")
+	}
+	// waiting again waits for the next change
+	select {
+	case <-feed.Wait():
+		t.Error("woken up by an old change")
+	default:
+	}
+}
@@ -0,0 +1,251 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/gidoBOSSftw5731/log"
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	// recordChangeChannel is the Postgres NOTIFY channel changes to DNS
+	// records are announced on, with the zone that changed as the payload.
+	recordChangeChannel = "drs_record_changes"
+	// how long to wait before trying to listen again after losing the
+	// connection to the database
+	listenRetryInterval = 5 * time.Second
+)
+
+// ZoneCache holds every DNS record in memory so queries can be answered
+// without going to the database, and keep being answered while it is down.
+// It is kept up to date by listening for the notifications sent by the
+// trigger made in the record change notification migration. Changes are
+// written straight to the database.
+type ZoneCache struct {
+	recordIndex
+	store *gormStore
+}
+
+// NewZoneCache returns an empty ZoneCache backed by db. Call Load to fill it.
+func NewZoneCache(db *gorm.DB) *ZoneCache {
+	return &ZoneCache{
+		recordIndex: newRecordIndex(),
+		store:       &gormStore{db: db},
+	}
+}
+
+// Lookup answers from memory, so it never fails.
+func (c *ZoneCache) Lookup(zone, name, view string) ([]*pb.DNSRecord, error) {
+	return c.lookup(zone, name, view), nil
+}
+
+// Create adds the record to the database. It shows up in the cache once the
+// database tells us about it, as do all other changes.
+func (c *ZoneCache) Create(record *pb.DNSRecord) error {
+	return c.store.Create(record)
+}
+
+// Get reads from the database, as whoever is asking is probably about to
+// change the record.
+func (c *ZoneCache) Get(id uint64) (*pb.DNSRecord, error) {
+	return c.store.Get(id)
+}
+
+// List reads from the database, so it sees changes right away.
+func (c *ZoneCache) List(zone, name string) ([]*pb.DNSRecord, error) {
+	return c.store.List(zone, name)
+}
+
+func (c *ZoneCache) Update(record *pb.DNSRecord, revision uint64) error {
+	return c.store.Update(record, revision)
+}
+
+func (c *ZoneCache) Delete(id uint64) error {
+	return c.store.Delete(id)
+}
+
+func (c *ZoneCache) Apply(changes []*pb.RecordChange) error {
+	return c.store.Apply(changes)
+}
+
+// Transaction changes the database, and the cache catches up once it is told
+// about it, like with Update.
+func (c *ZoneCache) Transaction(fn func(tx RecordStore) error) error {
+	return c.store.Transaction(fn)
+}
+
+// Delegate and the rest of the delegation and token methods aren't cached,
+// as they are only needed by the API.
+func (c *ZoneCache) Delegate(zone, user string) error {
+	return c.store.Delegate(zone, user)
+}
+
+func (c *ZoneCache) Undelegate(zone, user string) error {
+	return c.store.Undelegate(zone, user)
+}
+
+func (c *ZoneCache) Delegations(zone string) ([]*pb.ZoneDelegation, error) {
+	return c.store.Delegations(zone)
+}
+
+func (c *ZoneCache) IsDelegated(zone, user string) (bool, error) {
+	return c.store.IsDelegated(zone, user)
+}
+
+func (c *ZoneCache) CreateToken(token *pb.APIToken) error {
+	return c.store.CreateToken(token)
+}
+
+func (c *ZoneCache) TokenByHash(hash string) (*pb.APIToken, error) {
+	return c.store.TokenByHash(hash)
+}
+
+func (c *ZoneCache) Tokens(user string) ([]*pb.APIToken, error) {
+	return c.store.Tokens(user)
+}
+
+func (c *ZoneCache) RevokeToken(id uint64, at int64) error {
+	return c.store.RevokeToken(id, at)
+}
+
+func (c *ZoneCache) Owners() ([]string, error) {
+	return c.store.Owners()
+}
+
+func (c *ZoneCache) OwnedRecords(user string) ([]*pb.DNSRecord, error) {
+	return c.store.OwnedRecords(user)
+}
+
+// SetOwnerDisabled changes the database, and the cache catches up once it is
+// told about it, like with Update.
+func (c *ZoneCache) SetOwnerDisabled(user string, disabled bool) (int64, error) {
+	return c.store.SetOwnerDisabled(user, disabled)
+}
+
+func (c *ZoneCache) AppendAudit(entry *pb.AuditEntry) error {
+	return c.store.AppendAudit(entry)
+}
+
+func (c *ZoneCache) AuditLog(query AuditQuery) ([]*pb.AuditEntry, error) {
+	return c.store.AuditLog(query)
+}
+
+func (c *ZoneCache) ZoneSerial(zone string) (uint64, error) {
+	return c.store.ZoneSerial(zone)
+}
+
+func (c *ZoneCache) ZoneHistory(zone string, at ZonePoint) ([]*pb.RecordVersion, error) {
+	return c.store.ZoneHistory(zone, at)
+}
+
+func (c *ZoneCache) ZoneHistorySince(zone string, serial uint64) ([]*pb.RecordVersion, error) {
+	return c.store.ZoneHistorySince(zone, serial)
+}
+
+// RollbackZone changes the database, and the cache catches up once it is told
+// about it, like with Update.
+func (c *ZoneCache) RollbackZone(zone string, at ZonePoint, ifSerial uint64) ([]*pb.RecordChange, uint64, error) {
+	return c.store.RollbackZone(zone, at, ifSerial)
+}
+
+// Load replaces the contents of the cache with every record in the database.
+// If the database can't be read the cache is left as it was.
+func (c *ZoneCache) Load() error {
+	var records []*pb.DNSRecord
+	if err := c.store.db.Find(&records).Error; err != nil {
+		return err
+	}
+
+	zones := map[string]map[string][]*pb.DNSRecord{}
+	for _, record := range records {
+		zone := zoneKey(record.GetZone())
+		if zones[zone] == nil {
+			zones[zone] = map[string][]*pb.DNSRecord{}
+		}
+		indexRecord(zones[zone], record)
+	}
+
+	c.mu.Lock()
+	c.zones = zones
+	c.mu.Unlock()
+	log.Infof("Loaded %d records in %d zones into the cache", len(records), len(zones))
+	return nil
+}
+
+// LoadZone replaces the cached records of a single zone with what is in the
+// database, however the zone is written there.
+func (c *ZoneCache) LoadZone(zone string) error {
+	var records []*pb.DNSRecord
+	err := c.store.db.Where("LOWER(zone) IN ?", zoneSpellings(zone)).Find(&records).Error
+	if err != nil {
+		return err
+	}
+
+	names := map[string][]*pb.DNSRecord{}
+	for _, record := range records {
+		indexRecord(names, record)
+	}
+
+	c.mu.Lock()
+	c.zones[zoneKey(zone)] = names
+	c.mu.Unlock()
+	log.Tracef("Reloaded %d records in zone %s", len(records), zone)
+	return nil
+}
+
+// Listen keeps the cache up to date with changes made to the database, by
+// any instance, until the program exits. The whole cache is reloaded every
+// time the connection is (re)established, since anything could have changed
+// while we weren't listening.
+func (c *ZoneCache) Listen(dbConf *pb.DatabaseConfig) {
+	for {
+		err := c.listen(context.Background(), dbConf)
+		log.Errorln("Not listening for record changes, retrying:", err)
+		time.Sleep(listenRetryInterval)
+	}
+}
+
+func (c *ZoneCache) listen(ctx context.Context, dbConf *pb.DatabaseConfig) error {
+	conn, err := pgx.Connect(ctx, (&url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(dbConf.GetUsername(), dbConf.GetPassword()),
+		Host:   dbConf.GetHostname(),
+		Path:   dbConf.GetDatabaseName(),
+	}).String())
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	_, err = conn.Exec(ctx, "LISTEN "+recordChangeChannel)
+	if err != nil {
+		return err
+	}
+	if err := c.Load(); err != nil {
+		return err
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		if err := c.notified(n.Payload); err != nil {
+			return fmt.Errorf("error reloading records: %w", err)
+		}
+	}
+}
+
+// notified reloads what a notification on recordChangeChannel says has
+// changed: the zone in its payload, or everything if it is empty.
+func (c *ZoneCache) notified(payload string) error {
+	if payload == "" {
+		return c.Load()
+	}
+	return c.LoadZone(payload)
+}
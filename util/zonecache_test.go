@@ -0,0 +1,84 @@
+package util
+
+import (
+	"path/filepath"
+	"testing"
+
+	pb "github.com/gidoBOSSftw5731/DeviceRegistrationSystem/proto"
+	"github.com/miekg/dns"
+)
+
+// openTestZoneCache returns an empty ZoneCache backed by a new SQLite
+// database, standing in for Postgres.
+func openTestZoneCache(t *testing.T) *ZoneCache {
+	store, err := OpenStore(&pb.ServerConfig{
+		StorageBackend: StorageSQLite,
+		DBConf:         &pb.DatabaseConfig{SqlitePath: filepath.Join(t.TempDir(), "drs.db")},
+	})
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	return NewZoneCache(store.(*gormStore).db)
+}
+
+func TestZoneCache(t *testing.T) {
+	c := openTestZoneCache(t)
+
+	// records written with and without the trailing dot are the same zone
+	for _, record := range []*pb.DNSRecord{
+		{Name: "dotted", Zone: "valid.zone.", Type: uint32(dns.TypeA), Value: "192.0.2.1", Ttl: 60},
+		{Name: "undotted", Zone: "Valid.Zone", Type: uint32(dns.TypeA), Value: "192.0.2.2", Ttl: 60},
+		{Name: "host", Zone: "other.zone.", Type: uint32(dns.TypeA), Value: "192.0.2.3", Ttl: 60},
+	} {
+		if err := c.store.db.Create(record).Error; err != nil {
+			t.Fatalf("error creating record: %v", err)
+		}
+	}
+
+	// lookup returns how many records there are for name in valid.zone.
+	lookup := func(zone, name string) int {
+		t.Helper()
+		records, err := c.Lookup(zone, name, "")
+		if err != nil {
+			t.Fatalf("error looking up %s: %v", name, err)
+		}
+		return len(records)
+	}
+
+	// nothing is cached until it is loaded
+	if n := lookup("valid.zone.", "dotted"); n != 0 {
+		t.Errorf("found %d records before loading", n)
+	}
+	if err := c.Load(); err != nil {
+		t.Fatalf("error loading: %v", err)
+	}
+	for _, name := range []string{"dotted", "undotted"} {
+		if n := lookup("valid.zone", name); n != 1 {
+			t.Errorf("found %d records for %s after loading, want 1", n, name)
+		}
+	}
+
+	// a change announced for either spelling reloads both, and leaves other
+	// zones alone
+	c.store.db.Create(&pb.DNSRecord{Name: "new", Zone: "valid.zone", Type: uint32(dns.TypeA), Value: "192.0.2.4", Ttl: 60})
+	c.store.db.Create(&pb.DNSRecord{Name: "new", Zone: "other.zone.", Type: uint32(dns.TypeA), Value: "192.0.2.5", Ttl: 60})
+	if err := c.notified("valid.zone"); err != nil {
+		t.Fatalf("error reloading zone: %v", err)
+	}
+	for _, name := range []string{"dotted", "undotted", "new"} {
+		if n := lookup("valid.zone.", name); n != 1 {
+			t.Errorf("found %d records for %s after reloading the zone, want 1", n, name)
+		}
+	}
+	if n := lookup("other.zone.", "new"); n != 0 {
+		t.Errorf("reloading valid.zone loaded %d records in other.zone", n)
+	}
+
+	// an empty payload reloads everything
+	if err := c.notified(""); err != nil {
+		t.Fatalf("error reloading everything: %v", err)
+	}
+	if n := lookup("other.zone.", "new"); n != 1 {
+		t.Errorf("found %d records in other.zone after reloading everything, want 1", n)
+	}
+}